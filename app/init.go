@@ -1,13 +1,21 @@
 package app
 
 import (
+	"context"
+	"library-system/config"
 	"library-system/controller"
 	"library-system/database"
+	"library-system/metadata"
+	"library-system/middleware"
+	"library-system/notifier"
 	"library-system/repository"
 	"library-system/scheduler"
 	"library-system/service"
+	"library-system/storage"
+	"library-system/utils"
 
 	"fmt"
+	"log"
 )
 
 type App struct {
@@ -26,6 +34,8 @@ func InitApp() (*App, error) {
 	}
 
 	repository.NewRedis(rdb)
+	eventRdb := repository.NewEventRdb(rdb)
+	importReportRdb := repository.NewImportReportRdb(rdb)
 
 	userRepo := repository.NewUserRepository(db)
 	bookRepo := repository.NewBookRepository(db)
@@ -33,32 +43,166 @@ func InitApp() (*App, error) {
 	cateRepo := repository.NewCategoryRepository(db)
 	reservationRepo := repository.NewReservationRepository(db)
 	statsRepo := repository.NewStatsRepository(db)
+	copyRepo := repository.NewBookCopyRepository(db)
+	policyRepo := repository.NewLoanPolicyRepository(db)
+	fineRepo := repository.NewFineRepository(db)
+	notificationRepo := repository.NewNotificationRepository(db)
+	reviewRepo := repository.NewReviewRepository(db)
+	auditRepo := repository.NewAuditRepository(db)
+	roleRepo := repository.NewRoleRepository(db)
 
-	overdueService := service.NewOverdueService(borrowRepo, userRepo)
-	userService := service.NewUserService(userRepo, overdueService)
-	bookService := service.NewBookService(bookRepo, cateRepo)
-	reservationService := service.NewReservationService(reservationRepo, bookRepo, userRepo)
-	borrowService := service.NewBorrowService(borrowRepo, bookRepo, userRepo, reservationRepo, reservationService, overdueService)
-	cateService := service.NewCategoryService(cateRepo)
-	statsService := service.NewStatsService(statsRepo, userRepo)
+	// 为旧数据补建馆藏副本
+	if n, err := copyRepo.BackfillLegacyStock(context.Background()); err != nil {
+		return nil, fmt.Errorf("馆藏副本迁移失败: %v", err)
+	} else if n > 0 {
+		log.Printf("已为旧图书补建 %d 个馆藏副本", n)
+	}
+
+	// 写入缺失的内置角色
+	if n, err := roleRepo.SeedBuiltinRoles(context.Background()); err != nil {
+		return nil, fmt.Errorf("内置角色初始化失败: %v", err)
+	} else if n > 0 {
+		log.Printf("已创建 %d 个内置角色", n)
+	}
+
+	// 为旧的已归还借阅记录补记罚金流水
+	if n, err := fineRepo.BackfillReturnedCharges(context.Background()); err != nil {
+		return nil, fmt.Errorf("罚金流水迁移失败: %v", err)
+	} else if n > 0 {
+		log.Printf("已补记 %d 条历史罚金流水", n)
+	}
+
+	// 加载 Access Token 签名密钥
+	keyConf := config.GetJWTKeyConfig()
+	if keyConf.RefreshSecret == "" {
+		return nil, fmt.Errorf("JWT_REFRESH_SECRET 环境变量未设置")
+	}
+	utils.SetRefreshTokenSecret(keyConf.RefreshSecret)
+	keySet := utils.NewKeySet(keyConf.KeyDir, keyConf.Algorithm)
+	if err := keySet.Load(); err != nil {
+		return nil, fmt.Errorf("签名密钥加载失败: %v", err)
+	}
+	utils.SetAccessKeySet(keySet)
+
+	notifyConf := config.GetNotifyConfig()
+	var notifiers []notifier.Notifier
+	if notifyConf.SMTPHost != "" {
+		notifiers = append(notifiers, notifier.NewSMTPNotifier(notifyConf.SMTPHost, notifyConf.SMTPPort, notifyConf.SMTPUsername, notifyConf.SMTPPassword, notifyConf.SMTPFrom))
+	}
+	if notifyConf.WebhookURL != "" {
+		notifiers = append(notifiers, notifier.NewWebhookNotifier(notifyConf.WebhookURL, notifyConf.WebhookSecret))
+	}
+	if len(notifiers) == 0 {
+		log.Println("未配置 SMTP_HOST 或 NOTIFY_WEBHOOK_URL，通知将不会发送")
+	}
+
+	// 账号邮件（重置密码等）即时发送，未配置 SMTP 时只记录日志
+	var mailSender notifier.MailSender = notifier.NewLogMailSender()
+	if notifyConf.SMTPHost != "" {
+		mailSender = notifier.NewSMTPNotifier(notifyConf.SMTPHost, notifyConf.SMTPPort, notifyConf.SMTPUsername, notifyConf.SMTPPassword, notifyConf.SMTPFrom)
+	}
+	resetConf := config.GetPasswordResetConfig()
+	verifyConf := config.GetEmailVerificationConfig()
+	if verifyConf.Enabled && notifyConf.SMTPHost == "" {
+		return nil, fmt.Errorf("已启用邮箱验证（EMAIL_VERIFICATION_ENABLED），但未配置 SMTP_HOST，新注册用户将无法完成验证")
+	}
+	verification := service.EmailVerificationPolicy{
+		Enabled:    verifyConf.Enabled,
+		AllowLogin: verifyConf.AllowLogin,
+		TokenTTL:   verifyConf.TokenTTL,
+		VerifyURL:  verifyConf.VerifyURL,
+	}
+
+	metaConf := config.GetMetadataConfig()
+
+	storageConf := config.GetStorageConfig()
+	var blobStore storage.BlobStore
+	switch storageConf.Driver {
+	case "s3":
+		blobStore = storage.NewS3Store(storage.S3Config(storageConf.S3))
+	case "local":
+		blobStore = storage.NewLocalStore(storageConf.LocalDir, storageConf.LocalURL)
+	default:
+		return nil, fmt.Errorf("未知的存储类型 STORAGE_DRIVER=%s", storageConf.Driver)
+	}
+
+	auditService := service.NewAuditService(auditRepo)
+	roleService := service.NewRoleService(roleRepo, auditService)
+	eventService := service.NewEventService(eventRdb, roleService)
+	notificationService := service.NewNotificationService(notificationRepo, notifyConf.MaxAttempts, notifiers...)
+	policyService := service.NewLoanPolicyService(policyRepo, cateRepo, auditService)
+	fineService := service.NewFineService(fineRepo, userRepo, config.GetFineConfig().BlockThreshold, auditService, eventService)
+	overdueService := service.NewOverdueService(borrowRepo, userRepo, policyService, notificationService, eventService)
+	sessionService := service.NewSessionService(userRepo, auditService)
+	userService := service.NewUserService(userRepo, overdueService, auditService, sessionService, roleRepo, mailSender, resetConf.ResetURL, resetConf.TokenTTL, verification)
+	bookService := service.NewBookService(bookRepo, cateRepo, copyRepo, reservationRepo, auditService, metadata.NewOpenLibraryProvider(metaConf.OpenLibraryURL, metaConf.Timeout))
+	copyService := service.NewBookCopyService(copyRepo, bookRepo, auditService)
+	importService := service.NewBookImportService(bookRepo, cateRepo, copyRepo, importReportRdb, auditService)
+	exportService := service.NewBookExportService(bookRepo)
+	coverService := service.NewBookCoverService(bookRepo, blobStore, auditService)
+	reviewService := service.NewReviewService(reviewRepo, bookRepo, auditService)
+	reservationService := service.NewReservationService(reservationRepo, bookRepo, userRepo, notificationService, eventService)
+	borrowService := service.NewBorrowService(borrowRepo, bookRepo, userRepo, reservationRepo, reservationService, overdueService, copyRepo, policyService, fineService, notificationService, eventService, auditService)
+	cateService := service.NewCategoryService(cateRepo, auditService)
+	statsService := service.NewStatsService(statsRepo, userRepo, cateRepo)
 
 	overdueScheduler := scheduler.NewOverdueScheduler(overdueService)
 	reservationScheduler := scheduler.NewReservationScheduler(reservationService)
+	notificationScheduler := scheduler.NewNotificationScheduler(notificationService)
+	dueReminderScheduler := scheduler.NewDueReminderScheduler(overdueService, config.GetDueReminderDays())
+	keyRotationScheduler := scheduler.NewKeyRotationScheduler(keySet, keyConf.RotationInterval)
+	unverifiedUserScheduler := scheduler.NewUnverifiedUserScheduler(userService)
 	userCtl := controller.NewUserController(userService)
 	bookCtl := controller.NewBookController(bookService)
+	copyCtl := controller.NewBookCopyController(copyService)
 	borrowCtl := controller.NewBorrowController(borrowService)
 	reservationCtl := controller.NewReservationController(reservationService)
 	cateCtl := controller.NewCategoryController(cateService)
 	statsCtl := controller.NewStatsController(statsService)
+	policyCtl := controller.NewLoanPolicyController(policyService)
+	fineCtl := controller.NewFineController(fineService)
+	notificationCtl := controller.NewNotificationController(notificationService)
+	eventCtl := controller.NewEventController(eventService)
+	reviewCtl := controller.NewReviewController(reviewService)
+	importCtl := controller.NewBookImportController(importService)
+	exportCtl := controller.NewBookExportController(exportService)
+	coverCtl := controller.NewBookCoverController(coverService)
+	auditCtl := controller.NewAuditController(auditService)
+	roleCtl := controller.NewRoleController(roleService)
+	sessionCtl := controller.NewSessionController(sessionService)
+	jwksCtl := controller.NewJWKSController(keySet)
 
 	ctl := controller.NewController(controller.WithBook(bookCtl),
+									controller.WithBookCopy(copyCtl),
 									controller.WithBorrow(borrowCtl),
 									controller.WithCategory(cateCtl),
 									controller.WithUser(userCtl),
 									controller.WithReservation(reservationCtl),
-									controller.WithStats(statsCtl))
+									controller.WithStats(statsCtl),
+									controller.WithLoanPolicy(policyCtl),
+									controller.WithFine(fineCtl),
+									controller.WithNotification(notificationCtl),
+									controller.WithEvent(eventCtl),
+									controller.WithReview(reviewCtl),
+									controller.WithBookImport(importCtl),
+									controller.WithBookExport(exportCtl),
+									controller.WithBookCover(coverCtl),
+									controller.WithAudit(auditCtl),
+									controller.WithRole(roleCtl),
+									controller.WithSession(sessionCtl),
+									controller.WithJWKS(jwksCtl))
 
-	scheduler := &scheduler.Scheduler{OverdueScheduler: overdueScheduler, ReservationScheduler: reservationScheduler}
+	// 认证中间件通过角色服务查询权限
+	middleware.SetPermissionResolver(roleService)
+
+	scheduler := &scheduler.Scheduler{
+		OverdueScheduler:      overdueScheduler,
+		ReservationScheduler:  reservationScheduler,
+		NotificationScheduler: notificationScheduler,
+		DueReminderScheduler:  dueReminderScheduler,
+		KeyRotationScheduler:  keyRotationScheduler,
+		UnverifiedUserScheduler: unverifiedUserScheduler,
+	}
 	app := &App{
 		Controller: ctl,
 		Scheduler:  scheduler,
@@ -72,5 +216,22 @@ func InitApp() (*App, error) {
 	if err := reservationScheduler.Start("0 * * * *"); err != nil {
 		return nil, fmt.Errorf("定时任务启动失败: %v", err)
 	}
+
+	if err := notificationScheduler.Start("@every 1m"); err != nil {
+		return nil, fmt.Errorf("定时任务启动失败: %v", err)
+	}
+
+	if err := dueReminderScheduler.Start("0 9 * * *"); err != nil {
+		return nil, fmt.Errorf("定时任务启动失败: %v", err)
+	}
+
+	// 每个实例按 KeyReloadInterval 重新加载密钥目录，及时发布其他实例生成的新密钥
+	if err := keyRotationScheduler.Start(fmt.Sprintf("@every %s", utils.KeyReloadInterval)); err != nil {
+		return nil, fmt.Errorf("定时任务启动失败: %v", err)
+	}
+
+	if err := unverifiedUserScheduler.Start("30 * * * *"); err != nil {
+		return nil, fmt.Errorf("定时任务启动失败: %v", err)
+	}
 	return app, nil
 }
@@ -36,6 +36,22 @@ var (
 	ErrInvalidToken  = NewBizError(10004, "Token无效或已过期", http.StatusUnauthorized)
 	ErrPermissionDenied = NewBizError(10005, "无权限访问", http.StatusForbidden)
 	ErrUserDisabled  = NewBizError(10006, "用户已被禁用", http.StatusForbidden)
+	ErrTrashedUserNotFound = NewBizError(10007, "回收站中不存在该用户", http.StatusNotFound)
+	ErrUserInTrash   = NewBizError(10008, "用户名或邮箱属于已删除的用户，请从回收站恢复", http.StatusConflict)
+	ErrUserHasReservations = NewBizError(10009, "用户存在未完成的预约，无法删除", http.StatusBadRequest)
+	ErrRoleNotFound  = NewBizError(10010, "角色不存在", http.StatusNotFound)
+	ErrRoleExist     = NewBizError(10011, "角色名已存在", http.StatusConflict)
+	ErrAdminRoleImmutable = NewBizError(10012, "admin 角色固定拥有全部权限，不可修改", http.StatusBadRequest)
+	ErrBuiltinRoleUndeletable = NewBizError(10013, "内置角色不可删除", http.StatusBadRequest)
+	ErrRoleInUse     = NewBizError(10014, "仍有用户使用该角色，无法删除", http.StatusBadRequest)
+	ErrInvalidPermission = NewBizError(10015, "存在无效的权限", http.StatusBadRequest)
+	ErrCardNumberExist = NewBizError(10016, "借书证号已被使用", http.StatusConflict)
+	ErrInvalidResetToken = NewBizError(10017, "重置链接无效或已过期", http.StatusBadRequest)
+	ErrEmailNotVerified  = NewBizError(10018, "邮箱尚未验证，请先完成验证", http.StatusForbidden)
+	ErrInvalidVerifyToken = NewBizError(10019, "验证链接无效或已过期", http.StatusBadRequest)
+	ErrSessionNotFound   = NewBizError(10020, "会话不存在或已失效", http.StatusNotFound)
+	ErrRefreshTokenReused = NewBizError(10021, "登录凭证已被使用过，为保障账号安全该会话已注销，请重新登录", http.StatusUnauthorized)
+	ErrEmailChangeTooFrequent = NewBizError(10022, "修改邮箱过于频繁，请稍后再试", http.StatusTooManyRequests)
 )
 
 // ========== 图书模块错误（20xxx）==========
@@ -45,6 +61,34 @@ var (
 	ErrISBNExist      = NewBizError(20002, "ISBN已存在", http.StatusConflict)
 	ErrBookOutOfStock = NewBizError(20003, "图书库存不足", http.StatusBadRequest)
 	ErrCategoryNotFound = NewBizError(20004, "分类不存在", http.StatusNotFound)
+	ErrCopyNotFound     = NewBizError(20005, "馆藏副本不存在", http.StatusNotFound)
+	ErrBarcodeExist     = NewBizError(20006, "条码已存在", http.StatusConflict)
+	ErrCopyNotAvailable = NewBizError(20007, "该副本当前不可借出", http.StatusBadRequest)
+	ErrCopyOnLoan       = NewBizError(20008, "副本借出中，请先归还", http.StatusBadRequest)
+	ErrEmptySearchQuery = NewBizError(20009, "检索关键词不能为空", http.StatusBadRequest)
+	ErrInvalidYearRange = NewBizError(20010, "起始年份不能晚于结束年份", http.StatusBadRequest)
+	ErrCategoryCycle    = NewBizError(20011, "不能将分类移动到其子孙分类下", http.StatusBadRequest)
+	ErrInvalidISBN      = NewBizError(20012, "ISBN格式或校验位错误", http.StatusBadRequest)
+	ErrMetadataNotFound = NewBizError(20013, "未查询到该ISBN的图书信息", http.StatusNotFound)
+	ErrMetadataFailed   = NewBizError(20014, "图书信息查询失败，请稍后重试", http.StatusBadGateway)
+	ErrImportFileRequired      = NewBizError(20015, "请上传导入文件", http.StatusBadRequest)
+	ErrImportUnsupportedFormat = NewBizError(20016, "仅支持 CSV 或 XLSX 文件", http.StatusBadRequest)
+	ErrImportInvalidFile       = NewBizError(20017, "导入文件无法解析", http.StatusBadRequest)
+	ErrImportInvalidMapping    = NewBizError(20018, "列映射格式错误", http.StatusBadRequest)
+	ErrImportMissingColumns    = NewBizError(20019, "导入文件缺少必填列", http.StatusBadRequest)
+	ErrImportTooLarge          = NewBizError(20020, "导入文件过大", http.StatusRequestEntityTooLarge)
+	ErrImportReportNotFound    = NewBizError(20021, "导入报告不存在或已过期", http.StatusNotFound)
+	ErrExportUnsupportedFormat = NewBizError(20022, "不支持的导出格式，可选 csv、ndjson、marc、marcxml", http.StatusBadRequest)
+	ErrCoverFileRequired       = NewBizError(20023, "请上传封面图片", http.StatusBadRequest)
+	ErrCoverTooLarge           = NewBizError(20024, "封面图片不能超过 5MB", http.StatusRequestEntityTooLarge)
+	ErrCoverUnsupportedType    = NewBizError(20025, "封面仅支持 JPEG、PNG、GIF 格式", http.StatusUnsupportedMediaType)
+	ErrCoverInvalidImage       = NewBizError(20026, "封面图片无法识别或尺寸过大", http.StatusBadRequest)
+	ErrTrashedBookNotFound     = NewBizError(20027, "回收站中不存在该图书", http.StatusNotFound)
+	ErrISBNInTrash             = NewBizError(20028, "该ISBN属于回收站中的图书，请恢复原图书", http.StatusConflict)
+	ErrBookHasReservations     = NewBizError(20029, "图书存在未完成的预约，无法删除", http.StatusBadRequest)
+	ErrTrashedCategoryNotFound = NewBizError(20030, "回收站中不存在该分类", http.StatusNotFound)
+	ErrBookCategoryDeleted     = NewBizError(20031, "图书所属分类已删除，请先恢复分类", http.StatusBadRequest)
+	ErrParentCategoryDeleted   = NewBizError(20032, "上级分类已删除，请先恢复上级分类", http.StatusBadRequest)
 )
 
 // ========== 借阅模块错误（30xxx）==========
@@ -57,6 +101,13 @@ var (
 	ErrCannotRenewOverdue = NewBizError(30005, "逾期图书无法续借", http.StatusBadRequest)
 	ErrBookAlreadyBorrowed = NewBizError(30006, "该图书已被借出", http.StatusBadRequest)
 	ErrReservationFailed  = NewBizError(30007, "预约失败，图书有库存", http.StatusBadRequest)
+	ErrCategoryLoanLimitReached = NewBizError(30008, "该分类图书借阅数量已达上限", http.StatusBadRequest)
+	ErrFineBalanceExceeded = NewBizError(30009, "未缴罚金超过限额，请先缴纳罚金", http.StatusBadRequest)
+	ErrPaymentExceedsBalance = NewBizError(30010, "金额超过当前欠款", http.StatusBadRequest)
+	ErrNoOutstandingFine   = NewBizError(30011, "当前没有未缴罚金", http.StatusBadRequest)
+	ErrPatronNotFound      = NewBizError(30012, "读者不存在", http.StatusNotFound)
+	ErrNotBorrower         = NewBizError(30013, "只能归还本人借阅的图书", http.StatusForbidden)
+	ErrSelfReturnCondition = NewBizError(30014, "图书损坏或遗失请到流通台由馆员办理", http.StatusForbidden)
 )
 
 var (
@@ -66,6 +117,34 @@ var (
 	ErrHasReservation = NewBizError(40004, "该图书已被预约，请等待或预约排队", http.StatusBadRequest)
 )
 
+// ========== 借阅规则错误（50xxx）==========
+
+var (
+	ErrLoanPolicyNotFound = NewBizError(50001, "借阅规则不存在", http.StatusNotFound)
+	ErrLoanPolicyExist    = NewBizError(50002, "该角色与分类的借阅规则已存在", http.StatusConflict)
+)
+
+// ========== 通知模块错误（60xxx）==========
+
+var (
+	ErrNotificationNotFound = NewBizError(60001, "消息不存在", http.StatusNotFound)
+)
+
+// ========== 评价模块错误（70xxx）==========
+
+var (
+	ErrReviewNotFound   = NewBizError(70001, "评价不存在", http.StatusNotFound)
+	ErrReviewExist      = NewBizError(70002, "您已评价过该图书", http.StatusConflict)
+	ErrReviewNotAllowed = NewBizError(70003, "归还该图书后才能评价", http.StatusForbidden)
+)
+
+// ========== 审计日志错误（80xxx）==========
+
+var (
+	ErrInvalidAuditTime  = NewBizError(80001, "时间格式错误，应为 RFC3339 或 YYYY-MM-DD", http.StatusBadRequest)
+	ErrInvalidAuditRange = NewBizError(80002, "起始时间不能晚于结束时间", http.StatusBadRequest)
+)
+
 // ========== 通用错误 ==========
 
 var (
@@ -0,0 +1,12 @@
+package common
+
+import "github.com/gin-gonic/gin"
+
+// HasPermission 判断当前用户是否拥有指定权限，权限由认证中间件写入上下文
+func HasPermission(c *gin.Context, permission string) bool {
+	perms, exists := c.Get("permissions")
+	if !exists {
+		return false
+	}
+	return perms.(map[string]bool)[permission]
+}
@@ -0,0 +1,25 @@
+package common
+
+import "context"
+
+// RequestMeta 请求级元数据，由中间件写入请求 context，供审计等服务层逻辑读取
+type RequestMeta struct {
+	RequestID string
+	IP        string
+	UserAgent string
+	UserID    uint64 // 未登录时为 0
+	Username  string
+}
+
+type requestMetaKey struct{}
+
+// WithRequestMeta 将请求元数据写入 context
+func WithRequestMeta(ctx context.Context, meta *RequestMeta) context.Context {
+	return context.WithValue(ctx, requestMetaKey{}, meta)
+}
+
+// RequestMetaFrom 读取请求元数据，定时任务等非请求场景返回 nil
+func RequestMetaFrom(ctx context.Context) *RequestMeta {
+	meta, _ := ctx.Value(requestMetaKey{}).(*RequestMeta)
+	return meta
+}
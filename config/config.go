@@ -2,6 +2,8 @@ package config
 
 import (
 	"os"
+	"strconv"
+	"time"
 )
 
 type Config struct {
@@ -39,4 +41,227 @@ func GetRedisConfig() *RedisConfig {
 		Password: password,
 		DB:       0, // 默认使用 0 号数据库
 	}
-}
\ No newline at end of file
+}
+type FineConfig struct {
+	BlockThreshold float64 // 欠款超过该金额时禁止借书
+}
+
+func GetFineConfig() *FineConfig {
+	threshold := 10.0
+	if v := os.Getenv("FINE_BLOCK_THRESHOLD"); v != "" {
+		if f, err := strconv.ParseFloat(v, 64); err == nil && f >= 0 {
+			threshold = f
+		}
+	}
+
+	return &FineConfig{
+		BlockThreshold: threshold,
+	}
+}
+
+type NotifyConfig struct {
+	SMTPHost      string // 为空时不启用邮件通知
+	SMTPPort      int
+	SMTPUsername  string
+	SMTPPassword  string
+	SMTPFrom      string
+	WebhookURL    string // 为空时不启用 webhook 通知
+	WebhookSecret string
+	MaxAttempts   int // 单条通知最多尝试次数
+}
+
+func GetNotifyConfig() *NotifyConfig {
+	port := 25
+	if v, err := strconv.Atoi(os.Getenv("SMTP_PORT")); err == nil && v > 0 {
+		port = v
+	}
+
+	from := os.Getenv("SMTP_FROM")
+	if from == "" {
+		from = "library@localhost"
+	}
+
+	maxAttempts := 5
+	if v, err := strconv.Atoi(os.Getenv("NOTIFY_MAX_ATTEMPTS")); err == nil && v > 0 {
+		maxAttempts = v
+	}
+
+	return &NotifyConfig{
+		SMTPHost:      os.Getenv("SMTP_HOST"),
+		SMTPPort:      port,
+		SMTPUsername:  os.Getenv("SMTP_USERNAME"),
+		SMTPPassword:  os.Getenv("SMTP_PASSWORD"),
+		SMTPFrom:      from,
+		WebhookURL:    os.Getenv("NOTIFY_WEBHOOK_URL"),
+		WebhookSecret: os.Getenv("NOTIFY_WEBHOOK_SECRET"),
+		MaxAttempts:   maxAttempts,
+	}
+}
+
+// GetDueReminderDays 到期前多少天发送提醒
+func GetDueReminderDays() int {
+	days := 3
+	if v, err := strconv.Atoi(os.Getenv("DUE_REMINDER_DAYS")); err == nil && v > 0 {
+		days = v
+	}
+	return days
+}
+
+type PasswordResetConfig struct {
+	TokenTTL time.Duration // 重置链接有效期
+	ResetURL string        // 前端重置密码页面地址，邮件中的链接为 ResetURL?token=xxx
+}
+
+func GetPasswordResetConfig() *PasswordResetConfig {
+	ttl := 30 * time.Minute
+	if v, err := strconv.Atoi(os.Getenv("PASSWORD_RESET_TTL_MINUTES")); err == nil && v > 0 {
+		ttl = time.Duration(v) * time.Minute
+	}
+
+	resetURL := os.Getenv("PASSWORD_RESET_URL")
+	if resetURL == "" {
+		resetURL = "http://localhost:5173/reset-password"
+	}
+
+	return &PasswordResetConfig{
+		TokenTTL: ttl,
+		ResetURL: resetURL,
+	}
+}
+
+type EmailVerificationConfig struct {
+	Enabled    bool          // 自助注册的账号需验证邮箱后才能激活
+	AllowLogin bool          // 未验证的用户能否登录；允许登录时只能浏览，不能借阅、预约或评价
+	TokenTTL   time.Duration // 验证链接有效期
+	VerifyURL  string        // 前端验证页面地址，邮件中的链接为 VerifyURL?token=xxx
+}
+
+// GetEmailVerificationConfig 未显式配置时，只在配置了 SMTP 时启用邮箱验证，否则验证邮件无法送达
+func GetEmailVerificationConfig() *EmailVerificationConfig {
+	enabled := os.Getenv("SMTP_HOST") != ""
+	if v, err := strconv.ParseBool(os.Getenv("EMAIL_VERIFICATION_ENABLED")); err == nil {
+		enabled = v
+	}
+
+	allowLogin := true
+	if v, err := strconv.ParseBool(os.Getenv("EMAIL_VERIFICATION_ALLOW_LOGIN")); err == nil {
+		allowLogin = v
+	}
+
+	ttl := 24 * time.Hour
+	if v, err := strconv.Atoi(os.Getenv("EMAIL_VERIFICATION_TTL_HOURS")); err == nil && v > 0 {
+		ttl = time.Duration(v) * time.Hour
+	}
+
+	verifyURL := os.Getenv("EMAIL_VERIFICATION_URL")
+	if verifyURL == "" {
+		verifyURL = "http://localhost:5173/verify-email"
+	}
+
+	return &EmailVerificationConfig{
+		Enabled:    enabled,
+		AllowLogin: allowLogin,
+		TokenTTL:   ttl,
+		VerifyURL:  verifyURL,
+	}
+}
+
+type JWTKeyConfig struct {
+	KeyDir           string        // Access Token 签名私钥目录，文件名（不含 .pem）即 kid，以生效时间开头；多实例需共享
+	Algorithm        string        // 自动生成密钥使用的算法：EdDSA 或 RS256
+	RotationInterval time.Duration // 自动生成新签名密钥的间隔，0 表示不自动轮换
+	RefreshSecret    string        // Refresh Token 的 HMAC 密钥
+}
+
+func GetJWTKeyConfig() *JWTKeyConfig {
+	keyDir := os.Getenv("JWT_KEY_DIR")
+	if keyDir == "" {
+		keyDir = "./keys"
+	}
+
+	algorithm := os.Getenv("JWT_KEY_ALGORITHM")
+	if algorithm == "" {
+		algorithm = "EdDSA"
+	}
+
+	interval := 30 * 24 * time.Hour
+	if v, err := strconv.Atoi(os.Getenv("JWT_KEY_ROTATION_DAYS")); err == nil && v >= 0 {
+		interval = time.Duration(v) * 24 * time.Hour
+	}
+
+	return &JWTKeyConfig{
+		KeyDir:           keyDir,
+		Algorithm:        algorithm,
+		RotationInterval: interval,
+		RefreshSecret:    os.Getenv("JWT_REFRESH_SECRET"),
+	}
+}
+
+type MetadataConfig struct {
+	OpenLibraryURL string        // Open Library 兼容接口地址，可指向镜像
+	Timeout        time.Duration // 单次查询超时
+}
+
+func GetMetadataConfig() *MetadataConfig {
+	timeout := 10 * time.Second
+	if v, err := strconv.Atoi(os.Getenv("METADATA_TIMEOUT_SECONDS")); err == nil && v > 0 {
+		timeout = time.Duration(v) * time.Second
+	}
+
+	return &MetadataConfig{
+		OpenLibraryURL: os.Getenv("OPENLIBRARY_URL"),
+		Timeout:        timeout,
+	}
+}
+
+type StorageConfig struct {
+	Driver   string // local 或 s3
+	LocalDir string // 本地存储根目录，需与静态文件路由一致
+	LocalURL string // 本地存储对应的访问前缀
+	S3       S3Config
+}
+
+type S3Config struct {
+	Endpoint  string
+	Region    string
+	Bucket    string
+	AccessKey string
+	SecretKey string
+	PathStyle bool   // MinIO 等自建服务使用路径风格地址
+	PublicURL string // 对外访问前缀（如 CDN），为空时使用对象地址
+}
+
+func GetStorageConfig() *StorageConfig {
+	driver := os.Getenv("STORAGE_DRIVER")
+	if driver == "" {
+		driver = "local"
+	}
+	localDir := os.Getenv("STORAGE_LOCAL_DIR")
+	if localDir == "" {
+		localDir = "./static"
+	}
+	localURL := os.Getenv("STORAGE_LOCAL_URL")
+	if localURL == "" {
+		localURL = "/static"
+	}
+
+	pathStyle := true
+	if v, err := strconv.ParseBool(os.Getenv("S3_PATH_STYLE")); err == nil {
+		pathStyle = v
+	}
+
+	return &StorageConfig{
+		Driver:   driver,
+		LocalDir: localDir,
+		LocalURL: localURL,
+		S3: S3Config{
+			Endpoint:  os.Getenv("S3_ENDPOINT"),
+			Region:    os.Getenv("S3_REGION"),
+			Bucket:    os.Getenv("S3_BUCKET"),
+			AccessKey: os.Getenv("S3_ACCESS_KEY"),
+			SecretKey: os.Getenv("S3_SECRET_KEY"),
+			PathStyle: pathStyle,
+			PublicURL: os.Getenv("S3_PUBLIC_URL"),
+		},
+	}
+}
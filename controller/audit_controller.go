@@ -0,0 +1,39 @@
+package controller
+
+import (
+	"library-system/common"
+	"library-system/dto/request"
+	"library-system/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+type AuditController struct {
+	auditService *service.AuditService
+}
+
+func NewAuditController(auditService *service.AuditService) *AuditController {
+	return &AuditController{
+		auditService: auditService,
+	}
+}
+
+// GetAuditEventList 查询审计日志
+// GET /api/admin/audit
+func (ctl *AuditController) GetAuditEventList(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	var req request.GetAuditEventListRequest
+	if err := common.ValidateStruct(c, &req); err != nil {
+		c.Error(err)
+		return
+	}
+
+	data, err := ctl.auditService.GetAuditEventList(ctx, &req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	common.Success(c, 200, "success", data)
+}
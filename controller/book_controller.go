@@ -37,6 +37,24 @@ func (ctl *BookController) CreateBook(c *gin.Context) {
 	common.Success(c, 201, "图书添加成功", data)
 }
 
+func (ctl *BookController) LookupBook(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	var req request.LookupBookRequest
+	if err := common.ValidateStruct(c, &req); err != nil {
+		c.Error(err)
+		return
+	}
+
+	data, err := ctl.bookService.LookupBook(ctx, &req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	common.Success(c, 200, "success", data)
+}
+
 func (ctl *BookController) BatchCreateBook(c *gin.Context) {
 	ctx := c.Request.Context()
 
@@ -73,6 +91,24 @@ func (ctl *BookController) GetBookList(c *gin.Context) {
 	common.Success(c, 200, "success", data)
 }
 
+func (ctl *BookController) SearchBooks(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	var req request.SearchBooksRequest
+	if err := common.ValidateStruct(c, &req); err != nil {
+		c.Error(err)
+		return
+	}
+
+	data, err := ctl.bookService.SearchBooks(ctx, &req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	common.Success(c, 200, "success", data)
+}
+
 func (ctl *BookController) GetBookDetails(c *gin.Context) {
 	ctx := c.Request.Context()
 
@@ -133,4 +169,39 @@ func (ctl *BookController) DeleteBook(c *gin.Context) {
 	}
 	
 	common.Success(c, 200, "图书删除成功", gin.H{})
-}
\ No newline at end of file
+}
+func (ctl *BookController) GetBookTrash(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	var req request.GetBookTrashRequest
+	if err := common.ValidateStruct(c, &req); err != nil {
+		c.Error(err)
+		return
+	}
+
+	data, err := ctl.bookService.GetBookTrash(ctx, &req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	common.Success(c, 200, "success", data)
+}
+
+func (ctl *BookController) RestoreBook(c *gin.Context) {
+	ctx := c.Request.Context()
+	idStr := c.Param("id")
+	id, err := strconv.ParseUint(idStr, 10, 64)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	err = ctl.bookService.RestoreBook(ctx, id)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	common.Success(c, 200, "图书恢复成功", gin.H{})
+}
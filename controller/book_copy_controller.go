@@ -0,0 +1,127 @@
+package controller
+
+import (
+	"library-system/common"
+	"library-system/dto/request"
+	"library-system/service"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+type BookCopyController struct {
+	copyService *service.BookCopyService
+}
+
+func NewBookCopyController(service *service.BookCopyService) *BookCopyController {
+	return &BookCopyController{
+		copyService: service,
+	}
+}
+
+// GetBookCopyList 获取图书副本列表
+// GET /api/books/:id/copies
+func (ctl *BookCopyController) GetBookCopyList(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	bookID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.Error(common.ErrBadRequest)
+		return
+	}
+
+	var req request.GetBookCopyListRequest
+	if err := common.ValidateStruct(c, &req); err != nil {
+		c.Error(err)
+		return
+	}
+
+	data, err := ctl.copyService.GetBookCopyList(ctx, bookID, &req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	common.Success(c, 200, "success", data)
+}
+
+// CreateBookCopy 新增副本
+// POST /api/books/:id/copies
+func (ctl *BookCopyController) CreateBookCopy(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	bookID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.Error(common.ErrBadRequest)
+		return
+	}
+
+	var req request.CreateBookCopyRequest
+	if err := common.ValidateStruct(c, &req); err != nil {
+		c.Error(err)
+		return
+	}
+
+	data, err := ctl.copyService.CreateBookCopy(ctx, bookID, &req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	common.Success(c, 201, "副本添加成功", data)
+}
+
+// UpdateBookCopy 更新副本信息或状态
+// PUT /api/books/:id/copies/:copy_id
+func (ctl *BookCopyController) UpdateBookCopy(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	bookID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.Error(common.ErrBadRequest)
+		return
+	}
+	copyID, err := strconv.ParseUint(c.Param("copy_id"), 10, 64)
+	if err != nil {
+		c.Error(common.ErrBadRequest)
+		return
+	}
+
+	var req request.UpdateBookCopyRequest
+	if err := common.ValidateStruct(c, &req); err != nil {
+		c.Error(err)
+		return
+	}
+
+	data, err := ctl.copyService.UpdateBookCopy(ctx, bookID, copyID, &req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	common.Success(c, 200, "副本更新成功", data)
+}
+
+// DeleteBookCopy 删除副本
+// DELETE /api/books/:id/copies/:copy_id
+func (ctl *BookCopyController) DeleteBookCopy(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	bookID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.Error(common.ErrBadRequest)
+		return
+	}
+	copyID, err := strconv.ParseUint(c.Param("copy_id"), 10, 64)
+	if err != nil {
+		c.Error(common.ErrBadRequest)
+		return
+	}
+
+	if err := ctl.copyService.DeleteBookCopy(ctx, bookID, copyID); err != nil {
+		c.Error(err)
+		return
+	}
+
+	common.Success(c, 200, "副本删除成功", gin.H{})
+}
@@ -0,0 +1,51 @@
+package controller
+
+import (
+	"library-system/common"
+	"library-system/service"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+type BookCoverController struct {
+	coverService *service.BookCoverService
+}
+
+func NewBookCoverController(coverService *service.BookCoverService) *BookCoverController {
+	return &BookCoverController{
+		coverService: coverService,
+	}
+}
+
+// UploadCover 上传图书封面（multipart 字段 file），生成缩略图并更新封面地址
+// POST /api/books/:id/cover
+func (ctl *BookCoverController) UploadCover(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.Error(common.ErrBadRequest)
+		return
+	}
+
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		c.Error(common.ErrCoverFileRequired)
+		return
+	}
+	file, err := fileHeader.Open()
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	defer file.Close()
+
+	data, err := ctl.coverService.UploadCover(ctx, id, file)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	common.Success(c, 200, "封面上传成功", data)
+}
@@ -0,0 +1,43 @@
+package controller
+
+import (
+	"fmt"
+	"library-system/service"
+	"log"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+type BookExportController struct {
+	exportService *service.BookExportService
+}
+
+func NewBookExportController(exportService *service.BookExportService) *BookExportController {
+	return &BookExportController{
+		exportService: exportService,
+	}
+}
+
+// ExportBooks 流式导出全部图书，format 可选 csv / ndjson / marc / marcxml
+// GET /api/books/export
+func (ctl *BookExportController) ExportBooks(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	format := c.DefaultQuery("format", "csv")
+	meta, err := ctl.exportService.ExportMeta(format)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	filename := fmt.Sprintf("books-%s.%s", time.Now().Format("20060102150405"), meta.Ext)
+	c.Header("Content-Type", meta.ContentType)
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, filename))
+	c.Status(200)
+
+	// 响应头已发出，中途出错只能中断输出并记录日志
+	if err := ctl.exportService.ExportBooks(ctx, format, c.Writer); err != nil {
+		log.Printf("图书导出中断（%s）: %v", format, err)
+	}
+}
@@ -0,0 +1,72 @@
+package controller
+
+import (
+	"fmt"
+	"library-system/common"
+	"library-system/dto/request"
+	"library-system/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+type BookImportController struct {
+	importService *service.BookImportService
+}
+
+func NewBookImportController(importService *service.BookImportService) *BookImportController {
+	return &BookImportController{
+		importService: importService,
+	}
+}
+
+// ImportBooks 通过 CSV/XLSX 文件批量导入图书
+// POST /api/books/import
+func (ctl *BookImportController) ImportBooks(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	var req request.ImportBooksRequest
+	if err := c.ShouldBind(&req); err != nil {
+		c.Error(common.ErrBadRequest)
+		return
+	}
+
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		c.Error(common.ErrImportFileRequired)
+		return
+	}
+	file, err := fileHeader.Open()
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	defer file.Close()
+
+	data, err := ctl.importService.ImportBooks(ctx, &req, fileHeader.Filename, file, fileHeader.Size)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	msg := "导入完成"
+	if req.DryRun {
+		msg = "校验完成"
+	}
+	common.Success(c, 200, msg, data)
+}
+
+// DownloadImportReport 下载导入错误报告
+// GET /api/books/import/reports/:report_id
+func (ctl *BookImportController) DownloadImportReport(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	reportID := c.Param("report_id")
+	data, err := ctl.importService.GetImportReport(ctx, reportID)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="import-report-%s.csv"`, reportID))
+	c.Data(200, "text/csv; charset=utf-8", data)
+}
@@ -3,6 +3,7 @@ package controller
 import (
 	"library-system/common"
 	"library-system/dto/request"
+	"library-system/model"
 	"library-system/service"
 	"strconv"
 
@@ -38,6 +39,46 @@ func (ctl *BorrowController) BorrowBook(c *gin.Context) {
 	common.Success(c, 201, "借阅成功", data)
 }
 
+func (ctl *BorrowController) Checkout(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	staffID, _ := c.Get("user_id")
+
+	var req request.CheckoutRequest
+	if err := common.ValidateStruct(c, &req); err != nil {
+		c.Error(err)
+		return
+	}
+
+	data, err := ctl.borrowService.CheckoutForPatron(ctx, staffID.(uint64), &req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	common.Success(c, 201, "借阅成功", data)
+}
+
+func (ctl *BorrowController) Checkin(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	staffID, _ := c.Get("user_id")
+
+	var req request.CheckinRequest
+	if err := common.ValidateStruct(c, &req); err != nil {
+		c.Error(err)
+		return
+	}
+
+	data, err := ctl.borrowService.CheckinBook(ctx, staffID.(uint64), &req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	common.Success(c, 200, "归还成功", data)
+}
+
 func (ctl *BorrowController) ReturnBook(c *gin.Context) {
 	ctx := c.Request.Context()
 
@@ -48,13 +89,35 @@ func (ctl *BorrowController) ReturnBook(c *gin.Context) {
 		return		
 	}
 
+	userID, _ := c.Get("user_id")
+
 	var req request.ReturnBookRequest
 	if err := common.ValidateStruct(c, &req); err != nil {
 		c.Error(err)
 		return
 	}
 
-	data, err := ctl.borrowService.ReturnBook(ctx, id, &req)
+	data, err := ctl.borrowService.ReturnBook(ctx, userID.(uint64), id, &req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	common.Success(c, 200, "归还成功", data)
+}
+
+func (ctl *BorrowController) ReturnBookByBarcode(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	userID, _ := c.Get("user_id")
+
+	var req request.ReturnByBarcodeRequest
+	if err := common.ValidateStruct(c, &req); err != nil {
+		c.Error(err)
+		return
+	}
+
+	data, err := ctl.borrowService.ReturnBookByBarcode(ctx, userID.(uint64), &req)
 	if err != nil {
 		c.Error(err)
 		return
@@ -101,9 +164,8 @@ func (ctl *BorrowController) GetBorrowRecordList(c *gin.Context) {
 
 	// 权限检查：普通用户只能查看自己的记录
 	userID, _ := c.Get("user_id")
-	role, _ := c.Get("role")
 
-	if role != "admin" {
+	if !common.HasPermission(c, model.PermCirculationRead) {
 		// 普通用户强制只查询自己的记录
 		uid := userID.(uint64)
 		req.UserID = &uid
@@ -39,6 +39,20 @@ func (ctl *CategoryController) GetCategoryList(c *gin.Context) {
 	common.Success(c, 200, "success", data)
 }
 
+// GetCategoryTree 获取分类树
+// GET /api/categories/tree
+func (ctl *CategoryController) GetCategoryTree(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	data, err := ctl.categoryService.GetCategoryTree(ctx)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	common.Success(c, 200, "success", data)
+}
+
 // GetCategoryDetail 获取分类详情
 // GET /api/categories/: id
 func (ctl *CategoryController) GetCategoryDetail(c *gin.Context) {
@@ -126,4 +140,38 @@ func (ctl *CategoryController) DeleteCategory(c *gin. Context) {
 	}
 
 	common.Success(c, 200, "分类删除成功", gin.H{})
-}
\ No newline at end of file
+}
+// GetCategoryTrash 获取回收站中的分类
+// GET /api/categories/trash
+func (ctl *CategoryController) GetCategoryTrash(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	data, err := ctl.categoryService.GetCategoryTrash(ctx)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	common.Success(c, 200, "success", data)
+}
+
+// RestoreCategory 从回收站恢复分类
+// POST /api/categories/:id/restore
+func (ctl *CategoryController) RestoreCategory(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	idStr := c.Param("id")
+	id, err := strconv.ParseUint(idStr, 10, 64)
+	if err != nil {
+		c.Error(common.ErrBadRequest)
+		return
+	}
+
+	err = ctl.categoryService.RestoreCategory(ctx, uint(id))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	common.Success(c, 200, "分类恢复成功", gin.H{})
+}
@@ -1,12 +1,25 @@
 package controller
 
 type Controller struct {
-	UserController        *UserController
-	BookController        *BookController
-	BorrowController      *BorrowController
-	ReservationController *ReservationController
-	CategoryController    *CategoryController
-	StatsController       *StatsController
+	UserController         *UserController
+	BookController         *BookController
+	BookCopyController     *BookCopyController
+	BorrowController       *BorrowController
+	ReservationController  *ReservationController
+	CategoryController     *CategoryController
+	StatsController        *StatsController
+	LoanPolicyController   *LoanPolicyController
+	FineController         *FineController
+	NotificationController *NotificationController
+	EventController        *EventController
+	ReviewController       *ReviewController
+	BookImportController   *BookImportController
+	BookExportController   *BookExportController
+	BookCoverController    *BookCoverController
+	AuditController        *AuditController
+	RoleController         *RoleController
+	SessionController      *SessionController
+	JWKSController         *JWKSController
 }
 
 type Option func(*Controller)
@@ -23,6 +36,12 @@ func WithBook(bookCtl *BookController) Option {
 	}
 }
 
+func WithBookCopy(copyCtl *BookCopyController) Option {
+	return func(c *Controller) {
+		c.BookCopyController = copyCtl
+	}
+}
+
 func WithBorrow(borrowCtl *BorrowController) Option {
 	return func(c *Controller) {
 		c.BorrowController = borrowCtl
@@ -47,6 +66,78 @@ func WithStats(stats *StatsController) Option {
 	}
 }
 
+func WithLoanPolicy(policy *LoanPolicyController) Option {
+	return func(c *Controller) {
+		c.LoanPolicyController = policy
+	}
+}
+
+func WithFine(fine *FineController) Option {
+	return func(c *Controller) {
+		c.FineController = fine
+	}
+}
+
+func WithNotification(notification *NotificationController) Option {
+	return func(c *Controller) {
+		c.NotificationController = notification
+	}
+}
+
+func WithEvent(event *EventController) Option {
+	return func(c *Controller) {
+		c.EventController = event
+	}
+}
+
+func WithReview(review *ReviewController) Option {
+	return func(c *Controller) {
+		c.ReviewController = review
+	}
+}
+
+func WithBookImport(bookImport *BookImportController) Option {
+	return func(c *Controller) {
+		c.BookImportController = bookImport
+	}
+}
+
+func WithBookExport(bookExport *BookExportController) Option {
+	return func(c *Controller) {
+		c.BookExportController = bookExport
+	}
+}
+
+func WithBookCover(bookCover *BookCoverController) Option {
+	return func(c *Controller) {
+		c.BookCoverController = bookCover
+	}
+}
+
+func WithAudit(audit *AuditController) Option {
+	return func(c *Controller) {
+		c.AuditController = audit
+	}
+}
+
+func WithRole(role *RoleController) Option {
+	return func(c *Controller) {
+		c.RoleController = role
+	}
+}
+
+func WithSession(session *SessionController) Option {
+	return func(c *Controller) {
+		c.SessionController = session
+	}
+}
+
+func WithJWKS(jwks *JWKSController) Option {
+	return func(c *Controller) {
+		c.JWKSController = jwks
+	}
+}
+
 func NewController(opts ...Option) *Controller {
 	ctl := &Controller{}
 
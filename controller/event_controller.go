@@ -0,0 +1,108 @@
+package controller
+
+import (
+	"io"
+	"library-system/common"
+	"library-system/model"
+	"library-system/service"
+	"library-system/utils"
+	"log"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	// SSE 心跳间隔，防止代理因连接空闲而断开
+	eventHeartbeatInterval = 25 * time.Second
+	// 复核连接权限的间隔，Token 被吊销或权限被收回后最迟在此时间内断开
+	eventRecheckInterval = time.Minute
+)
+
+type EventController struct {
+	eventService *service.EventService
+}
+
+func NewEventController(service *service.EventService) *EventController {
+	return &EventController{
+		eventService: service,
+	}
+}
+
+// CreateTicket 签发建立事件流的一次性票据，浏览器以 ?ticket= 连接事件流
+// POST /api/events/ticket
+func (ctl *EventController) CreateTicket(c *gin.Context) {
+	ctx := c.Request.Context()
+	claims, _ := c.Get("claims")
+
+	data, err := ctl.eventService.IssueStreamTicket(ctx, claims.(*utils.AccessTokenClaims))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	common.Success(c, 200, "success", data)
+}
+
+// Stream 实时事件推送（Server-Sent Events），Token 过期、被吊销或权限被收回时断开
+// GET /api/events/stream
+func (ctl *EventController) Stream(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	userID, _ := c.Get("user_id")
+	value, _ := c.Get("claims")
+	claims := value.(*utils.AccessTokenClaims)
+	watchAll := common.HasPermission(c, model.PermCirculationRead)
+
+	events, err := ctl.eventService.Subscribe(ctx, userID.(uint64), watchAll)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Header("X-Accel-Buffering", "no")
+
+	// 告知客户端连接已建立
+	c.SSEvent("ready", gin.H{"user_id": userID})
+	c.Writer.Flush()
+
+	heartbeat := time.NewTicker(eventHeartbeatInterval)
+	defer heartbeat.Stop()
+	recheck := time.NewTicker(eventRecheckInterval)
+	defer recheck.Stop()
+	expiry := time.NewTimer(time.Until(claims.ExpiresAt.Time))
+	defer expiry.Stop()
+
+	c.Stream(func(w io.Writer) bool {
+		select {
+		case <-ctx.Done():
+			return false
+		case <-expiry.C:
+			c.SSEvent("expired", gin.H{})
+			return false
+		case <-recheck.C:
+			ok, err := ctl.eventService.StillAuthorized(ctx, claims, watchAll)
+			if err != nil {
+				log.Printf("复核事件流权限失败: %v", err)
+				return false
+			}
+			if !ok {
+				c.SSEvent("revoked", gin.H{})
+				return false
+			}
+			return true
+		case payload, ok := <-events:
+			if !ok {
+				return false
+			}
+			c.SSEvent("message", string(payload))
+			return true
+		case <-heartbeat.C:
+			io.WriteString(w, ": ping\n\n")
+			return true
+		}
+	})
+}
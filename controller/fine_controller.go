@@ -0,0 +1,152 @@
+package controller
+
+import (
+	"library-system/common"
+	"library-system/dto/request"
+	"library-system/service"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+type FineController struct {
+	fineService *service.FineService
+}
+
+func NewFineController(service *service.FineService) *FineController {
+	return &FineController{
+		fineService: service,
+	}
+}
+
+// GetMyFines 获取当前用户的欠款和罚金流水
+// GET /api/fines/me
+func (ctl *FineController) GetMyFines(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	var req request.GetFineTransactionsRequest
+	if err := common.ValidateStruct(c, &req); err != nil {
+		c.Error(err)
+		return
+	}
+
+	userID, _ := c.Get("user_id")
+
+	data, err := ctl.fineService.GetFineAccount(ctx, userID.(uint64), &req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	common.Success(c, 200, "success", data)
+}
+
+// GetUserFines 获取指定用户的欠款和罚金流水
+// GET /api/fines/users/:user_id
+func (ctl *FineController) GetUserFines(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	userID, err := strconv.ParseUint(c.Param("user_id"), 10, 64)
+	if err != nil {
+		c.Error(common.ErrBadRequest)
+		return
+	}
+
+	var req request.GetFineTransactionsRequest
+	if err := common.ValidateStruct(c, &req); err != nil {
+		c.Error(err)
+		return
+	}
+
+	data, err := ctl.fineService.GetFineAccount(ctx, userID, &req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	common.Success(c, 200, "success", data)
+}
+
+// RecordPayment 登记现金缴纳
+// POST /api/fines/users/:user_id/payments
+func (ctl *FineController) RecordPayment(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	userID, err := strconv.ParseUint(c.Param("user_id"), 10, 64)
+	if err != nil {
+		c.Error(common.ErrBadRequest)
+		return
+	}
+
+	var req request.RecordFinePaymentRequest
+	if err := common.ValidateStruct(c, &req); err != nil {
+		c.Error(err)
+		return
+	}
+
+	operatorID, _ := c.Get("user_id")
+
+	data, err := ctl.fineService.RecordPayment(ctx, operatorID.(uint64), userID, &req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	common.Success(c, 201, "缴纳登记成功", data)
+}
+
+// WaiveFine 减免罚金
+// POST /api/fines/users/:user_id/waivers
+func (ctl *FineController) WaiveFine(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	userID, err := strconv.ParseUint(c.Param("user_id"), 10, 64)
+	if err != nil {
+		c.Error(common.ErrBadRequest)
+		return
+	}
+
+	var req request.WaiveFineRequest
+	if err := common.ValidateStruct(c, &req); err != nil {
+		c.Error(err)
+		return
+	}
+
+	operatorID, _ := c.Get("user_id")
+
+	data, err := ctl.fineService.WaiveFine(ctx, operatorID.(uint64), userID, &req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	common.Success(c, 201, "罚金减免成功", data)
+}
+
+// AdjustFine 人工调整欠款
+// POST /api/fines/users/:user_id/adjustments
+func (ctl *FineController) AdjustFine(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	userID, err := strconv.ParseUint(c.Param("user_id"), 10, 64)
+	if err != nil {
+		c.Error(common.ErrBadRequest)
+		return
+	}
+
+	var req request.AdjustFineRequest
+	if err := common.ValidateStruct(c, &req); err != nil {
+		c.Error(err)
+		return
+	}
+
+	operatorID, _ := c.Get("user_id")
+
+	data, err := ctl.fineService.AdjustFine(ctx, operatorID.(uint64), userID, &req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	common.Success(c, 201, "欠款调整成功", data)
+}
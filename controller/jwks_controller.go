@@ -0,0 +1,26 @@
+package controller
+
+import (
+	"fmt"
+	"library-system/utils"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type JWKSController struct {
+	keys *utils.KeySet
+}
+
+func NewJWKSController(keys *utils.KeySet) *JWKSController {
+	return &JWKSController{
+		keys: keys,
+	}
+}
+
+// GetJWKS 公开 Access Token 的验证公钥，供其他服务自行验证 Token；按 JWKS 标准格式返回，不包装统一响应
+// GET /.well-known/jwks.json
+func (ctl *JWKSController) GetJWKS(c *gin.Context) {
+	c.Header("Cache-Control", fmt.Sprintf("public, max-age=%d", int(utils.JWKSCacheMaxAge.Seconds())))
+	c.JSON(http.StatusOK, ctl.keys.JWKS())
+}
@@ -0,0 +1,119 @@
+package controller
+
+import (
+	"library-system/common"
+	"library-system/dto/request"
+	"library-system/service"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+type LoanPolicyController struct {
+	policyService *service.LoanPolicyService
+}
+
+func NewLoanPolicyController(service *service.LoanPolicyService) *LoanPolicyController {
+	return &LoanPolicyController{
+		policyService: service,
+	}
+}
+
+// GetLoanPolicyList 获取借阅规则列表
+// GET /api/loan-policies
+func (ctl *LoanPolicyController) GetLoanPolicyList(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	data, err := ctl.policyService.GetLoanPolicyList(ctx)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	common.Success(c, 200, "success", data)
+}
+
+// ResolveLoanPolicy 查看某角色借阅某分类图书时实际生效的规则
+// GET /api/loan-policies/resolve
+func (ctl *LoanPolicyController) ResolveLoanPolicy(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	var req request.ResolveLoanPolicyRequest
+	if err := common.ValidateStruct(c, &req); err != nil {
+		c.Error(err)
+		return
+	}
+
+	data, err := ctl.policyService.ResolveLoanPolicy(ctx, &req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	common.Success(c, 200, "success", data)
+}
+
+// CreateLoanPolicy 创建借阅规则
+// POST /api/loan-policies
+func (ctl *LoanPolicyController) CreateLoanPolicy(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	var req request.CreateLoanPolicyRequest
+	if err := common.ValidateStruct(c, &req); err != nil {
+		c.Error(err)
+		return
+	}
+
+	data, err := ctl.policyService.CreateLoanPolicy(ctx, &req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	common.Success(c, 201, "借阅规则创建成功", data)
+}
+
+// UpdateLoanPolicy 更新借阅规则
+// PUT /api/loan-policies/:id
+func (ctl *LoanPolicyController) UpdateLoanPolicy(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.Error(common.ErrBadRequest)
+		return
+	}
+
+	var req request.UpdateLoanPolicyRequest
+	if err := common.ValidateStruct(c, &req); err != nil {
+		c.Error(err)
+		return
+	}
+
+	data, err := ctl.policyService.UpdateLoanPolicy(ctx, id, &req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	common.Success(c, 200, "借阅规则更新成功", data)
+}
+
+// DeleteLoanPolicy 删除借阅规则
+// DELETE /api/loan-policies/:id
+func (ctl *LoanPolicyController) DeleteLoanPolicy(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.Error(common.ErrBadRequest)
+		return
+	}
+
+	if err := ctl.policyService.DeleteLoanPolicy(ctx, id); err != nil {
+		c.Error(err)
+		return
+	}
+
+	common.Success(c, 200, "借阅规则删除成功", gin.H{})
+}
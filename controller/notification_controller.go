@@ -0,0 +1,95 @@
+package controller
+
+import (
+	"library-system/common"
+	"library-system/dto/request"
+	"library-system/service"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+type NotificationController struct {
+	notificationService *service.NotificationService
+}
+
+func NewNotificationController(service *service.NotificationService) *NotificationController {
+	return &NotificationController{
+		notificationService: service,
+	}
+}
+
+// GetNotificationList 获取我的站内消息
+// GET /api/notifications
+func (ctl *NotificationController) GetNotificationList(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	var req request.GetNotificationListRequest
+	if err := common.ValidateStruct(c, &req); err != nil {
+		c.Error(err)
+		return
+	}
+
+	userID, _ := c.Get("user_id")
+
+	data, err := ctl.notificationService.GetNotificationList(ctx, userID.(uint64), &req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	common.Success(c, 200, "success", data)
+}
+
+// GetUnreadCount 获取未读消息数
+// GET /api/notifications/unread-count
+func (ctl *NotificationController) GetUnreadCount(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	userID, _ := c.Get("user_id")
+
+	data, err := ctl.notificationService.GetUnreadCount(ctx, userID.(uint64))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	common.Success(c, 200, "success", data)
+}
+
+// MarkRead 标记消息已读
+// PUT /api/notifications/:id/read
+func (ctl *NotificationController) MarkRead(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.Error(common.ErrBadRequest)
+		return
+	}
+
+	userID, _ := c.Get("user_id")
+
+	if err := ctl.notificationService.MarkRead(ctx, userID.(uint64), id); err != nil {
+		c.Error(err)
+		return
+	}
+
+	common.Success(c, 200, "已标记为已读", gin.H{})
+}
+
+// MarkAllRead 全部标记为已读
+// PUT /api/notifications/read-all
+func (ctl *NotificationController) MarkAllRead(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	userID, _ := c.Get("user_id")
+
+	data, err := ctl.notificationService.MarkAllRead(ctx, userID.(uint64))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	common.Success(c, 200, "已全部标记为已读", data)
+}
@@ -0,0 +1,182 @@
+package controller
+
+import (
+	"library-system/common"
+	"library-system/dto/request"
+	"library-system/model"
+	"library-system/service"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+type ReviewController struct {
+	reviewService *service.ReviewService
+}
+
+func NewReviewController(service *service.ReviewService) *ReviewController {
+	return &ReviewController{
+		reviewService: service,
+	}
+}
+
+// GetReviewList 获取图书评价列表
+// GET /api/books/:id/reviews
+func (ctl *ReviewController) GetReviewList(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	bookID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.Error(common.ErrBadRequest)
+		return
+	}
+
+	var req request.GetReviewListRequest
+	if err := common.ValidateStruct(c, &req); err != nil {
+		c.Error(err)
+		return
+	}
+
+	data, err := ctl.reviewService.GetReviewList(ctx, bookID, &req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	common.Success(c, 200, "success", data)
+}
+
+// CreateReview 发表评价
+// POST /api/books/:id/reviews
+func (ctl *ReviewController) CreateReview(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	bookID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.Error(common.ErrBadRequest)
+		return
+	}
+
+	var req request.CreateReviewRequest
+	if err := common.ValidateStruct(c, &req); err != nil {
+		c.Error(err)
+		return
+	}
+
+	userID, _ := c.Get("user_id")
+
+	data, err := ctl.reviewService.CreateReview(ctx, userID.(uint64), bookID, &req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	common.Success(c, 201, "评价发表成功", data)
+}
+
+// UpdateReview 修改自己的评价
+// PUT /api/books/:id/reviews/:review_id
+func (ctl *ReviewController) UpdateReview(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	bookID, reviewID, ok := parseReviewPath(c)
+	if !ok {
+		return
+	}
+
+	var req request.UpdateReviewRequest
+	if err := common.ValidateStruct(c, &req); err != nil {
+		c.Error(err)
+		return
+	}
+
+	userID, _ := c.Get("user_id")
+
+	data, err := ctl.reviewService.UpdateReview(ctx, userID.(uint64), bookID, reviewID, &req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	common.Success(c, 200, "评价修改成功", data)
+}
+
+// DeleteReview 删除评价（本人或管理员）
+// DELETE /api/books/:id/reviews/:review_id
+func (ctl *ReviewController) DeleteReview(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	bookID, reviewID, ok := parseReviewPath(c)
+	if !ok {
+		return
+	}
+
+	userID, _ := c.Get("user_id")
+	canModerate := common.HasPermission(c, model.PermReviewsModerate)
+
+	if err := ctl.reviewService.DeleteReview(ctx, userID.(uint64), canModerate, bookID, reviewID); err != nil {
+		c.Error(err)
+		return
+	}
+
+	common.Success(c, 200, "评价删除成功", gin.H{})
+}
+
+// ModerateReview 隐藏或恢复评价
+// PUT /api/books/:id/reviews/:review_id/visibility
+func (ctl *ReviewController) ModerateReview(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	bookID, reviewID, ok := parseReviewPath(c)
+	if !ok {
+		return
+	}
+
+	var req request.ModerateReviewRequest
+	if err := common.ValidateStruct(c, &req); err != nil {
+		c.Error(err)
+		return
+	}
+
+	data, err := ctl.reviewService.ModerateReview(ctx, bookID, reviewID, &req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	common.Success(c, 200, "评价状态已更新", data)
+}
+
+// GetModerationList 评价审核列表（含已隐藏的评价）
+// GET /api/reviews
+func (ctl *ReviewController) GetModerationList(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	var req request.GetReviewModerationListRequest
+	if err := common.ValidateStruct(c, &req); err != nil {
+		c.Error(err)
+		return
+	}
+
+	data, err := ctl.reviewService.GetModerationList(ctx, &req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	common.Success(c, 200, "success", data)
+}
+
+func parseReviewPath(c *gin.Context) (uint64, uint64, bool) {
+	bookID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.Error(common.ErrBadRequest)
+		return 0, 0, false
+	}
+	reviewID, err := strconv.ParseUint(c.Param("review_id"), 10, 64)
+	if err != nil {
+		c.Error(common.ErrBadRequest)
+		return 0, 0, false
+	}
+	return bookID, reviewID, true
+}
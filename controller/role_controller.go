@@ -0,0 +1,105 @@
+package controller
+
+import (
+	"library-system/common"
+	"library-system/dto/request"
+	"library-system/service"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+type RoleController struct {
+	roleService *service.RoleService
+}
+
+func NewRoleController(roleService *service.RoleService) *RoleController {
+	return &RoleController{
+		roleService: roleService,
+	}
+}
+
+// GetPermissionList 获取系统支持的全部权限
+// GET /api/admin/permissions
+func (ctl *RoleController) GetPermissionList(c *gin.Context) {
+	common.Success(c, 200, "success", ctl.roleService.GetPermissionList())
+}
+
+// GetRoleList 获取角色及其权限
+// GET /api/admin/roles
+func (ctl *RoleController) GetRoleList(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	data, err := ctl.roleService.GetRoleList(ctx)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	common.Success(c, 200, "success", data)
+}
+
+// CreateRole 创建自定义角色
+// POST /api/admin/roles
+func (ctl *RoleController) CreateRole(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	var req request.CreateRoleRequest
+	if err := common.ValidateStruct(c, &req); err != nil {
+		c.Error(err)
+		return
+	}
+
+	data, err := ctl.roleService.CreateRole(ctx, &req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	common.Success(c, 201, "角色创建成功", data)
+}
+
+// UpdateRole 修改角色描述或权限
+// PUT /api/admin/roles/:id
+func (ctl *RoleController) UpdateRole(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.Error(common.ErrBadRequest)
+		return
+	}
+
+	var req request.UpdateRoleRequest
+	if err := common.ValidateStruct(c, &req); err != nil {
+		c.Error(err)
+		return
+	}
+
+	data, err := ctl.roleService.UpdateRole(ctx, id, &req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	common.Success(c, 200, "角色更新成功", data)
+}
+
+// DeleteRole 删除自定义角色
+// DELETE /api/admin/roles/:id
+func (ctl *RoleController) DeleteRole(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.Error(common.ErrBadRequest)
+		return
+	}
+
+	if err := ctl.roleService.DeleteRole(ctx, id); err != nil {
+		c.Error(err)
+		return
+	}
+
+	common.Success(c, 200, "角色删除成功", gin.H{})
+}
@@ -0,0 +1,100 @@
+package controller
+
+import (
+	"library-system/common"
+	"library-system/service"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+type SessionController struct {
+	sessionService *service.SessionService
+}
+
+func NewSessionController(sessionService *service.SessionService) *SessionController {
+	return &SessionController{
+		sessionService: sessionService,
+	}
+}
+
+// GetMySessions 获取当前用户已登录的设备
+// GET /api/users/me/sessions
+func (ctl *SessionController) GetMySessions(c *gin.Context) {
+	ctx := c.Request.Context()
+	userID, _ := c.Get("user_id")
+	sessionID, _ := c.Get("session_id")
+
+	data, err := ctl.sessionService.GetSessionList(ctx, userID.(uint64), sessionID.(string))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	common.Success(c, 200, "success", data)
+}
+
+// RevokeMySession 登出指定设备
+// DELETE /api/users/me/sessions/:id
+func (ctl *SessionController) RevokeMySession(c *gin.Context) {
+	ctx := c.Request.Context()
+	userID, _ := c.Get("user_id")
+
+	if err := ctl.sessionService.Revoke(ctx, userID.(uint64), c.Param("id")); err != nil {
+		c.Error(err)
+		return
+	}
+
+	common.Success(c, 200, "会话已注销", gin.H{})
+}
+
+// RevokeAllMySessions 在所有设备上登出（包括当前设备）
+// DELETE /api/users/me/sessions
+func (ctl *SessionController) RevokeAllMySessions(c *gin.Context) {
+	ctx := c.Request.Context()
+	userID, _ := c.Get("user_id")
+
+	if err := ctl.sessionService.RevokeAll(ctx, userID.(uint64)); err != nil {
+		c.Error(err)
+		return
+	}
+
+	common.Success(c, 200, "已在所有设备上登出", gin.H{})
+}
+
+// GetUserSessions 查看指定用户的会话
+// GET /api/users/:id/sessions
+func (ctl *SessionController) GetUserSessions(c *gin.Context) {
+	ctx := c.Request.Context()
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.Error(common.ErrBadRequest)
+		return
+	}
+
+	data, err := ctl.sessionService.GetUserSessionList(ctx, id)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	common.Success(c, 200, "success", data)
+}
+
+// ForceLogout 强制用户在所有设备上登出
+// POST /api/users/:id/logout
+func (ctl *SessionController) ForceLogout(c *gin.Context) {
+	ctx := c.Request.Context()
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.Error(common.ErrBadRequest)
+		return
+	}
+
+	if err := ctl.sessionService.ForceLogout(ctx, id); err != nil {
+		c.Error(err)
+		return
+	}
+
+	common.Success(c, 200, "已强制该用户登出", gin.H{})
+}
@@ -3,6 +3,7 @@ package controller
 import (
 	"library-system/common"
 	"library-system/dto/request"
+	"library-system/model"
 	"library-system/service"
 	"strconv"
 
@@ -65,10 +66,9 @@ func (ctl *StatsController) GetUserStats(c *gin.Context) {
 		return
 	}
 
-	// 权限检查：普通用户只能查看自己的统计
+	// 权限检查：没有统计查看权限的用户只能查看自己的统计
 	currentUserID, _ := c.Get("user_id")
-	role, _ := c.Get("role")
-	if role != "admin" && currentUserID.(uint64) != userID {
+	if !common.HasPermission(c, model.PermStatsRead) && currentUserID.(uint64) != userID {
 		c.Error(common.ErrPermissionDenied)
 		return
 	}
@@ -114,4 +114,24 @@ func (ctl *StatsController) GetCategoryStats(c *gin.Context) {
 	}
 
 	common.Success(c, 200, "success", data)
-}
\ No newline at end of file
+}
+
+// GetDamagedItems 获取损坏/遗失图书报表
+// GET /api/stats/damaged-items
+func (ctl *StatsController) GetDamagedItems(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	var req request.GetDamagedItemsRequest
+	if err := common.ValidateStruct(c, &req); err != nil {
+		c.Error(err)
+		return
+	}
+
+	data, err := ctl.statsService.GetDamagedItems(ctx, &req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	common.Success(c, 200, "success", data)
+}
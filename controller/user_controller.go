@@ -3,6 +3,7 @@ package controller
 import (
 	"library-system/common"
 	"library-system/dto/request"
+	"library-system/model"
 	"library-system/service"
 	"strconv"
 
@@ -55,6 +56,74 @@ func (ctl *UserController) Login(c *gin.Context) {
 	common.Success(c, 200, "登录成功", data)
 }
 
+func (ctl *UserController) ForgotPassword(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	var req request.ForgotPasswordRequest
+	if err := common.ValidateStruct(c, &req); err != nil {
+		c.Error(err)
+		return
+	}
+
+	if err := ctl.userService.ForgotPassword(ctx, &req); err != nil {
+		c.Error(err)
+		return
+	}
+
+	common.Success(c, 200, "如果该邮箱已注册，重置密码邮件将很快送达", gin.H{})
+}
+
+func (ctl *UserController) ResetPassword(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	var req request.ResetPasswordRequest
+	if err := common.ValidateStruct(c, &req); err != nil {
+		c.Error(err)
+		return
+	}
+
+	if err := ctl.userService.ResetPassword(ctx, &req); err != nil {
+		c.Error(err)
+		return
+	}
+
+	common.Success(c, 200, "密码已重置，请重新登录", gin.H{})
+}
+
+func (ctl *UserController) VerifyEmail(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	var req request.VerifyEmailRequest
+	if err := common.ValidateStruct(c, &req); err != nil {
+		c.Error(err)
+		return
+	}
+
+	if err := ctl.userService.VerifyEmail(ctx, &req); err != nil {
+		c.Error(err)
+		return
+	}
+
+	common.Success(c, 200, "邮箱验证成功", gin.H{})
+}
+
+func (ctl *UserController) ResendVerification(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	var req request.ResendVerificationRequest
+	if err := common.ValidateStruct(c, &req); err != nil {
+		c.Error(err)
+		return
+	}
+
+	if err := ctl.userService.ResendVerification(ctx, &req); err != nil {
+		c.Error(err)
+		return
+	}
+
+	common.Success(c, 200, "如果该邮箱待验证，验证邮件将很快送达", gin.H{})
+}
+
 func (ctl *UserController) RefreshToken(c *gin.Context) {
 	ctx := c.Request.Context()
 
@@ -79,7 +148,8 @@ func (ctl *UserController) Logout (c *gin.Context) {
 
 	userID, _ := c.Get("user_id")
 	tokenID, _ := c.Get("token_id")
-	if err := ctl.userService.Logout(ctx, userID.(uint64), tokenID.(string)); err != nil {
+	sessionID, _ := c.Get("session_id")
+	if err := ctl.userService.Logout(ctx, userID.(uint64), sessionID.(string), tokenID.(string)); err != nil {
 		c.Error(err)
 		return
 	}
@@ -118,6 +188,10 @@ func (ctl *UserController) UpdateUser (c *gin.Context) {
 		return
 	}
 
+	if data.PendingEmail != "" {
+		common.Success(c, 200, "更新成功，新邮箱需打开验证邮件中的链接后生效", data)
+		return
+	}
 	common.Success(c, 200, "更新成功", data)
 }
 
@@ -126,13 +200,14 @@ func (ctl *UserController) ChangePwd (c *gin.Context) {
 
 	userID, _ := c.Get("user_id")
 	tokenID, _ := c.Get("token_id")
+	sessionID, _ := c.Get("session_id")
 	var req request.ChangePwdRequest
 	if err := common.ValidateStruct(c, &req); err != nil {
 		c.Error(err)
 		return
 	}
 
-	if err := ctl.userService.ChangePwd(ctx, userID.(uint64), tokenID.(string), &req); err !=  nil {
+	if err := ctl.userService.ChangePwd(ctx, userID.(uint64), sessionID.(string), tokenID.(string), &req); err !=  nil {
 		c.Error(err)
 		return
 	}
@@ -167,6 +242,17 @@ func (ctl *UserController) CreateUser(c *gin.Context) {
 		return
 	}
 
+	// 分配普通读者以外的角色需要角色管理权限，防止越权提升
+	if req.Role != "" && req.Role != model.RoleUser && !common.HasPermission(c, model.PermRolesManage) {
+		c.Error(common.ErrPermissionDenied)
+		return
+	}
+	// 只有管理员能创建管理员
+	if req.Role == model.RoleAdmin && c.GetString("role") != model.RoleAdmin {
+		c.Error(common.ErrPermissionDenied)
+		return
+	}
+
 	data, err := ctl.userService.CreateUser(ctx, &req)
 	if err != nil {
 		c.Error(err)
@@ -191,7 +277,12 @@ func (ctl *UserController) UpdateUserByAdmin(c *gin.Context) {
 		return
 	}
 
-	data, err := ctl.userService.UpdateUserByAdmin(ctx, id, &req)
+	if req.Role != nil && !common.HasPermission(c, model.PermRolesManage) {
+		c.Error(common.ErrPermissionDenied)
+		return
+	}
+
+	data, err := ctl.userService.UpdateUserByAdmin(ctx, c.GetString("role"), id, &req)
 	if err != nil {
 		c.Error(err)
 		return		
@@ -209,11 +300,46 @@ func (ctl *UserController) DeleteUser(c *gin.Context) {
 		return		
 	}
 
-	err = ctl.userService.DeleteUser(ctx, id)
+	err = ctl.userService.DeleteUser(ctx, c.GetString("role"), id)
 	if err != nil {
 		c.Error(err)
 		return		
 	}
 	
 	common.Success(c, 200, "用户删除成功", gin.H{})
-}
\ No newline at end of file
+}
+func (ctl *UserController) GetUserTrash(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	var req request.GetUserTrashRequest
+	if err := common.ValidateStruct(c, &req); err != nil {
+		c.Error(err)
+		return
+	}
+
+	data, err := ctl.userService.GetUserTrash(ctx, &req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	common.Success(c, 200, "success", data)
+}
+
+func (ctl *UserController) RestoreUser(c *gin.Context) {
+	ctx := c.Request.Context()
+	idStr := c.Param("id")
+	id, err := strconv.ParseUint(idStr, 10, 64)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	err = ctl.userService.RestoreUser(ctx, id)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	common.Success(c, 200, "用户恢复成功", gin.H{})
+}
@@ -16,8 +16,17 @@ func MigrateSQL(db *gorm.DB) error{
 		&model.User{},
 		&model.Category{},
 		&model.Book{},
+		&model.BookCopy{},
 		&model.BorrowRecord{},
 		&model.Reservation{},
+		&model.LoanPolicy{},
+		&model.FineTransaction{},
+		&model.NotificationOutbox{},
+		&model.Notification{},
+		&model.Review{},
+		&model.AuditEvent{},
+		&model.Role{},
+		&model.RolePermission{},
 	)
 	if err != nil {
 		return fmt.Errorf("MySQL自动迁移失败: %v", err)
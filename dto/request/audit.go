@@ -0,0 +1,13 @@
+package request
+
+// GetAuditEventListRequest 审计日志查询，时间支持 RFC3339 或 YYYY-MM-DD（结束日期含当天）
+type GetAuditEventListRequest struct {
+	ActorID    *uint64 `form:"actor_id"`
+	EntityType string  `form:"entity_type" binding:"omitempty,max=30"`
+	EntityID   *uint64 `form:"entity_id"`
+	Action     string  `form:"action" binding:"omitempty,max=30"`
+	StartTime  string  `form:"start_time"`
+	EndTime    string  `form:"end_time"`
+	Page       int     `form:"page" binding:"omitempty,min=1"`
+	Limit      int     `form:"limit" binding:"omitempty,min=1,max=100"`
+}
@@ -8,7 +8,7 @@ type CreateBookRequest struct {
     Publisher   string  `json:"publisher" binding:"required,min=1,max=100"`
     PublishDate *string `json:"publish_date"`
     Price       *float64 `json:"price" binding:"omitempty,gt=0"`
-    Stock       int     `json:"stock" binding:"gte=0"`
+    Stock       int     `json:"stock" binding:"gte=0"` // 初始副本数量，之后通过 /books/:id/copies 管理
     Description *string `json:"description" binding:"max=1000"`
     CoverURL    *string `json:"cover_url"`
 }
@@ -27,10 +27,21 @@ type GetBookListRequest struct {
     CategoryID    *uint   `form:"category_id"`
     Publisher     *string `form:"publisher"`
 
+    // 为 true 时 category_id 同时匹配其所有子孙分类
+    IncludeDescendants bool   `form:"include_descendants"`
+    // 由服务层根据 IncludeDescendants 展开，不接受客户端传入
+    CategoryIDs        []uint `form:"-"`
+
     AvailableOnly *bool   `form:"available_only"`
+    YearFrom      *int    `form:"year_from" binding:"omitempty,min=1,max=9999"`
+    YearTo        *int    `form:"year_to" binding:"omitempty,min=1,max=9998"`
 
     SortBy        *string `form:"sort_by" binding:"omitempty,oneof=title author publish_date borrow_count created_at"`
     Order         *string `form:"order" binding:"omitempty,oneof=asc desc"`
+
+    // 返回分面统计，FacetLimit 限制作者、出版社分面的条目数
+    IncludeFacets bool    `form:"include_facets"`
+    FacetLimit    int     `form:"facet_limit" binding:"omitempty,min=1,max=50"`
 }
 
 type UpdateBookRequest struct {
@@ -41,9 +52,34 @@ type UpdateBookRequest struct {
     Publisher   *string  `json:"publisher" binding:"omitempty,min=1,max=100"`
     PublishDate *string  `json:"publish_date"`
     Price       *float64 `json:"price" binding:"omitempty,gt=0"`
-    Stock       *int     `json:"stock" binding:"omitempty,gte=0"`
     Description *string  `json:"description" binding:"omitempty,max=1000"`
     CoverURL    *string  `json:"cover_url"`
 }
 
 
+
+type SearchBooksRequest struct {
+    Q             string `form:"q" binding:"required,min=1,max=100"`
+    CategoryID    *uint  `form:"category_id"`
+    AvailableOnly *bool  `form:"available_only"`
+    Page          int    `form:"page" binding:"omitempty,min=1"`
+    Limit         int    `form:"limit" binding:"omitempty,min=1,max=100"`
+}
+
+type LookupBookRequest struct {
+    ISBN string `json:"isbn" binding:"required"`
+}
+
+// ImportBooksRequest 随 multipart 文件一同提交的导入参数
+type ImportBooksRequest struct {
+    DryRun  bool   `form:"dry_run"` // 只校验不写入
+    Atomic  bool   `form:"atomic"`  // 任一行出错则全部不导入
+    // 列映射 JSON，键为字段名，值为文件中的表头，如 {"title":"书名","category":"分类"}；
+    // 未映射的字段按内置的中英文表头自动识别
+    Mapping string `form:"mapping"`
+}
+
+type GetBookTrashRequest struct {
+    Page  int `form:"page" binding:"omitempty,min=1"`
+    Limit int `form:"limit" binding:"omitempty,min=1,max=100"`
+}
@@ -0,0 +1,20 @@
+package request
+
+type GetBookCopyListRequest struct {
+	Status *string `form:"status" binding:"omitempty,oneof=available on_loan in_repair lost withdrawn"`
+}
+
+type CreateBookCopyRequest struct {
+	Barcode       *string `json:"barcode" binding:"omitempty,min=1,max=50"`
+	ShelfLocation *string `json:"shelf_location" binding:"omitempty,max=100"`
+	AcquiredAt    *string `json:"acquired_at"` // 格式：YYYY-MM-DD
+	Remark        *string `json:"remark" binding:"omitempty,max=255"`
+}
+
+type UpdateBookCopyRequest struct {
+	Barcode       *string `json:"barcode" binding:"omitempty,min=1,max=50"`
+	ShelfLocation *string `json:"shelf_location" binding:"omitempty,max=100"`
+	AcquiredAt    *string `json:"acquired_at"`
+	Status        *string `json:"status" binding:"omitempty,oneof=available in_repair lost withdrawn"`
+	Remark        *string `json:"remark" binding:"omitempty,max=255"`
+}
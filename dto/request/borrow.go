@@ -1,15 +1,43 @@
 package request
 
+// BorrowBookRequest book_id 与 barcode 至少填一个；扫码借书时按条码借出指定副本
 type BorrowBookRequest struct {
-	BookId     uint64 `json:"book_id" binding:"required"`
-	BorrowDays *int   `json:"borrow_days"`
+	BookId     uint64  `json:"book_id"`
+	Barcode    *string `json:"barcode" binding:"omitempty,max=50"`
+	BorrowDays *int    `json:"borrow_days"`
 }
 
+// ReturnBookRequest 还书；自助还书只能按完好（good）归还，损坏、遗失由馆员在流通台登记
 type ReturnBookRequest struct {
 	Condition *string `json:"condition" binding:"omitempty,oneof=good damaged lost"`
 	Remark    *string `json:"remark"    binding:"omitempty,max=255"`
 }
 
+// ReturnByBarcodeRequest 扫码还书
+type ReturnByBarcodeRequest struct {
+	Barcode   string  `json:"barcode" binding:"required,max=50"`
+	Condition *string `json:"condition" binding:"omitempty,oneof=good damaged lost"`
+	Remark    *string `json:"remark"    binding:"omitempty,max=255"`
+}
+
+// CheckoutRequest 馆员代借：user_id、username、card_number 三选一确定读者，book_id 与 barcode 至少填一个
+type CheckoutRequest struct {
+	UserID     *uint64 `json:"user_id"`
+	Username   *string `json:"username" binding:"omitempty,max=20"`
+	CardNumber *string `json:"card_number" binding:"omitempty,max=20"`
+	BookId     uint64  `json:"book_id"`
+	Barcode    *string `json:"barcode" binding:"omitempty,max=50"`
+	BorrowDays *int    `json:"borrow_days"`
+}
+
+// CheckinRequest 馆员代还：borrow_id 与 barcode 二选一
+type CheckinRequest struct {
+	BorrowID  *uint64 `json:"borrow_id"`
+	Barcode   *string `json:"barcode" binding:"omitempty,max=50"`
+	Condition *string `json:"condition" binding:"omitempty,oneof=good damaged lost"`
+	Remark    *string `json:"remark"    binding:"omitempty,max=255"`
+}
+
 type RenewBorrowRequest struct {
 	RenewDays *int `json:"renew_days" binding:"omitempty,min=1,max=90"`
 }
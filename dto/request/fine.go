@@ -0,0 +1,24 @@
+package request
+
+type GetFineTransactionsRequest struct {
+	Page  int `form:"page" binding:"omitempty,min=1"`
+	Limit int `form:"limit" binding:"omitempty,min=1,max=100"`
+}
+
+// RecordFinePaymentRequest 登记现金缴纳
+type RecordFinePaymentRequest struct {
+	Amount float64 `json:"amount" binding:"required,gt=0"`
+	Remark *string `json:"remark" binding:"omitempty,max=255"`
+}
+
+// WaiveFineRequest 减免罚金，不填金额时减免全部欠款
+type WaiveFineRequest struct {
+	Amount *float64 `json:"amount" binding:"omitempty,gt=0"`
+	Reason string   `json:"reason" binding:"required,max=255"`
+}
+
+// AdjustFineRequest 人工调整欠款，正数增加、负数减少
+type AdjustFineRequest struct {
+	Amount float64 `json:"amount" binding:"required"`
+	Reason string  `json:"reason" binding:"required,max=255"`
+}
@@ -0,0 +1,29 @@
+package request
+
+type CreateLoanPolicyRequest struct {
+	Role          *string  `json:"role" binding:"omitempty,min=1,max=30"`
+	CategoryID    *uint    `json:"category_id"`
+	LoanDays      int      `json:"loan_days" binding:"required,min=1,max=365"`
+	MaxRenewCount *int     `json:"max_renew_count" binding:"required,gte=0,lte=10"`
+	RenewDays     int      `json:"renew_days" binding:"required,min=1,max=365"`
+	MaxLoans      *int     `json:"max_loans" binding:"omitempty,gte=0"`
+	FineRate      *float64 `json:"fine_rate" binding:"required,gte=0"`
+	FineCap       *float64 `json:"fine_cap" binding:"omitempty,gte=0"`
+	Description   *string  `json:"description" binding:"omitempty,max=200"`
+}
+
+// UpdateLoanPolicyRequest 适用范围（角色、分类）不可修改，如需调整请删除后重建
+type UpdateLoanPolicyRequest struct {
+	LoanDays      *int     `json:"loan_days" binding:"omitempty,min=1,max=365"`
+	MaxRenewCount *int     `json:"max_renew_count" binding:"omitempty,gte=0,lte=10"`
+	RenewDays     *int     `json:"renew_days" binding:"omitempty,min=1,max=365"`
+	MaxLoans      *int     `json:"max_loans" binding:"omitempty,gte=0"`
+	FineRate      *float64 `json:"fine_rate" binding:"omitempty,gte=0"`
+	FineCap       *float64 `json:"fine_cap" binding:"omitempty,gte=0"`
+	Description   *string  `json:"description" binding:"omitempty,max=200"`
+}
+
+type ResolveLoanPolicyRequest struct {
+	Role       string `form:"role" binding:"required"`
+	CategoryID uint   `form:"category_id" binding:"required"`
+}
@@ -0,0 +1,7 @@
+package request
+
+type GetNotificationListRequest struct {
+	UnreadOnly bool `form:"unread_only"`
+	Page       int  `form:"page" binding:"omitempty,min=1"`
+	Limit      int  `form:"limit" binding:"omitempty,min=1,max=100"`
+}
@@ -0,0 +1,29 @@
+package request
+
+type GetReviewListRequest struct {
+	Page  int `form:"page" binding:"omitempty,min=1"`
+	Limit int `form:"limit" binding:"omitempty,min=1,max=100"`
+}
+
+// GetReviewModerationListRequest 管理员审核列表，可按图书和状态筛选
+type GetReviewModerationListRequest struct {
+	BookID *uint64 `form:"book_id"`
+	Status *string `form:"status" binding:"omitempty,oneof=visible hidden"`
+	Page   int     `form:"page" binding:"omitempty,min=1"`
+	Limit  int     `form:"limit" binding:"omitempty,min=1,max=100"`
+}
+
+type CreateReviewRequest struct {
+	Rating  int     `json:"rating" binding:"required,min=1,max=5"`
+	Content *string `json:"content" binding:"omitempty,max=2000"`
+}
+
+type UpdateReviewRequest struct {
+	Rating  *int    `json:"rating" binding:"omitempty,min=1,max=5"`
+	Content *string `json:"content" binding:"omitempty,max=2000"`
+}
+
+type ModerateReviewRequest struct {
+	Hidden *bool   `json:"hidden" binding:"required"`
+	Reason *string `json:"reason" binding:"omitempty,max=255"`
+}
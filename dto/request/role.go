@@ -0,0 +1,13 @@
+package request
+
+type CreateRoleRequest struct {
+	Name        string   `json:"name" binding:"required,min=2,max=30,alphanum"`
+	Description string   `json:"description" binding:"max=255"`
+	Permissions []string `json:"permissions"`
+}
+
+// UpdateRoleRequest permissions 不为 null 时整体替换角色的权限
+type UpdateRoleRequest struct {
+	Description *string  `json:"description" binding:"omitempty,max=255"`
+	Permissions []string `json:"permissions"`
+}
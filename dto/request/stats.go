@@ -10,8 +10,17 @@ type GetPopularBooksRequest struct {
 	Limit      int  `form:"limit"`       // 默认10
 	Period     string `form:"period"`    // 7d/30d/90d/all，默认30d
 	CategoryID *uint  `form:"category_id"`
+	IncludeDescendants bool `form:"include_descendants"` // 同时统计子孙分类下的图书
 }
 
 type GetUserStatsRequest struct {
 	UserID uint64 `uri:"user_id" binding:"required"`
-}
\ No newline at end of file
+}
+
+type GetDamagedItemsRequest struct {
+	Condition *string `form:"condition" binding:"omitempty,oneof=damaged lost"`
+	StartDate string  `form:"start_date"` // 按归还日期筛选，格式：YYYY-MM-DD
+	EndDate   string  `form:"end_date"`
+	Page      int     `form:"page"`
+	Limit     int     `form:"limit"`
+}
@@ -27,6 +27,23 @@ type ChangePwdRequest struct {
 	NewPassword string `json:"new_password" binding:"required,min=8,max=32"`
 }
 
+type ForgotPasswordRequest struct {
+	Email string `json:"email" binding:"required,email"`
+}
+
+type ResetPasswordRequest struct {
+	Token       string `json:"token" binding:"required,max=100"`
+	NewPassword string `json:"new_password" binding:"required,min=8,max=32"`
+}
+
+type ResendVerificationRequest struct {
+	Email string `json:"email" binding:"required,email"`
+}
+
+type VerifyEmailRequest struct {
+	Token string `json:"token" binding:"required,max=100"`
+}
+
 type GetUserListRequest struct {
 	Page 		int 	`form:"page"`
 	Limit 		int 	`form:"limit"`
@@ -40,6 +57,7 @@ type CreateUserRequest struct {
 	Password    string `json:"password" binding:"required,min=8,max=32"`
 	Email       string `json:"email"    binding:"required,email"`
 	Phone       string `json:"phone"`
+	CardNumber  *string `json:"card_number" binding:"omitempty,min=1,max=20,alphanum"`
 	Role        string `json:"role"`
 	BorrowLimit int    `json:"borrow_limit"`
 }
@@ -48,7 +66,13 @@ type UpdateUserByAdminRequest struct {
 	Username 	*string `json:"username" binding:"omitempty,min=4,max=20,alphanumunicode"`
 	Email 	 	*string `json:"email" binding:"omitempty,email"`
 	Phone    	*string `json:"phone" binding:"omitempty,len=11,numeric"`
+	CardNumber  *string `json:"card_number" binding:"omitempty,min=1,max=20,alphanum"`
 	Role        *string `json:"role"`
 	Status      *string `json:"status"`
 	BorrowLimit *int    `json:"borrow_limit"`
 }
+
+type GetUserTrashRequest struct {
+	Page  int `form:"page" binding:"omitempty,min=1"`
+	Limit int `form:"limit" binding:"omitempty,min=1,max=100"`
+}
@@ -0,0 +1,27 @@
+package response
+
+type AuditFieldChange struct {
+	Before interface{} `json:"before"`
+	After  interface{} `json:"after"`
+}
+
+type AuditEventItem struct {
+	ID         uint64                      `json:"id"`
+	ActorID    *uint64                     `json:"actor_id"`
+	ActorName  string                      `json:"actor_name"`
+	Action     string                      `json:"action"`
+	EntityType string                      `json:"entity_type"`
+	EntityID   uint64                      `json:"entity_id"`
+	Changes    map[string]AuditFieldChange `json:"changes"`
+	IP         string                      `json:"ip"`
+	RequestID  string                      `json:"request_id"`
+	CreatedAt  string                      `json:"created_at"`
+}
+
+type GetAuditEventListResponse struct {
+	Total      int64            `json:"total"`
+	Page       int              `json:"page"`
+	Limit      int              `json:"limit"`
+	TotalPages int              `json:"total_pages"`
+	Events     []AuditEventItem `json:"events"`
+}
@@ -47,6 +47,7 @@ type BookListItem struct {
     Available    int      `json:"available"`
 
     CoverURL     string   `json:"cover_url"`
+    CoverThumbnails map[string]string `json:"cover_thumbnails,omitempty"`
     BorrowCount  int      `json:"borrow_count"`
 }
 
@@ -56,6 +57,34 @@ type GetBookListResponse struct {
     Limit      int            `json:"limit"`
     TotalPages int            `json:"total_pages"`
     Books      []BookListItem `json:"books"`
+    Facets     *BookFacets    `json:"facets,omitempty"`
+}
+
+type FacetBucket struct {
+    Value string `json:"value"`
+    Label string `json:"label"`
+    Count int64  `json:"count"`
+}
+
+type YearFacetBucket struct {
+    From  int    `json:"from"`
+    To    int    `json:"to"`
+    Label string `json:"label"`
+    Count int64  `json:"count"`
+}
+
+type AvailabilityFacet struct {
+    Available   int64 `json:"available"`
+    Unavailable int64 `json:"unavailable"`
+}
+
+// BookFacets 基于当前筛选条件的分面统计，每个维度统计时忽略该维度自身的筛选
+type BookFacets struct {
+    Categories   []FacetBucket     `json:"categories"`
+    Authors      []FacetBucket     `json:"authors"`
+    Publishers   []FacetBucket     `json:"publishers"`
+    Years        []YearFacetBucket `json:"years"`
+    Availability AvailabilityFacet `json:"availability"`
 }
 
 type CategoryDetails struct {
@@ -76,8 +105,10 @@ type GetBookDetailsResponse struct {
     Available    int               `json:"available"`
     Description  string            `json:"description"`
     CoverURL     string            `json:"cover_url"`
+    CoverThumbnails map[string]string `json:"cover_thumbnails,omitempty"`
     BorrowCount  int               `json:"borrow_count"`
     Rating       float64           `json:"rating"`
+    ReviewCount  int               `json:"review_count"`
     CreatedAt    time.Time         `json:"created_at"`
     UpdatedAt    time.Time         `json:"updated_at"`
 }
@@ -93,8 +124,107 @@ type UpdateBookResponse struct {
     Publisher   *string  `json:"publisher,omitempty"`
     PublishDate *string  `json:"publish_date,omitempty"`
     Price       *float64 `json:"price,omitempty"`
-    Stock       *int     `json:"stock,omitempty"`
-    Available   *int     `json:"available,omitempty"`
     Description *string  `json:"description,omitempty"`
     CoverURL    *string  `json:"cover_url,omitempty"`
-}
\ No newline at end of file
+}
+// BookHighlight 命中关键词以 <em></em> 标记，其余内容已做 HTML 转义
+type BookHighlight struct {
+    Title       string `json:"title"`
+    Author      string `json:"author"`
+    Publisher   string `json:"publisher"`
+    Description string `json:"description,omitempty"`
+}
+
+type SearchBookItem struct {
+    ID           uint64        `json:"id"`
+    Title        string        `json:"title"`
+    Author       string        `json:"author"`
+    ISBN         string        `json:"isbn"`
+    CategoryID   uint          `json:"category_id"`
+    CategoryName string        `json:"category_name"`
+    Publisher    string        `json:"publisher"`
+    CoverURL     string        `json:"cover_url"`
+    Available    int           `json:"available"`
+    Rating       float64       `json:"rating"`
+    Score        float64       `json:"score"`
+    Highlight    BookHighlight `json:"highlight"`
+}
+
+type SearchBooksResponse struct {
+    Query      string           `json:"query"`
+    Total      int64            `json:"total"`
+    Page       int              `json:"page"`
+    Limit      int              `json:"limit"`
+    TotalPages int              `json:"total_pages"`
+    Books      []SearchBookItem `json:"books"`
+}
+
+// BookDraft 与创建图书请求字段一致，管理员补充分类与库存后即可直接提交创建
+type BookDraft struct {
+    Title       string  `json:"title"`
+    Author      string  `json:"author"`
+    ISBN        string  `json:"isbn"`
+    Publisher   string  `json:"publisher"`
+    PublishDate *string `json:"publish_date"`
+    Description *string `json:"description"`
+    CoverURL    *string `json:"cover_url"`
+}
+
+type LookupBookResponse struct {
+    ISBN           string    `json:"isbn"`
+    ISBN10         string    `json:"isbn10,omitempty"`
+    Source         string    `json:"source"`
+    ExistingBookID *uint64   `json:"existing_book_id"` // 馆内已有该 ISBN 的图书时返回其 ID
+    Pages          int       `json:"pages,omitempty"`
+    Subjects       []string  `json:"subjects,omitempty"`
+    Book           BookDraft `json:"book"`
+}
+
+type ImportRowError struct {
+    Row     int    `json:"row"` // 文件中的行号，表头为第 1 行
+    ISBN    string `json:"isbn,omitempty"`
+    Field   string `json:"field,omitempty"`
+    Message string `json:"message"`
+}
+
+type ImportBooksResponse struct {
+    DryRun          bool              `json:"dry_run"`
+    Atomic          bool              `json:"atomic"`
+    Committed       bool              `json:"committed"` // 是否有数据写入
+    TotalRows       int               `json:"total_rows"`
+    ValidRows       int               `json:"valid_rows"`
+    ImportedCount   int               `json:"imported_count"`
+    FailedCount     int               `json:"failed_count"`
+    Columns         map[string]string `json:"columns"` // 实际采用的字段与表头对应关系
+    Errors          []ImportRowError  `json:"errors"`
+    ErrorsTruncated bool              `json:"errors_truncated"`
+    ReportID        string            `json:"report_id,omitempty"` // 存在错误时生成，可下载完整报告
+}
+
+type UploadCoverResponse struct {
+    BookID      uint64            `json:"book_id"`
+    CoverURL    string            `json:"cover_url"`
+    Thumbnails  map[string]string `json:"thumbnails"`
+    ContentType string            `json:"content_type"`
+    Size        int               `json:"size"`
+    Width       int               `json:"width"`
+    Height      int               `json:"height"`
+}
+
+type TrashedBookItem struct {
+    ID           uint64 `json:"id"`
+    Title        string `json:"title"`
+    Author       string `json:"author"`
+    ISBN         string `json:"isbn"`
+    CategoryID   uint   `json:"category_id"`
+    CategoryName string `json:"category_name"`
+    DeletedAt    string `json:"deleted_at"`
+}
+
+type GetBookTrashResponse struct {
+    Total      int64             `json:"total"`
+    Page       int               `json:"page"`
+    Limit      int               `json:"limit"`
+    TotalPages int               `json:"total_pages"`
+    Books      []TrashedBookItem `json:"books"`
+}
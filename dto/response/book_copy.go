@@ -0,0 +1,23 @@
+package response
+
+import "time"
+
+type BookCopyItem struct {
+	ID            uint64     `json:"id"`
+	BookID        uint64     `json:"book_id"`
+	Barcode       string     `json:"barcode"`
+	ShelfLocation string     `json:"shelf_location"`
+	AcquiredAt    *time.Time `json:"acquired_at"`
+	Status        string     `json:"status"`
+	Remark        string     `json:"remark,omitempty"`
+	CreatedAt     time.Time  `json:"created_at"`
+	UpdatedAt     time.Time  `json:"updated_at"`
+}
+
+type GetBookCopyListResponse struct {
+	BookID       uint64         `json:"book_id"`
+	Title        string         `json:"title"`
+	Total        int            `json:"total"`
+	StatusCounts map[string]int `json:"status_counts"`
+	Copies       []BookCopyItem `json:"copies"`
+}
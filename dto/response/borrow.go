@@ -6,6 +6,8 @@ type BorrowBookResponse struct {
 	ID            uint64    `json:"id"`
 	BookID        uint64    `json:"book_id"`
 	BookTitle     string    `json:"book_title"`
+	CopyID        uint64    `json:"copy_id"`
+	Barcode       string    `json:"barcode"`
 	UserID        uint64    `json:"user_id"`
 	Username      string    `json:"username"`
 	BorrowDate    time.Time `json:"borrow_date"`
@@ -13,20 +15,26 @@ type BorrowBookResponse struct {
 	Status        string    `json:"status"`
 	RenewCount    int       `json:"renew_count"`
 	MaxRenewCount int       `json:"max_renew_count"`
+	CheckoutStaffID *uint64 `json:"checkout_staff_id,omitempty"`
 }
 
 type ReturnBookResponse struct {
-	ID          uint64    `json:"id"`
-	BookID      uint64    `json:"book_id"`
-	UserID      uint64    `json:"user_id"`
-	BorrowDate  time.Time `json:"borrow_date"`
-	DueDate     time.Time `json:"due_date"`
-	ReturnDate  time.Time `json:"return_date"`
-	Status      string    `json:"status"`
-	IsOverdue   bool      `json:"is_overdue"`
-	OverdueDays int       `json:"overdue_days"`
-	Fine        float64   `json:"fine"`
-	Condition   *string   `json:"condition"`
+	ID           uint64    `json:"id"`
+	BookID       uint64    `json:"book_id"`
+	CopyID       *uint64   `json:"copy_id"`
+	UserID       uint64    `json:"user_id"`
+	BorrowDate   time.Time `json:"borrow_date"`
+	DueDate      time.Time `json:"due_date"`
+	ReturnDate   time.Time `json:"return_date"`
+	Status       string    `json:"status"`
+	IsOverdue    bool      `json:"is_overdue"`
+	OverdueDays  int       `json:"overdue_days"`
+	OverdueFine  float64   `json:"overdue_fine"`
+	Compensation float64   `json:"compensation"`
+	Fine         float64   `json:"fine"`
+	Condition    string    `json:"condition"`
+	Remark       *string   `json:"remark,omitempty"`
+	CheckinStaffID *uint64 `json:"checkin_staff_id,omitempty"`
 }
 
 type RenewBorrowResponse struct {
@@ -53,19 +61,21 @@ type GetBorrowRecordListBookResponse struct {
 }
 
 type GetBorrowRecordItemResponse struct {
-	ID           uint64                          `json:"id"`
-	Book         GetBorrowRecordListBookResponse `json:"book"`
+	ID           uint64                           `json:"id"`
+	Book         GetBorrowRecordListBookResponse  `json:"book"`
 	User         *GetBorrowRecordListUserResponse `json:"user,omitempty"`
-	BorrowDate   time.Time                       `json:"borrow_date"`
-	DueDate      time.Time                       `json:"due_date"`
-	ReturnDate   *time.Time                      `json:"return_date"`
-	Status       string                          `json:"status"`
-	IsOverdue    bool                            `json:"is_overdue"`
-	DaysUntilDue int                             `json:"days_until_due"`
-	OverdueDays  int                             `json:"overdue_days,omitempty"`
-	RenewCount   int                             `json:"renew_count"`
-	CanRenew     bool                            `json:"can_renew"`
-	Fine         float64                         `json:"fine"`
+	BorrowDate   time.Time                        `json:"borrow_date"`
+	DueDate      time.Time                        `json:"due_date"`
+	ReturnDate   *time.Time                       `json:"return_date"`
+	Status       string                           `json:"status"`
+	IsOverdue    bool                             `json:"is_overdue"`
+	DaysUntilDue int                              `json:"days_until_due"`
+	OverdueDays  int                              `json:"overdue_days,omitempty"`
+	RenewCount   int                              `json:"renew_count"`
+	CanRenew     bool                             `json:"can_renew"`
+	Fine         float64                          `json:"fine"`
+	CheckoutStaffID *uint64                       `json:"checkout_staff_id,omitempty"`
+	CheckinStaffID  *uint64                       `json:"checkin_staff_id,omitempty"`
 }
 
 type GetBorrowRecordListResponse struct {
@@ -77,9 +87,10 @@ type GetBorrowRecordListResponse struct {
 }
 
 type GetCurrentRecordResponse struct {
-	BorrowingCount int                           `json:"borrowing_count"`
-	BorrowLimit    int                           `json:"borrow_limit"`
-	OverdueCount   int                           `json:"overdue_count"`
-	TotalFine      float64                       `json:"total_fine"`
-	Records        []GetBorrowRecordItemResponse `json:"records"`
+	BorrowingCount  int                           `json:"borrowing_count"`
+	BorrowLimit     int                           `json:"borrow_limit"`
+	OverdueCount    int                           `json:"overdue_count"`
+	TotalFine       float64                       `json:"total_fine"`       // 在借图书当前累计的逾期罚金
+	OutstandingFine float64                       `json:"outstanding_fine"` // 已结算但未缴纳的罚金
+	Records         []GetBorrowRecordItemResponse `json:"records"`
 }
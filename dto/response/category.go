@@ -15,6 +15,21 @@ type GetCategoryListResponse struct {
 	Categories []CategoryItem `json:"categories"`
 }
 
+// CategoryTreeNode 分类树节点，TotalBookCount 为本分类及全部子孙分类的图书总数
+type CategoryTreeNode struct {
+	ID             uint                `json:"id"`
+	Name           string              `json:"name"`
+	Description    string              `json:"description,omitempty"`
+	ParentID       *uint               `json:"parent_id"`
+	BookCount      int64               `json:"book_count"`
+	TotalBookCount int64               `json:"total_book_count"`
+	Children       []*CategoryTreeNode `json:"children"`
+}
+
+type GetCategoryTreeResponse struct {
+	Categories []*CategoryTreeNode `json:"categories"`
+}
+
 type CreateCategoryResponse struct {
 	ID          uint      `json:"id"`
 	Name        string    `json:"name"`
@@ -43,4 +58,15 @@ type UpdateCategoryResponse struct {
 	ID        uint      `json:"id"`
 	Name      *string   `json:"name,omitempty"`
 	UpdatedAt time.Time `json:"updated_at"`
-}
\ No newline at end of file
+}
+type TrashedCategoryItem struct {
+	ID          uint      `json:"id"`
+	Name        string    `json:"name"`
+	Description string    `json:"description,omitempty"`
+	ParentID    *uint     `json:"parent_id"`
+	DeletedAt   time.Time `json:"deleted_at"`
+}
+
+type GetCategoryTrashResponse struct {
+	Categories []TrashedCategoryItem `json:"categories"`
+}
@@ -0,0 +1,7 @@
+package response
+
+// StreamTicketResponse 建立事件流的一次性票据
+type StreamTicketResponse struct {
+	Ticket    string `json:"ticket"`
+	ExpiresIn int    `json:"expires_in"` // 秒
+}
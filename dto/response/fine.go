@@ -0,0 +1,30 @@
+package response
+
+import "time"
+
+type FineTransactionItem struct {
+	ID         uint64    `json:"id"`
+	BorrowID   *uint64   `json:"borrow_id"`
+	Type       string    `json:"type"`
+	Amount     float64   `json:"amount"`
+	Reason     string    `json:"reason,omitempty"`
+	OperatorID *uint64   `json:"operator_id,omitempty"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+type GetFineAccountResponse struct {
+	UserID         uint64                `json:"user_id"`
+	Balance        float64               `json:"balance"`
+	BlockThreshold float64               `json:"block_threshold"`
+	BorrowBlocked  bool                  `json:"borrow_blocked"`
+	Total          int64                 `json:"total"`
+	Page           int                   `json:"page"`
+	Limit          int                   `json:"limit"`
+	TotalPages     int                   `json:"total_pages"`
+	Transactions   []FineTransactionItem `json:"transactions"`
+}
+
+type FineTransactionResponse struct {
+	Transaction FineTransactionItem `json:"transaction"`
+	Balance     float64             `json:"balance"`
+}
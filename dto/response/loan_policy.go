@@ -0,0 +1,24 @@
+package response
+
+import "time"
+
+type LoanPolicyItem struct {
+	ID            uint64    `json:"id"`
+	Role          *string   `json:"role"`
+	CategoryID    *uint     `json:"category_id"`
+	LoanDays      int       `json:"loan_days"`
+	MaxRenewCount int       `json:"max_renew_count"`
+	RenewDays     int       `json:"renew_days"`
+	MaxLoans      int       `json:"max_loans"`
+	FineRate      float64   `json:"fine_rate"`
+	FineCap       float64   `json:"fine_cap"`
+	Description   string    `json:"description,omitempty"`
+	IsDefault     bool      `json:"is_default,omitempty"`
+	CreatedAt     time.Time `json:"created_at"`
+	UpdatedAt     time.Time `json:"updated_at"`
+}
+
+type GetLoanPolicyListResponse struct {
+	Policies []LoanPolicyItem `json:"policies"`
+	Default  LoanPolicyItem   `json:"default"`
+}
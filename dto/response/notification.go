@@ -0,0 +1,31 @@
+package response
+
+import "time"
+
+type NotificationItem struct {
+	ID        uint64                 `json:"id"`
+	Event     string                 `json:"event"`
+	Title     string                 `json:"title"`
+	Content   string                 `json:"content"`
+	Data      map[string]interface{} `json:"data,omitempty"`
+	IsRead    bool                   `json:"is_read"`
+	ReadAt    *time.Time             `json:"read_at"`
+	CreatedAt time.Time              `json:"created_at"`
+}
+
+type GetNotificationListResponse struct {
+	Total         int64              `json:"total"`
+	UnreadCount   int64              `json:"unread_count"`
+	Page          int                `json:"page"`
+	Limit         int                `json:"limit"`
+	TotalPages    int                `json:"total_pages"`
+	Notifications []NotificationItem `json:"notifications"`
+}
+
+type UnreadCountResponse struct {
+	UnreadCount int64 `json:"unread_count"`
+}
+
+type MarkAllReadResponse struct {
+	Updated int64 `json:"updated"`
+}
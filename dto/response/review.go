@@ -0,0 +1,32 @@
+package response
+
+import "time"
+
+type ReviewUser struct {
+	ID       uint64 `json:"id"`
+	Username string `json:"username"`
+}
+
+type ReviewItem struct {
+	ID           uint64     `json:"id"`
+	BookID       uint64     `json:"book_id"`
+	BookTitle    string     `json:"book_title,omitempty"`
+	User         ReviewUser `json:"user"`
+	Rating       int        `json:"rating"`
+	Content      string     `json:"content"`
+	Status       string     `json:"status"`
+	HiddenReason string     `json:"hidden_reason,omitempty"`
+	CreatedAt    time.Time  `json:"created_at"`
+	UpdatedAt    time.Time  `json:"updated_at"`
+}
+
+type GetReviewListResponse struct {
+	BookID      uint64       `json:"book_id,omitempty"`
+	Rating      float64      `json:"rating"`
+	ReviewCount int          `json:"review_count"`
+	Total       int64        `json:"total"`
+	Page        int          `json:"page"`
+	Limit       int          `json:"limit"`
+	TotalPages  int          `json:"total_pages"`
+	Reviews     []ReviewItem `json:"reviews"`
+}
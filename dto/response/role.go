@@ -0,0 +1,19 @@
+package response
+
+type RoleItem struct {
+	ID          uint64   `json:"id"`
+	Name        string   `json:"name"`
+	Description string   `json:"description"`
+	Builtin     bool     `json:"builtin"`
+	Permissions []string `json:"permissions"`
+	CreatedAt   string   `json:"created_at"`
+	UpdatedAt   string   `json:"updated_at"`
+}
+
+type GetRoleListResponse struct {
+	Roles []RoleItem `json:"roles"`
+}
+
+type GetPermissionListResponse struct {
+	Permissions []string `json:"permissions"`
+}
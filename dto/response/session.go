@@ -0,0 +1,14 @@
+package response
+
+type SessionItem struct {
+	ID         string `json:"id"`
+	UserAgent  string `json:"user_agent"`
+	IP         string `json:"ip"`
+	CreatedAt  string `json:"created_at"`
+	LastUsedAt string `json:"last_used_at"`
+	Current    bool   `json:"current"` // 是否为发起请求的会话
+}
+
+type GetSessionListResponse struct {
+	Sessions []SessionItem `json:"sessions"`
+}
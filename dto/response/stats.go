@@ -1,5 +1,7 @@
 package response
 
+import "time"
+
 // ========== 7.1 系统统计概览 ==========
 
 type StatsOverviewResponse struct {
@@ -87,4 +89,36 @@ type CategoryStatsItem struct {
 
 type GetCategoryStatsResponse struct {
 	Categories []CategoryStatsItem `json:"categories"`
-}
\ No newline at end of file
+}
+
+// ========== 7.6 损坏/遗失报表 ==========
+
+type DamagedItem struct {
+	BorrowID        uint64    `json:"borrow_id"`
+	BookID          uint64    `json:"book_id"`
+	Title           string    `json:"title"`
+	ISBN            string    `json:"isbn"`
+	CopyID          *uint64   `json:"copy_id"`
+	Barcode         string    `json:"barcode"`
+	UserID          uint64    `json:"user_id"`
+	Username        string    `json:"username"`
+	Condition       string    `json:"condition"`
+	Remark          string    `json:"remark"`
+	CompensationFee float64   `json:"compensation_fee"`
+	ReturnDate      time.Time `json:"return_date"`
+}
+
+type DamagedItemsSummary struct {
+	DamagedCount      int64   `json:"damaged_count"`
+	LostCount         int64   `json:"lost_count"`
+	TotalCompensation float64 `json:"total_compensation"`
+}
+
+type GetDamagedItemsResponse struct {
+	Total      int64               `json:"total"`
+	Page       int                 `json:"page"`
+	Limit      int                 `json:"limit"`
+	TotalPages int                 `json:"total_pages"`
+	Summary    DamagedItemsSummary `json:"summary"`
+	Items      []DamagedItem       `json:"items"`
+}
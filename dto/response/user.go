@@ -9,6 +9,7 @@ type UserRegisterResponse struct {
 	Username	 	string 			`json:"username"`
 	Email 			string 			`json:"email"`
 	Role 			string 			`json:"role"`
+	Status 			string 			`json:"status"`
 	CreatedAt 		time.Time 		`json:"created_at"`
 }
 
@@ -40,6 +41,7 @@ type GetUserMsgResponse struct {
 	Username 		string 		`json:"username"`
 	Email 			string 		`json:"email"`
 	Phone 			string 		`json:"phone"`
+	CardNumber		*string		`json:"card_number"`
 	Role 			string 		`json:"role"`
 	Status			string 		`json:"status"`
 	BorrowLimit 	int	   		`json:"borrow_limit"`
@@ -53,6 +55,7 @@ type UpdateUserResponse struct {
 	Username 		string 			`json:"username,omitempty"`
 	Email 			string 			`json:"email,omitempty"`
 	Phone 			string 			`json:"phone,omitempty"`
+	PendingEmail 	string 			`json:"pending_email,omitempty"` // 新邮箱验证后才生效
 	UpdatedAt   	string			`json:"updated_at"`
 }
 
@@ -67,6 +70,7 @@ type CreateUserResponse struct {
 	ID          uint64 `json:"id"`
 	Username    string `json:"username"`
 	Email       string `json:"email"`
+	CardNumber  *string `json:"card_number"`
 	Role        string `json:"role"`
 	Status      string `json:"status"`
 	BorrowLimit int    `json:"borrow_limit"`
@@ -78,8 +82,26 @@ type UpdateUserByAdminResponse struct {
 	Username    string `json:"username"`
 	Phone 		string 	`json:"phone"`
 	Email       string `json:"email"`
+	CardNumber  *string `json:"card_number"`
 	Role        string `json:"role"`
 	Status      string `json:"status"`
 	BorrowLimit int    `json:"borrow_limit"`
 	UpdatedAt   string `json:"updated_at"`
-}
\ No newline at end of file
+}
+type TrashedUserItem struct {
+	ID        uint64 `json:"id"`
+	Username  string `json:"username"`
+	Email     string `json:"email"`
+	Role      string `json:"role"`
+	Status    string `json:"status"`
+	CreatedAt string `json:"created_at"`
+	DeletedAt string `json:"deleted_at"`
+}
+
+type GetUserTrashResponse struct {
+	Total      int               `json:"total"`
+	Page       int               `json:"page"`
+	Limit      int               `json:"limit"`
+	TotalPages int               `json:"total_pages"`
+	Users      []TrashedUserItem `json:"users"`
+}
@@ -0,0 +1,66 @@
+package exporter
+
+import (
+	"encoding/csv"
+	"strconv"
+	"time"
+)
+
+var csvHeader = []string{
+	"id", "title", "author", "isbn", "category_id", "category_name", "publisher", "publish_date",
+	"price", "description", "cover_url", "stock", "borrow_count", "copy_count", "available_copies",
+	"rating", "review_count", "created_at", "updated_at",
+}
+
+type csvEncoder struct {
+	bw *bufferedWriter
+	w  *csv.Writer
+}
+
+func newCSVEncoder(bw *bufferedWriter) *csvEncoder {
+	return &csvEncoder{bw: bw, w: csv.NewWriter(bw)}
+}
+
+func (e *csvEncoder) Begin() error {
+	// BOM 便于 Excel 直接打开中文内容
+	if _, err := e.bw.Write([]byte{0xEF, 0xBB, 0xBF}); err != nil {
+		return err
+	}
+	return e.w.Write(csvHeader)
+}
+
+func (e *csvEncoder) Encode(rec *Record) error {
+	return e.w.Write([]string{
+		strconv.FormatUint(rec.ID, 10),
+		rec.Title,
+		rec.Author,
+		rec.ISBN,
+		strconv.FormatUint(uint64(rec.CategoryID), 10),
+		rec.CategoryName,
+		rec.Publisher,
+		formatDate(rec.PublishDate),
+		strconv.FormatFloat(rec.Price, 'f', 2, 64),
+		rec.Description,
+		rec.CoverURL,
+		strconv.Itoa(rec.Stock),
+		strconv.Itoa(rec.BorrowCount),
+		strconv.FormatInt(rec.CopyCount, 10),
+		strconv.FormatInt(rec.AvailableCopies, 10),
+		strconv.FormatFloat(rec.Rating, 'f', 2, 64),
+		strconv.Itoa(rec.ReviewCount),
+		rec.CreatedAt.Format(time.RFC3339),
+		rec.UpdatedAt.Format(time.RFC3339),
+	})
+}
+
+func (e *csvEncoder) End() error {
+	return e.Flush()
+}
+
+func (e *csvEncoder) Flush() error {
+	e.w.Flush()
+	if err := e.w.Error(); err != nil {
+		return err
+	}
+	return e.bw.Flush()
+}
@@ -0,0 +1,105 @@
+package exporter
+
+import (
+	"bufio"
+	"errors"
+	"io"
+	"time"
+)
+
+const (
+	FormatCSV     = "csv"
+	FormatNDJSON  = "ndjson"
+	FormatMARC    = "marc"    // MARC21 ISO 2709
+	FormatMARCXML = "marcxml" // MARC21 XML（MARCXML slim）
+)
+
+var ErrUnsupportedFormat = errors.New("unsupported export format")
+
+// Record 导出的一条图书记录
+type Record struct {
+	ID              uint64     `json:"id"`
+	Title           string     `json:"title"`
+	Author          string     `json:"author"`
+	ISBN            string     `json:"isbn"`
+	CategoryID      uint       `json:"category_id"`
+	CategoryName    string     `json:"category_name"`
+	Publisher       string     `json:"publisher"`
+	PublishDate     *time.Time `json:"publish_date"`
+	Price           float64    `json:"price"`
+	Description     string     `json:"description"`
+	CoverURL        string     `json:"cover_url"`
+	Stock           int        `json:"stock"`
+	BorrowCount     int        `json:"borrow_count"`
+	CopyCount       int64      `json:"copy_count"`       // 全部副本数（含维修、遗失等）
+	AvailableCopies int64      `json:"available_copies"` // 在架可借副本数
+	Rating          float64    `json:"rating"`
+	ReviewCount     int        `json:"review_count"`
+	CreatedAt       time.Time  `json:"created_at"`
+	UpdatedAt       time.Time  `json:"updated_at"`
+}
+
+// Encoder 将记录逐条编码写出，调用方按批次 Flush 以便边查边发
+type Encoder interface {
+	Begin() error
+	Encode(rec *Record) error
+	End() error
+	Flush() error
+}
+
+// Meta 各格式的响应类型与文件扩展名
+type Meta struct {
+	ContentType string
+	Ext         string
+}
+
+var formats = map[string]Meta{
+	FormatCSV:     {ContentType: "text/csv; charset=utf-8", Ext: "csv"},
+	FormatNDJSON:  {ContentType: "application/x-ndjson", Ext: "ndjson"},
+	FormatMARC:    {ContentType: "application/marc", Ext: "mrc"},
+	FormatMARCXML: {ContentType: "application/marcxml+xml", Ext: "xml"},
+}
+
+// Lookup 返回格式对应的元信息
+func Lookup(format string) (Meta, bool) {
+	meta, ok := formats[format]
+	return meta, ok
+}
+
+func NewEncoder(format string, w io.Writer) (Encoder, error) {
+	bw := &bufferedWriter{Writer: bufio.NewWriterSize(w, 32<<10), dst: w}
+	switch format {
+	case FormatCSV:
+		return newCSVEncoder(bw), nil
+	case FormatNDJSON:
+		return newNDJSONEncoder(bw), nil
+	case FormatMARC:
+		return &marcEncoder{w: bw}, nil
+	case FormatMARCXML:
+		return &marcXMLEncoder{w: bw}, nil
+	}
+	return nil, ErrUnsupportedFormat
+}
+
+// bufferedWriter 刷新缓冲后，若底层支持（如 HTTP 响应）再把数据推送给客户端
+type bufferedWriter struct {
+	*bufio.Writer
+	dst io.Writer
+}
+
+func (w *bufferedWriter) Flush() error {
+	if err := w.Writer.Flush(); err != nil {
+		return err
+	}
+	if f, ok := w.dst.(interface{ Flush() }); ok {
+		f.Flush()
+	}
+	return nil
+}
+
+func formatDate(t *time.Time) string {
+	if t == nil {
+		return ""
+	}
+	return t.Format("2006-01-02")
+}
@@ -0,0 +1,237 @@
+package exporter
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"strconv"
+	"unicode/utf8"
+)
+
+const (
+	marcSubfieldDelimiter = 0x1F
+	marcFieldTerminator   = 0x1E
+	marcRecordTerminator  = 0x1D
+
+	marcMaxFieldLength  = 9999  // 目次区中字段长度只有 4 位
+	marcMaxRecordLength = 99999 // 头标区中记录长度只有 5 位
+)
+
+type marcControlField struct {
+	Tag   string
+	Value string
+}
+
+type marcSubfield struct {
+	Code  byte
+	Value string
+}
+
+type marcDataField struct {
+	Tag       string
+	Ind1      byte
+	Ind2      byte
+	Subfields []marcSubfield
+}
+
+type marcRecord struct {
+	Controls []marcControlField
+	Fields   []marcDataField
+}
+
+// toMARC 按 MARC21 书目格式映射图书字段
+func toMARC(rec *Record) *marcRecord {
+	m := &marcRecord{}
+
+	m.Controls = append(m.Controls,
+		marcControlField{Tag: "001", Value: strconv.FormatUint(rec.ID, 10)},
+		marcControlField{Tag: "005", Value: rec.UpdatedAt.Format("20060102150405") + ".0"},
+		marcControlField{Tag: "008", Value: marc008(rec)},
+	)
+
+	isbn := []marcSubfield{{'a', rec.ISBN}}
+	if rec.Price > 0 {
+		isbn = append(isbn, marcSubfield{'c', fmt.Sprintf("CNY%.2f", rec.Price)})
+	}
+	m.addField("020", ' ', ' ', isbn...)
+	m.addField("100", '1', ' ', marcSubfield{'a', rec.Author})
+	m.addField("245", '1', '0', marcSubfield{'a', rec.Title})
+
+	publication := []marcSubfield{{'b', rec.Publisher}}
+	if rec.PublishDate != nil {
+		publication = append(publication, marcSubfield{'c', strconv.Itoa(rec.PublishDate.Year())})
+	}
+	m.addField("260", ' ', ' ', publication...)
+
+	m.addField("520", ' ', ' ', marcSubfield{'a', truncateBytes(rec.Description, 8000)})
+	m.addField("653", ' ', ' ', marcSubfield{'a', rec.CategoryName})
+	// 馆藏信息：$x 为内部附注，记录副本数量
+	m.addField("852", ' ', ' ',
+		marcSubfield{'x', fmt.Sprintf("copies=%d available=%d", rec.CopyCount, rec.AvailableCopies)})
+	m.addField("856", '4', '2', marcSubfield{'3', "Cover image"}, marcSubfield{'u', rec.CoverURL})
+
+	return m
+}
+
+// addField 忽略值为空的子字段，全部为空时不生成该字段
+func (m *marcRecord) addField(tag string, ind1, ind2 byte, subfields ...marcSubfield) {
+	var kept []marcSubfield
+	for _, sf := range subfields {
+		if sf.Value != "" {
+			kept = append(kept, sf)
+		}
+	}
+	// 856 仅有 $3 说明而没有链接时没有意义
+	if len(kept) == 0 || (tag == "856" && kept[len(kept)-1].Code != 'u') {
+		return
+	}
+	m.Fields = append(m.Fields, marcDataField{Tag: tag, Ind1: ind1, Ind2: ind2, Subfields: kept})
+}
+
+// marc008 定长数据元素，共 40 个字符
+func marc008(rec *Record) string {
+	year := "uuuu"
+	if rec.PublishDate != nil {
+		year = fmt.Sprintf("%04d", rec.PublishDate.Year())
+	}
+	return rec.CreatedAt.Format("060102") + // 00-05 入档日期
+		"s" + year + "    " + // 06-14 日期类型与出版年
+		"xx " + // 15-17 出版地未知
+		"                 " + // 18-34 图书专用位，未编码
+		"und" + // 35-37 语种未定
+		" d" // 38-39 非修改记录，其他编目来源
+}
+
+// leader 头标区，recordLength 与 baseAddress 由 ISO 2709 编码时填入
+func marcLeader(recordLength, baseAddress int) string {
+	return fmt.Sprintf("%05dnam a22%05d7i 4500", recordLength, baseAddress)
+}
+
+// encodeISO2709 生成二进制 MARC 记录
+func (m *marcRecord) encodeISO2709() ([]byte, error) {
+	var directory, data bytes.Buffer
+
+	addEntry := func(tag string, content []byte) error {
+		if len(content) > marcMaxFieldLength {
+			return fmt.Errorf("marc field %s too long: %d bytes", tag, len(content))
+		}
+		fmt.Fprintf(&directory, "%s%04d%05d", tag, len(content), data.Len())
+		data.Write(content)
+		return nil
+	}
+
+	for _, cf := range m.Controls {
+		if err := addEntry(cf.Tag, append([]byte(cf.Value), marcFieldTerminator)); err != nil {
+			return nil, err
+		}
+	}
+	for _, df := range m.Fields {
+		var field bytes.Buffer
+		field.WriteByte(df.Ind1)
+		field.WriteByte(df.Ind2)
+		for _, sf := range df.Subfields {
+			field.WriteByte(marcSubfieldDelimiter)
+			field.WriteByte(sf.Code)
+			field.WriteString(sf.Value)
+		}
+		field.WriteByte(marcFieldTerminator)
+		if err := addEntry(df.Tag, field.Bytes()); err != nil {
+			return nil, err
+		}
+	}
+	directory.WriteByte(marcFieldTerminator)
+
+	baseAddress := 24 + directory.Len()
+	recordLength := baseAddress + data.Len() + 1
+	if recordLength > marcMaxRecordLength {
+		return nil, fmt.Errorf("marc record too long: %d bytes", recordLength)
+	}
+
+	out := make([]byte, 0, recordLength)
+	out = append(out, marcLeader(recordLength, baseAddress)...)
+	out = append(out, directory.Bytes()...)
+	out = append(out, data.Bytes()...)
+	out = append(out, marcRecordTerminator)
+	return out, nil
+}
+
+// truncateBytes 按 UTF-8 字节数截断，不截断半个字符
+func truncateBytes(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	for n > 0 && !utf8.RuneStart(s[n]) {
+		n--
+	}
+	return s[:n]
+}
+
+type marcEncoder struct {
+	w *bufferedWriter
+}
+
+func (e *marcEncoder) Begin() error {
+	return nil
+}
+
+func (e *marcEncoder) Encode(rec *Record) error {
+	data, err := toMARC(rec).encodeISO2709()
+	if err != nil {
+		return fmt.Errorf("book %d: %w", rec.ID, err)
+	}
+	_, err = e.w.Write(data)
+	return err
+}
+
+func (e *marcEncoder) End() error {
+	return e.Flush()
+}
+
+func (e *marcEncoder) Flush() error {
+	return e.w.Flush()
+}
+
+type marcXMLEncoder struct {
+	w *bufferedWriter
+}
+
+func (e *marcXMLEncoder) Begin() error {
+	_, err := e.w.WriteString(xml.Header + `<collection xmlns="http://www.loc.gov/MARC21/slim">` + "\n")
+	return err
+}
+
+func (e *marcXMLEncoder) Encode(rec *Record) error {
+	m := toMARC(rec)
+	w := e.w
+
+	w.WriteString("<record>")
+	// MARCXML 中记录长度与基地址不具意义，按惯例以 0 占位
+	w.WriteString("<leader>" + marcLeader(0, 0) + "</leader>")
+	for _, cf := range m.Controls {
+		w.WriteString(`<controlfield tag="` + cf.Tag + `">`)
+		xml.EscapeText(w, []byte(cf.Value))
+		w.WriteString("</controlfield>")
+	}
+	for _, df := range m.Fields {
+		fmt.Fprintf(w, `<datafield tag="%s" ind1="%c" ind2="%c">`, df.Tag, df.Ind1, df.Ind2)
+		for _, sf := range df.Subfields {
+			fmt.Fprintf(w, `<subfield code="%c">`, sf.Code)
+			xml.EscapeText(w, []byte(sf.Value))
+			w.WriteString("</subfield>")
+		}
+		w.WriteString("</datafield>")
+	}
+	_, err := w.WriteString("</record>\n")
+	return err
+}
+
+func (e *marcXMLEncoder) End() error {
+	if _, err := e.w.WriteString("</collection>\n"); err != nil {
+		return err
+	}
+	return e.Flush()
+}
+
+func (e *marcXMLEncoder) Flush() error {
+	return e.w.Flush()
+}
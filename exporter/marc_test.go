@@ -0,0 +1,281 @@
+package exporter
+
+import (
+	"bytes"
+	"encoding/xml"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func sampleRecord() *Record {
+	published := time.Date(2008, 1, 1, 0, 0, 0, 0, time.UTC)
+	return &Record{
+		ID:              42,
+		Title:           "三体",
+		Author:          "刘慈欣",
+		ISBN:            "9787536692930",
+		CategoryName:    "科幻",
+		Publisher:       "重庆出版社",
+		PublishDate:     &published,
+		Price:           23,
+		Description:     "地球文明 & 三体文明",
+		CoverURL:        "https://example.com/covers/42.jpg",
+		CopyCount:       3,
+		AvailableCopies: 1,
+		CreatedAt:       time.Date(2026, 3, 5, 8, 0, 0, 0, time.UTC),
+		UpdatedAt:       time.Date(2026, 4, 6, 9, 30, 15, 0, time.UTC),
+	}
+}
+
+// parseISO2709 按头标区与目次区拆出各字段内容（不含字段结束符），同时校验记录结构
+func parseISO2709(t *testing.T, data []byte) (string, map[string]string, []string) {
+	t.Helper()
+	if len(data) < 25 || data[len(data)-1] != marcRecordTerminator {
+		t.Fatalf("record missing terminator: %q", data)
+	}
+	leader := string(data[:24])
+	recordLength, err := strconv.Atoi(leader[:5])
+	if err != nil || recordLength != len(data) {
+		t.Fatalf("leader record length %q, want %d", leader[:5], len(data))
+	}
+	baseAddress, err := strconv.Atoi(leader[12:17])
+	if err != nil || data[baseAddress-1] != marcFieldTerminator {
+		t.Fatalf("leader base address %q does not end the directory", leader[12:17])
+	}
+
+	directory := data[24 : baseAddress-1]
+	if len(directory)%12 != 0 {
+		t.Fatalf("directory length %d is not a multiple of 12", len(directory))
+	}
+	fields := map[string]string{}
+	var tags []string
+	next := 0
+	for i := 0; i < len(directory); i += 12 {
+		entry := string(directory[i : i+12])
+		tag := entry[:3]
+		length, _ := strconv.Atoi(entry[3:7])
+		start, _ := strconv.Atoi(entry[7:12])
+		if start != next {
+			t.Fatalf("field %s starts at %d, want %d", tag, start, next)
+		}
+		next = start + length
+		content := data[baseAddress+start : baseAddress+next]
+		if content[len(content)-1] != marcFieldTerminator {
+			t.Fatalf("field %s missing terminator", tag)
+		}
+		fields[tag] = string(content[:len(content)-1])
+		tags = append(tags, tag)
+	}
+	if baseAddress+next != len(data)-1 {
+		t.Fatalf("fields end at %d, want %d", baseAddress+next, len(data)-1)
+	}
+	return leader, fields, tags
+}
+
+// subfields 按 MARC 子字段分隔符拼出便于比较的形式，如 "10$a三体"
+func subfields(field string) string {
+	return strings.ReplaceAll(field, string(rune(marcSubfieldDelimiter)), "$")
+}
+
+func TestEncodeISO2709(t *testing.T) {
+	data, err := toMARC(sampleRecord()).encodeISO2709()
+	if err != nil {
+		t.Fatal(err)
+	}
+	leader, fields, tags := parseISO2709(t, data)
+
+	if got := leader[5:12] + leader[17:]; got != "nam a227i 4500" {
+		t.Errorf("leader = %q", leader)
+	}
+	wantTags := "001,005,008,020,100,245,260,520,653,852,856"
+	if got := strings.Join(tags, ","); got != wantTags {
+		t.Fatalf("tags = %s, want %s", got, wantTags)
+	}
+
+	want := map[string]string{
+		"001": "42",
+		"005": "20260406093015.0",
+		"008": "260305s2008    xx                  und d",
+		"020": "  $a9787536692930$cCNY23.00",
+		"100": "1 $a刘慈欣",
+		"245": "10$a三体", // 目次区的长度按 UTF-8 字节数计算
+		"260": "  $b重庆出版社$c2008",
+		"520": "  $a地球文明 & 三体文明",
+		"653": "  $a科幻",
+		"852": "  $xcopies=3 available=1",
+		"856": "42$3Cover image$uhttps://example.com/covers/42.jpg",
+	}
+	for tag, value := range want {
+		if got := subfields(fields[tag]); got != value {
+			t.Errorf("field %s = %q, want %q", tag, got, value)
+		}
+	}
+	if len(fields["008"]) != 40 {
+		t.Errorf("008 has %d characters, want 40", len(fields["008"]))
+	}
+}
+
+func TestEncodeISO2709OmitsEmptyFields(t *testing.T) {
+	rec := &Record{ID: 1, Title: "Untitled", CreatedAt: time.Now(), UpdatedAt: time.Now()}
+	data, err := toMARC(rec).encodeISO2709()
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, fields, tags := parseISO2709(t, data)
+
+	// 没有作者、出版社、ISBN 与封面时不生成对应字段；856 只有 $3 说明时同样省略
+	if got := strings.Join(tags, ","); got != "001,005,008,245,852" {
+		t.Fatalf("tags = %s", got)
+	}
+	if year := fields["008"][7:11]; year != "uuuu" {
+		t.Errorf("008 publish year = %q, want uuuu", year)
+	}
+}
+
+func TestEncodeISO2709Limits(t *testing.T) {
+	rec := sampleRecord()
+	rec.Title = strings.Repeat("a", marcMaxFieldLength)
+	if _, err := toMARC(rec).encodeISO2709(); err == nil || !strings.Contains(err.Error(), "245") {
+		t.Fatalf("err = %v, want field 245 too long", err)
+	}
+
+	m := &marcRecord{}
+	for i := 0; i < 12; i++ {
+		m.addField("500", ' ', ' ', marcSubfield{'a', strings.Repeat("a", 9000)})
+	}
+	if _, err := m.encodeISO2709(); err == nil || !strings.Contains(err.Error(), "record too long") {
+		t.Fatalf("err = %v, want record too long", err)
+	}
+
+	// 描述截断到 8000 字节，不截断半个汉字
+	rec = sampleRecord()
+	rec.Description = strings.Repeat("文", 3000)
+	data, err := toMARC(rec).encodeISO2709()
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, fields, _ := parseISO2709(t, data)
+	if got := fields["520"]; len(got) != 4+7998 || !strings.HasSuffix(got, "文") {
+		t.Fatalf("520 has %d bytes, want 4+7998", len(got))
+	}
+}
+
+func TestTruncateBytes(t *testing.T) {
+	tests := []struct {
+		in   string
+		n    int
+		want string
+	}{
+		{"abc", 5, "abc"},
+		{"abc", 2, "ab"},
+		{"三体", 6, "三体"},
+		{"三体", 5, "三"},
+		{"三体", 3, "三"},
+		{"三体", 2, ""},
+	}
+	for _, tt := range tests {
+		if got := truncateBytes(tt.in, tt.n); got != tt.want {
+			t.Errorf("truncateBytes(%q, %d) = %q, want %q", tt.in, tt.n, got, tt.want)
+		}
+	}
+}
+
+func TestMARCEncoderWritesRecords(t *testing.T) {
+	var buf bytes.Buffer
+	enc, err := NewEncoder(FormatMARC, &buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	second := sampleRecord()
+	second.ID = 43
+	for _, rec := range []*Record{sampleRecord(), second} {
+		if err := enc.Encode(rec); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := enc.End(); err != nil {
+		t.Fatal(err)
+	}
+
+	records := bytes.SplitAfter(buf.Bytes(), []byte{marcRecordTerminator})
+	if len(records) != 3 || len(records[2]) != 0 {
+		t.Fatalf("got %d records, want 2", len(records)-1)
+	}
+	for i, want := range []string{"42", "43"} {
+		if _, fields, _ := parseISO2709(t, records[i]); fields["001"] != want {
+			t.Errorf("record %d 001 = %q, want %s", i, fields["001"], want)
+		}
+	}
+
+	rec := sampleRecord()
+	rec.Title = strings.Repeat("a", marcMaxFieldLength)
+	if err := enc.Encode(rec); err == nil || !strings.Contains(err.Error(), "book 42") {
+		t.Fatalf("err = %v, want error naming the book", err)
+	}
+}
+
+func TestMARCXMLEncoder(t *testing.T) {
+	var buf bytes.Buffer
+	enc, err := NewEncoder(FormatMARCXML, &buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := enc.Begin(); err != nil {
+		t.Fatal(err)
+	}
+	if err := enc.Encode(sampleRecord()); err != nil {
+		t.Fatal(err)
+	}
+	if err := enc.End(); err != nil {
+		t.Fatal(err)
+	}
+
+	var collection struct {
+		XMLName xml.Name `xml:"http://www.loc.gov/MARC21/slim collection"`
+		Records []struct {
+			Leader   string `xml:"leader"`
+			Controls []struct {
+				Tag   string `xml:"tag,attr"`
+				Value string `xml:",chardata"`
+			} `xml:"controlfield"`
+			Fields []struct {
+				Tag       string `xml:"tag,attr"`
+				Ind1      string `xml:"ind1,attr"`
+				Ind2      string `xml:"ind2,attr"`
+				Subfields []struct {
+					Code  string `xml:"code,attr"`
+					Value string `xml:",chardata"`
+				} `xml:"subfield"`
+			} `xml:"datafield"`
+		} `xml:"record"`
+	}
+	if err := xml.Unmarshal(buf.Bytes(), &collection); err != nil {
+		t.Fatalf("invalid MARCXML: %v\n%s", err, buf.String())
+	}
+	if len(collection.Records) != 1 {
+		t.Fatalf("got %d records, want 1", len(collection.Records))
+	}
+
+	record := collection.Records[0]
+	if record.Leader != "00000nam a22000007i 4500" {
+		t.Errorf("leader = %q", record.Leader)
+	}
+	if len(record.Controls) != 3 || record.Controls[0].Tag != "001" || record.Controls[0].Value != "42" {
+		t.Errorf("control fields = %+v", record.Controls)
+	}
+	var found bool
+	for _, field := range record.Fields {
+		if field.Tag == "520" {
+			found = true
+			// 特殊字符经转义后原样还原
+			if field.Ind1 != " " || field.Subfields[0].Code != "a" || field.Subfields[0].Value != "地球文明 & 三体文明" {
+				t.Errorf("520 = %+v", field)
+			}
+		}
+	}
+	if !found {
+		t.Error("520 missing")
+	}
+}
@@ -0,0 +1,31 @@
+package exporter
+
+import "encoding/json"
+
+// ndjsonEncoder 每行一个 JSON 对象，便于逐行流式处理
+type ndjsonEncoder struct {
+	bw  *bufferedWriter
+	enc *json.Encoder
+}
+
+func newNDJSONEncoder(bw *bufferedWriter) *ndjsonEncoder {
+	enc := json.NewEncoder(bw)
+	enc.SetEscapeHTML(false)
+	return &ndjsonEncoder{bw: bw, enc: enc}
+}
+
+func (e *ndjsonEncoder) Begin() error {
+	return nil
+}
+
+func (e *ndjsonEncoder) Encode(rec *Record) error {
+	return e.enc.Encode(rec)
+}
+
+func (e *ndjsonEncoder) End() error {
+	return e.Flush()
+}
+
+func (e *ndjsonEncoder) Flush() error {
+	return e.bw.Flush()
+}
@@ -3,27 +3,50 @@ import request from '../utils/request';
 // --- 用户模块 ---
 export const login = (data) => request.post('/api/users/login', data);
 export const register = (data) => request.post('/api/users/register', data);
+export const forgotPassword = (data) => request.post('/api/users/forgot-password', data);
+export const resetPassword = (data) => request.post('/api/users/reset-password', data);
+export const verifyEmail = (data) => request.post('/api/users/verify-email', data);
+export const resendVerification = (data) => request.post('/api/users/resend-verification', data);
 export const logout = () => request.post('/api/users/logout');
 export const getUserInfo = () => request.get('/api/users/me');
 export const updateUserInfo = (data) => request.put('/api/users/me', data);
 export const changePassword = (data) => request.post('/api/users/change-password', data);
+export const getMySessions = () => request.get('/api/users/me/sessions');
+export const revokeSession = (id) => request.delete(`/api/users/me/sessions/${id}`);
+export const revokeAllSessions = () => request.delete('/api/users/me/sessions');
 
 // 管理员用户接口
 export const getUserList = (params) => request.get('/api/users', { params });
 export const createUser = (data) => request.post('/api/users', data);
 export const updateUser = (id, data) => request.put(`/api/users/${id}`, data);
 export const deleteUser = (id) => request.delete(`/api/users/${id}`);
+export const getUserTrash = (params) => request.get('/api/users/trash', { params });
+export const restoreUser = (id) => request.post(`/api/users/${id}/restore`);
+export const getUserSessions = (id) => request.get(`/api/users/${id}/sessions`);
+export const forceLogoutUser = (id) => request.post(`/api/users/${id}/logout`);
 
 // --- 图书模块 ---
 export const getBooks = (params) => request.get('/api/books', { params });
+export const searchBooks = (params) => request.get('/api/books/search', { params });
 export const getBookDetail = (id) => request.get(`/api/books/${id}`);
 export const addBook = (data) => request.post('/api/books', data);
+export const lookupBook = (isbn) => request.post('/api/books/lookup', { isbn });
+export const importBooks = (formData) => request.post('/api/books/import', formData, { headers: { 'Content-Type': 'multipart/form-data' } });
 export const batchImportBooks = (data) => request.post('/api/books/batch', data);
 export const updateBook = (id, data) => request.put(`/api/books/${id}`, data);
 export const deleteBook = (id) => request.delete(`/api/books/${id}`);
+export const getBookTrash = (params) => request.get('/api/books/trash', { params });
+export const restoreBook = (id) => request.post(`/api/books/${id}/restore`);
+export const uploadBookCover = (id, formData) => request.post(`/api/books/${id}/cover`, formData, { headers: { 'Content-Type': 'multipart/form-data' } });
 // 热门图书已移至统计模块
 export const getPopularBooks = (params) => request.get('/api/stats/popular-books', { params });
 
+// --- 图书评价 ---
+export const getBookReviews = (bookId, params) => request.get(`/api/books/${bookId}/reviews`, { params });
+export const createBookReview = (bookId, data) => request.post(`/api/books/${bookId}/reviews`, data);
+export const updateBookReview = (bookId, reviewId, data) => request.put(`/api/books/${bookId}/reviews/${reviewId}`, data);
+export const deleteBookReview = (bookId, reviewId) => request.delete(`/api/books/${bookId}/reviews/${reviewId}`);
+
 // --- 借阅模块 ---
 export const borrowBook = (data) => request.post('/api/borrow', data);
 export const returnBook = (id, data) => request.post(`/api/borrow/${id}/return`, data);
@@ -38,14 +61,40 @@ export const getMyReservations = () => request.get('/api/reservations/my');
 
 // --- 分类模块 ---
 export const getCategories = (params) => request.get('/api/categories', { params });
+export const getCategoryTree = () => request.get('/api/categories/tree');
 export const getCategoryDetail = (id) => request.get(`/api/categories/${id}`);
 export const addCategory = (data) => request.post('/api/categories', data);
 export const updateCategory = (id, data) => request.put(`/api/categories/${id}`, data);
 export const deleteCategory = (id) => request.delete(`/api/categories/${id}`);
+export const getCategoryTrash = () => request.get('/api/categories/trash');
+export const restoreCategory = (id) => request.post(`/api/categories/${id}/restore`);
 
 // --- 统计模块 ---
 export const getStatsOverview = () => request.get('/api/stats/overview');
 export const getBorrowStats = (params) => request.get('/api/stats/borrow', { params });
 export const getUserStats = (userId) => request.get(`/api/stats/user/${userId}`);
 export const getPopularBooksStats = (params) => request.get('/api/stats/popular-books', { params });
-export const getCategoryStats = () => request.get('/api/stats/categories');
\ No newline at end of file
+export const getCategoryStats = () => request.get('/api/stats/categories');
+// --- 站内消息 ---
+export const getNotifications = (params) => request.get('/api/notifications', { params });
+export const getUnreadNotificationCount = () => request.get('/api/notifications/unread-count');
+export const markNotificationRead = (id) => request.put(`/api/notifications/${id}/read`);
+export const markAllNotificationsRead = () => request.put('/api/notifications/read-all');
+
+// --- 实时事件 ---
+// EventSource 无法携带请求头，先换取一次性票据再连接：new EventSource(`/api/events/stream?ticket=${ticket}`)
+export const getEventTicket = () => request.post('/api/events/ticket');
+
+// --- 审计日志 ---
+export const getAuditEvents = (params) => request.get('/api/admin/audit', { params });
+
+// --- 角色与权限 ---
+export const getPermissions = () => request.get('/api/admin/permissions');
+export const getRoles = () => request.get('/api/admin/roles');
+export const createRole = (data) => request.post('/api/admin/roles', data);
+export const updateRole = (id, data) => request.put(`/api/admin/roles/${id}`, data);
+export const deleteRole = (id) => request.delete(`/api/admin/roles/${id}`);
+
+// --- 流通台 ---
+export const checkoutForPatron = (data) => request.post('/api/circulation/checkout', data);
+export const checkinBook = (data) => request.post('/api/circulation/checkin', data);
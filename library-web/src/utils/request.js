@@ -10,6 +10,29 @@ const service = axios.create({
   }
 });
 
+// 正在进行的 Token 刷新：同一 Refresh Token 只能使用一次，重复使用会被后端视为盗用并注销会话，
+// 因此并发的 401 请求共用同一次刷新
+let refreshPromise = null;
+
+const refreshAccessToken = (refreshToken) => {
+  if (!refreshPromise) {
+    // 注意：这里必须用 axios 原生实例，不能用 service，否则死循环
+    refreshPromise = axios.post('/api/users/refresh-token', {
+      refresh_token: refreshToken
+    }).then(({ data }) => {
+      if (data && data.code === 200) {
+        setToken(data.data.access_token);
+        setRefreshToken(data.data.refresh_token);
+        return data.data.access_token;
+      }
+      throw new Error(data?.message || 'Token 刷新失败');
+    }).finally(() => {
+      refreshPromise = null;
+    });
+  }
+  return refreshPromise;
+};
+
 // 请求拦截器
 service.interceptors.request.use(
   config => {
@@ -70,17 +93,9 @@ service.interceptors.response.use(
         
         if (refreshToken) {
           try {
-            // 注意：这里必须用 axios 原生实例，不能用 service，否则死循环
-            const { data } = await axios.post('/api/users/refresh-token', {
-              refresh_token: refreshToken
-            });
-
-            if (data && data.code === 200) {
-              setToken(data.data.access_token);
-              setRefreshToken(data.data.refresh_token);
-              originalRequest.headers['Authorization'] = `Bearer ${data.data.access_token}`;
-              return axios(originalRequest);
-            }
+            const accessToken = await refreshAccessToken(refreshToken);
+            originalRequest.headers['Authorization'] = `Bearer ${accessToken}`;
+            return axios(originalRequest);
           } catch (refreshError) {
             console.error('Token 刷新失败', refreshError);
           }
@@ -32,6 +32,10 @@ func main() {
 	log.Println("服务器正在关闭...")
 	app.Scheduler.OverdueScheduler.Stop()
 	app.Scheduler.ReservationScheduler.Stop()
+	app.Scheduler.NotificationScheduler.Stop()
+	app.Scheduler.DueReminderScheduler.Stop()
+	app.Scheduler.KeyRotationScheduler.Stop()
+	app.Scheduler.UnverifiedUserScheduler.Stop()
 	database.CloseRedis()
 	log.Println("服务器已关闭")
 
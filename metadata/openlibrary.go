@@ -0,0 +1,167 @@
+package metadata
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const DefaultOpenLibraryURL = "https://openlibrary.org"
+
+// OpenLibraryProvider 通过 Open Library Books API（jscmd=data）查询元数据，
+// baseURL 可指向兼容该格式的镜像或本地假服务
+type OpenLibraryProvider struct {
+	baseURL string
+	client  *http.Client
+}
+
+func NewOpenLibraryProvider(baseURL string, timeout time.Duration) *OpenLibraryProvider {
+	if baseURL == "" {
+		baseURL = DefaultOpenLibraryURL
+	}
+	return &OpenLibraryProvider{
+		baseURL: strings.TrimRight(baseURL, "/"),
+		client:  &http.Client{Timeout: timeout},
+	}
+}
+
+func (p *OpenLibraryProvider) Name() string {
+	return "openlibrary"
+}
+
+type openLibraryNamed struct {
+	Name string `json:"name"`
+}
+
+type openLibraryBook struct {
+	Title         string             `json:"title"`
+	Subtitle      string             `json:"subtitle"`
+	Authors       []openLibraryNamed `json:"authors"`
+	Publishers    []openLibraryNamed `json:"publishers"`
+	PublishDate   string             `json:"publish_date"`
+	NumberOfPages int                `json:"number_of_pages"`
+	Subjects      []openLibraryNamed `json:"subjects"`
+	// notes 可能是字符串，也可能是 {"type": ..., "value": ...}
+	Notes    json.RawMessage `json:"notes"`
+	Excerpts []struct {
+		Text string `json:"text"`
+	} `json:"excerpts"`
+	Cover struct {
+		Small  string `json:"small"`
+		Medium string `json:"medium"`
+		Large  string `json:"large"`
+	} `json:"cover"`
+}
+
+func (p *OpenLibraryProvider) Lookup(ctx context.Context, isbn string) (*BookMetadata, error) {
+	key := "ISBN:" + isbn
+	query := url.Values{}
+	query.Set("bibkeys", key)
+	query.Set("format", "json")
+	query.Set("jscmd", "data")
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, p.baseURL+"/api/books?"+query.Encode(), nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, ErrNotFound
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("openlibrary returned status %d", resp.StatusCode)
+	}
+
+	var result map[string]openLibraryBook
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("decode openlibrary response: %w", err)
+	}
+
+	book, ok := result[key]
+	if !ok || book.Title == "" {
+		return nil, ErrNotFound
+	}
+
+	meta := &BookMetadata{
+		ISBN:        isbn,
+		Title:       book.Title,
+		PublishDate: parsePublishDate(book.PublishDate),
+		Pages:       book.NumberOfPages,
+		Description: openLibraryNotes(book.Notes),
+	}
+	if book.Subtitle != "" {
+		meta.Title += ": " + book.Subtitle
+	}
+	for _, a := range book.Authors {
+		meta.Authors = append(meta.Authors, a.Name)
+	}
+	if len(book.Publishers) > 0 {
+		meta.Publisher = book.Publishers[0].Name
+	}
+	for _, s := range book.Subjects {
+		meta.Subjects = append(meta.Subjects, s.Name)
+	}
+	if meta.Description == "" && len(book.Excerpts) > 0 {
+		meta.Description = book.Excerpts[0].Text
+	}
+	switch {
+	case book.Cover.Large != "":
+		meta.CoverURL = book.Cover.Large
+	case book.Cover.Medium != "":
+		meta.CoverURL = book.Cover.Medium
+	default:
+		meta.CoverURL = book.Cover.Small
+	}
+
+	return meta, nil
+}
+
+func openLibraryNotes(raw json.RawMessage) string {
+	if len(raw) == 0 {
+		return ""
+	}
+	var s string
+	if err := json.Unmarshal(raw, &s); err == nil {
+		return s
+	}
+	var typed struct {
+		Value string `json:"value"`
+	}
+	if err := json.Unmarshal(raw, &typed); err == nil {
+		return typed.Value
+	}
+	return ""
+}
+
+// Open Library 的出版日期是自由文本，常见格式如下
+var publishDateLayouts = []string{
+	"2006-01-02",
+	"January 2, 2006",
+	"Jan 2, 2006",
+	"2 January 2006",
+	"January 2006",
+	"Jan 2006",
+	"2006-01",
+	"2006",
+}
+
+func parsePublishDate(s string) string {
+	s = strings.TrimSpace(s)
+	for _, layout := range publishDateLayouts {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t.Format("2006-01-02")
+		}
+	}
+	return ""
+}
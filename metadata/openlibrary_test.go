@@ -0,0 +1,158 @@
+package metadata
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func newOpenLibraryServer(t *testing.T, status int, body string) *httptest.Server {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/books" {
+			t.Errorf("path = %s, want /api/books", r.URL.Path)
+		}
+		q := r.URL.Query()
+		if q.Get("bibkeys") != "ISBN:9780306406157" || q.Get("format") != "json" || q.Get("jscmd") != "data" {
+			t.Errorf("query = %s", r.URL.RawQuery)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status)
+		w.Write([]byte(body))
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestOpenLibraryLookup(t *testing.T) {
+	srv := newOpenLibraryServer(t, http.StatusOK, `{
+		"ISBN:9780306406157": {
+			"title": "Information Theory",
+			"subtitle": "An Introduction",
+			"authors": [{"name": "Ada Lovelace"}, {"name": "Alan Turing"}],
+			"publishers": [{"name": "Plenum Press"}, {"name": "Reprint House"}],
+			"publish_date": "March 3, 2004",
+			"number_of_pages": 320,
+			"subjects": [{"name": "Mathematics"}, {"name": "Computing"}],
+			"notes": {"type": "/type/text", "value": "Second edition."},
+			"excerpts": [{"text": "Chapter one."}],
+			"cover": {"small": "https://covers.example/s.jpg", "medium": "https://covers.example/m.jpg"}
+		}
+	}`)
+	p := NewOpenLibraryProvider(srv.URL+"/", time.Second)
+
+	got, err := p.Lookup(context.Background(), "9780306406157")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := &BookMetadata{
+		ISBN:        "9780306406157",
+		Title:       "Information Theory: An Introduction",
+		Authors:     []string{"Ada Lovelace", "Alan Turing"},
+		Publisher:   "Plenum Press",
+		PublishDate: "2004-03-03",
+		Description: "Second edition.",
+		CoverURL:    "https://covers.example/m.jpg",
+		Pages:       320,
+		Subjects:    []string{"Mathematics", "Computing"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("Lookup = %+v\nwant %+v", got, want)
+	}
+}
+
+func TestOpenLibraryLookupFallbacks(t *testing.T) {
+	srv := newOpenLibraryServer(t, http.StatusOK, `{
+		"ISBN:9780306406157": {
+			"title": "Information Theory",
+			"publish_date": "1998",
+			"excerpts": [{"text": "Chapter one."}],
+			"cover": {"small": "https://covers.example/s.jpg"}
+		}
+	}`)
+	p := NewOpenLibraryProvider(srv.URL, time.Second)
+
+	got, err := p.Lookup(context.Background(), "9780306406157")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Description != "Chapter one." {
+		t.Errorf("Description = %q, want excerpt", got.Description)
+	}
+	if got.CoverURL != "https://covers.example/s.jpg" {
+		t.Errorf("CoverURL = %q, want small cover", got.CoverURL)
+	}
+	if got.PublishDate != "1998-01-01" {
+		t.Errorf("PublishDate = %q, want 1998-01-01", got.PublishDate)
+	}
+}
+
+func TestOpenLibraryLookupNotFound(t *testing.T) {
+	tests := []struct {
+		name   string
+		status int
+		body   string
+	}{
+		{"empty result", http.StatusOK, `{}`},
+		{"no title", http.StatusOK, `{"ISBN:9780306406157": {"authors": [{"name": "Anonymous"}]}}`},
+		{"404", http.StatusNotFound, `{}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := newOpenLibraryServer(t, tt.status, tt.body)
+			p := NewOpenLibraryProvider(srv.URL, time.Second)
+
+			if _, err := p.Lookup(context.Background(), "9780306406157"); !errors.Is(err, ErrNotFound) {
+				t.Fatalf("err = %v, want ErrNotFound", err)
+			}
+		})
+	}
+}
+
+func TestOpenLibraryLookupErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		status int
+		body   string
+	}{
+		{"server error", http.StatusInternalServerError, `{}`},
+		{"malformed json", http.StatusOK, `{"ISBN:9780306406157": `},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := newOpenLibraryServer(t, tt.status, tt.body)
+			p := NewOpenLibraryProvider(srv.URL, time.Second)
+
+			_, err := p.Lookup(context.Background(), "9780306406157")
+			if err == nil || errors.Is(err, ErrNotFound) {
+				t.Fatalf("err = %v, want a non-ErrNotFound error", err)
+			}
+		})
+	}
+}
+
+func TestParsePublishDate(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{"2004-03-03", "2004-03-03"},
+		{"March 3, 2004", "2004-03-03"},
+		{"Mar 3, 2004", "2004-03-03"},
+		{"3 March 2004", "2004-03-03"},
+		{"March 2004", "2004-03-01"},
+		{"2004-03", "2004-03-01"},
+		{" 2004 ", "2004-01-01"},
+		{"circa 2004", ""},
+		{"", ""},
+	}
+	for _, tt := range tests {
+		if got := parsePublishDate(tt.in); got != tt.want {
+			t.Errorf("parsePublishDate(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,30 @@
+package metadata
+
+import (
+	"context"
+	"errors"
+)
+
+// ErrNotFound 元数据源中没有该 ISBN 的记录
+var ErrNotFound = errors.New("metadata not found")
+
+// BookMetadata 从外部元数据源获取的图书信息
+type BookMetadata struct {
+	ISBN        string
+	Title       string
+	Authors     []string
+	Publisher   string
+	PublishDate string // 统一为 2006-01-02，仅有年份时取当年 1 月 1 日；无法解析时为空
+	Description string
+	CoverURL    string
+	Pages       int
+	Subjects    []string
+}
+
+// MetadataProvider 图书元数据源，新增数据源只需实现该接口并在初始化时注册
+type MetadataProvider interface {
+	// Name 数据源名称，随查询结果返回便于管理员核对来源
+	Name() string
+	// Lookup 按 ISBN-13 查询，查不到时返回 ErrNotFound
+	Lookup(ctx context.Context, isbn string) (*BookMetadata, error)
+}
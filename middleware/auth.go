@@ -1,7 +1,9 @@
 package middleware
 
 import (
+	"encoding/json"
 	"strings"
+	"time"
 	"library-system/utils"
 	"library-system/common"
 	"library-system/repository"
@@ -24,46 +26,118 @@ func AuthMiddleware() gin.HandlerFunc {
 			c.Abort()
 			return
 		}
-		tokenString := parts[1]
 
-		claims, err := utils.ValidateAccessToken(tokenString)
-		if err != nil {
-			c.Error(common.ErrInvalidToken)
-			c.Abort()
+		authenticate(c, parts[1])
+	}
+}
+
+// StreamAuthMiddleware 用于 SSE 等长连接：浏览器 EventSource 无法设置请求头，可通过 ?ticket= 传递一次性票据。
+// 查询参数会出现在访问日志中，因此不接受 Access Token，只接受短期有效、用后即焚的票据
+func StreamAuthMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		authHeader := c.GetHeader("Authorization")
+		if authHeader == "" {
+			ticket := c.Query("ticket")
+			if ticket == "" {
+				c.Error(common.ErrInvalidToken)
+				c.Abort()
+				return
+			}
+			authenticateTicket(c, ticket)
 			return
 		}
 
-		inBlacklist, err := repository.Rdb.IsInBlacklist(c. Request.Context(), claims.TokenID)
-		if err != nil || inBlacklist {
-			c.Error(common.ErrInvalidToken)
+		parts := strings.SplitN(authHeader, " ", 2)
+		if len(parts) != 2 || parts[0] != "Bearer" {
+			c.Error(common.ErrUnauthorized)
 			c.Abort()
 			return
 		}
 
-        c.Set("user_id", claims.UserID)
-		c.Set("username", claims.Username)
-		c.Set("role", claims.Role)
-		c.Set("token_id", claims.TokenID)
+		authenticate(c, parts[1])
+	}
+}
 
-		c.Next()
+// authenticate 校验 Access Token 并写入用户信息，失败时中止请求
+func authenticate(c *gin.Context, tokenString string) {
+	claims, err := utils.ValidateAccessToken(tokenString)
+	if err != nil {
+		c.Error(common.ErrInvalidToken)
+		c.Abort()
+		return
 	}
+
+	authorize(c, claims)
 }
 
-func RoleMiddleware() gin.HandlerFunc {
-	return func(c *gin.Context) {
-		role, exists := c.Get("role")
-		if !exists {
-			c.Error(common.ErrUnauthorized)
+// authenticateTicket 兑换事件流票据，票据中保存签发时的 Token 信息，仍需检查是否过期或已被吊销
+func authenticateTicket(c *gin.Context, ticket string) {
+	payload, err := repository.Rdb.ConsumeStreamTicket(c.Request.Context(), utils.HashOpaqueToken(ticket))
+	if err != nil {
+		c.Error(common.ErrInvalidToken)
+		c.Abort()
+		return
+	}
+
+	var claims utils.AccessTokenClaims
+	if err := json.Unmarshal(payload, &claims); err != nil || claims.ExpiresAt == nil || time.Now().After(claims.ExpiresAt.Time) {
+		c.Error(common.ErrInvalidToken)
+		c.Abort()
+		return
+	}
+
+	authorize(c, &claims)
+}
+
+// authorize 检查 Token 是否已被吊销，写入用户信息与权限
+func authorize(c *gin.Context, claims *utils.AccessTokenClaims) {
+	inBlacklist, err := repository.Rdb.IsInBlacklist(c. Request.Context(), claims.TokenID)
+	if err != nil || inBlacklist {
+		c.Error(common.ErrInvalidToken)
+		c.Abort()
+		return
+	}
+
+	c.Set("user_id", claims.UserID)
+	c.Set("username", claims.Username)
+	c.Set("role", claims.Role)
+	c.Set("token_id", claims.TokenID)
+	c.Set("session_id", claims.SessionID)
+	c.Set("email_verified", !claims.Pending)
+	c.Set("claims", claims)
+
+	permissions := map[string]bool{}
+	if permissionResolver != nil {
+		resolved, err := permissionResolver.Permissions(c.Request.Context(), claims.Role)
+		if err != nil {
+			c.Error(err)
 			c.Abort()
 			return
 		}
+		if resolved != nil {
+			permissions = resolved
+		}
+	}
+	c.Set("permissions", permissions)
+
+	// 供服务层（如审计日志）识别操作人
+	if meta := common.RequestMetaFrom(c.Request.Context()); meta != nil {
+		meta.UserID = claims.UserID
+		meta.Username = claims.Username
+	}
 
-		if role.(string) != "admin" {
-			c.Error(common.ErrPermissionDenied)
+	c.Next()
+}
+
+// RequireVerifiedEmail 未验证邮箱的用户只能浏览，不能执行借阅、预约、评价等操作，需放在 AuthMiddleware 之后
+func RequireVerifiedEmail() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !c.GetBool("email_verified") {
+			c.Error(common.ErrEmailNotVerified)
 			c.Abort()
 			return
 		}
 
 		c.Next()
 	}
-}
\ No newline at end of file
+}
@@ -0,0 +1,39 @@
+package middleware
+
+import (
+	"context"
+	"library-system/common"
+
+	"github.com/gin-gonic/gin"
+)
+
+// PermissionResolver 根据角色查询其拥有的权限
+type PermissionResolver interface {
+	Permissions(ctx context.Context, role string) (map[string]bool, error)
+}
+
+var permissionResolver PermissionResolver
+
+// SetPermissionResolver 设置角色权限的查询方式，需在处理请求前调用
+func SetPermissionResolver(resolver PermissionResolver) {
+	permissionResolver = resolver
+}
+
+// RequirePermission 要求当前用户的角色拥有指定权限，需放在 AuthMiddleware 之后
+func RequirePermission(permission string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if _, exists := c.Get("role"); !exists {
+			c.Error(common.ErrUnauthorized)
+			c.Abort()
+			return
+		}
+
+		if !common.HasPermission(c, permission) {
+			c.Error(common.ErrPermissionDenied)
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
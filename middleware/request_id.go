@@ -0,0 +1,43 @@
+package middleware
+
+import (
+	"library-system/common"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+const requestIDHeader = "X-Request-ID"
+
+// RequestID 为每个请求分配请求 ID 并写入响应头，沿用客户端或网关传入的合法 ID，便于串联日志与审计记录
+func RequestID() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		requestID := c.GetHeader(requestIDHeader)
+		if !validRequestID(requestID) {
+			requestID = uuid.NewString()
+		}
+
+		c.Set("request_id", requestID)
+		c.Header(requestIDHeader, requestID)
+
+		meta := &common.RequestMeta{RequestID: requestID, IP: c.ClientIP(), UserAgent: c.Request.UserAgent()}
+		c.Request = c.Request.WithContext(common.WithRequestMeta(c.Request.Context(), meta))
+
+		c.Next()
+	}
+}
+
+// validRequestID 只接受长度不超过 64 的字母、数字及 -_.，避免日志注入
+func validRequestID(id string) bool {
+	if id == "" || len(id) > 64 {
+		return false
+	}
+	for _, r := range id {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '-', r == '_', r == '.':
+		default:
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,80 @@
+package model
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// AuditEvent 审计日志，只追加不修改。Changes 记录发生变化的字段及其前后取值
+type AuditEvent struct {
+	ID         uint64       `json:"id" gorm:"primaryKey;autoIncrement"`
+	ActorID    *uint64      `json:"actor_id" gorm:"index:idx_actor"` // 定时任务等系统操作为空
+	ActorName  string       `json:"actor_name" gorm:"type:varchar(20)"`
+	Action     string       `json:"action" gorm:"type:varchar(30);not null;index:idx_action"`
+	EntityType string       `json:"entity_type" gorm:"type:varchar(30);not null;index:idx_entity,priority:1"`
+	EntityID   uint64       `json:"entity_id" gorm:"index:idx_entity,priority:2"`
+	Changes    AuditChanges `json:"changes" gorm:"type:json"`
+	IP         string       `json:"ip" gorm:"type:varchar(45)"`
+	RequestID  string       `json:"request_id" gorm:"type:varchar(64);index:idx_request"`
+	CreatedAt  time.Time    `json:"created_at" gorm:"autoCreateTime;index:idx_created_at"`
+}
+
+// 审计动作
+const (
+	AuditActionCreate            = "create"
+	AuditActionUpdate            = "update"
+	AuditActionDelete            = "delete"
+	AuditActionRestore           = "restore"
+	AuditActionImport            = "import"
+	AuditActionUploadCover       = "upload_cover"
+	AuditActionBorrow            = "borrow"
+	AuditActionReturn            = "return"
+	AuditActionRenew             = "renew"
+	AuditActionRevokeSessions    = "revoke_sessions"
+	AuditActionRefreshTokenReuse = "refresh_token_reuse"
+)
+
+// 审计对象类型
+const (
+	AuditEntityBook            = "book"
+	AuditEntityBookCopy        = "book_copy"
+	AuditEntityCategory        = "category"
+	AuditEntityUser            = "user"
+	AuditEntityBorrow          = "borrow"
+	AuditEntityFineTransaction = "fine_transaction"
+	AuditEntityLoanPolicy      = "loan_policy"
+	AuditEntityReview          = "review"
+	AuditEntityRole            = "role"
+)
+
+// AuditChange 单个字段的变更，创建时 Before 为空，删除时 After 为空
+type AuditChange struct {
+	Before interface{} `json:"before"`
+	After  interface{} `json:"after"`
+}
+
+// AuditChanges 字段名到变更的映射，以 JSON 存储
+type AuditChanges map[string]AuditChange
+
+func (c AuditChanges) Value() (driver.Value, error) {
+	if c == nil {
+		return nil, nil
+	}
+	b, err := json.Marshal(c)
+	return string(b), err
+}
+
+func (c *AuditChanges) Scan(value interface{}) error {
+	switch v := value.(type) {
+	case nil:
+		*c = nil
+		return nil
+	case []byte:
+		return json.Unmarshal(v, c)
+	case string:
+		return json.Unmarshal([]byte(v), c)
+	}
+	return fmt.Errorf("unsupported audit changes value: %T", value)
+}
@@ -1,25 +1,35 @@
 package model
 
 import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
 	"time"
+
+	"gorm.io/gorm"
 )
 
 type Book struct {
 	ID			uint64    `json:"id" gorm:"primaryKey;autoIncrement"`
-	Title		string    `json:"title" gorm:"type:varchar(200);not null;index:idx_title"`
-	Author     	string 	  `json:"author" gorm:"type:varchar(100);not null;index:idx_author"`
+	Title		string    `json:"title" gorm:"type:varchar(200);not null;index:idx_title;index:idx_fulltext,class:FULLTEXT,option:WITH PARSER ngram,priority:1"`
+	Author     	string 	  `json:"author" gorm:"type:varchar(100);not null;index:idx_author;index:idx_fulltext,priority:2"`
 	ISBN        string    `json:"isbn" gorm:"type:varchar(20);unique;not null;index:idx_isbn"`
     CategoryID  uint      `json:"category_id" gorm:"index:idx_category;not null"`
-    Publisher   string    `json:"publisher" gorm:"type:varchar(100);not null"`
+    Publisher   string    `json:"publisher" gorm:"type:varchar(100);not null;index:idx_fulltext,priority:3"`
     PublishDate *time.Time`json:"publish_date" gorm:"type:date"`
     Price       float64   `json:"price" gorm:"type:decimal(10,2)"`
     Stock       int       `json:"stock" gorm:"default:0"`
-    Description string    `json:"description" gorm:"type:text"`
+    Description string    `json:"description" gorm:"type:text;index:idx_fulltext,priority:4"`
     CoverURL    string    `json:"cover_url" gorm:"type:varchar(500)"`
+    // 上传封面生成的缩略图地址，键为规格名（small、medium）
+    CoverThumbnails CoverThumbnails `json:"cover_thumbnails" gorm:"type:json"`
+    CoverKey    string    `json:"-" gorm:"type:varchar(255)"` // 上传封面原图在存储中的 key，替换封面时据此清理旧文件
     BorrowCount int       `json:"borrow_count" gorm:"default:0"`
-    Rating      float64   `json:"rating" gorm:"type:decimal(3,2)"`
+    Rating      float64   `json:"rating" gorm:"type:decimal(3,2)"` // 可见评价的平均分，由评价变更时重新计算
+    ReviewCount int       `json:"review_count" gorm:"default:0"`
     CreatedAt   time.Time `json:"created_at" gorm:"autoCreateTime"`
     UpdatedAt   time.Time `json:"updated_at" gorm:"autoUpdateTime"`
+    DeletedAt   gorm.DeletedAt `json:"-" gorm:"index"` // 软删除，历史借阅与预约仍可关联到已删除的图书
 
     Category    Category    `gorm:"foreignKey:CategoryID"`
 }
@@ -28,8 +38,33 @@ type Category struct {
     ID          uint      `json:"id" gorm:"primaryKey;autoIncrement"`
     Name        string    `json:"name" gorm:"type:varchar(50);not null"`
     Description string    `json:"description" gorm:"type:varchar(200)"`
-    ParentID    *uint
+    ParentID    *uint     `json:"parent_id"`
     CreatedAt   time.Time `json:"created_at" gorm:"autoCreateTime"`
     UpdatedAt   time.Time `json:"updated_at" gorm:"autoUpdateTime"`
+    DeletedAt   gorm.DeletedAt `json:"-" gorm:"index"`
+}
+
+
+// CoverThumbnails 封面缩略图地址，以 JSON 存储
+type CoverThumbnails map[string]string
+
+func (t CoverThumbnails) Value() (driver.Value, error) {
+	if t == nil {
+		return nil, nil
+	}
+	b, err := json.Marshal(t)
+	return string(b), err
 }
 
+func (t *CoverThumbnails) Scan(value interface{}) error {
+	switch v := value.(type) {
+	case nil:
+		*t = nil
+		return nil
+	case []byte:
+		return json.Unmarshal(v, t)
+	case string:
+		return json.Unmarshal([]byte(v), t)
+	}
+	return fmt.Errorf("unsupported cover thumbnails value: %T", value)
+}
@@ -0,0 +1,32 @@
+package model
+
+import (
+	"time"
+)
+
+// BookCopy 馆藏副本（每一册实体书对应一条记录）
+type BookCopy struct {
+	ID            uint64     `json:"id" gorm:"primaryKey;autoIncrement"`
+	BookID        uint64     `json:"book_id" gorm:"index:idx_book;not null"`
+	Barcode       string     `json:"barcode" gorm:"type:varchar(50);unique;not null"`
+	ShelfLocation string     `json:"shelf_location" gorm:"type:varchar(100)"`
+	AcquiredAt    *time.Time `json:"acquired_at" gorm:"type:date"`
+	Status        string     `json:"status" gorm:"type:enum('available','on_loan','in_repair','lost','withdrawn');default:'available';index:idx_status"`
+	Remark        string     `json:"remark" gorm:"type:varchar(255)"`
+	CreatedAt     time.Time  `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt     time.Time  `json:"updated_at" gorm:"autoUpdateTime"`
+
+	Book Book `gorm:"foreignKey:BookID"`
+}
+
+// 副本状态说明
+const (
+	CopyStatusAvailable = "available" // 在架可借
+	CopyStatusOnLoan    = "on_loan"   // 借出中
+	CopyStatusInRepair  = "in_repair" // 维修中
+	CopyStatusLost      = "lost"      // 已遗失
+	CopyStatusWithdrawn = "withdrawn" // 已剔旧下架
+)
+
+// CirculatingCopyStatuses 计入馆藏总量（Book.Stock）的副本状态
+var CirculatingCopyStatuses = []string{CopyStatusAvailable, CopyStatusOnLoan}
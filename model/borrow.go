@@ -8,15 +8,35 @@ type BorrowRecord struct {
     ID         uint64     `json:"id" gorm:"primaryKey;autoIncrement"`
     BookID     uint64     `json:"book_id" gorm:"index:idx_book;not null"`
     UserID     uint64     `json:"user_id" gorm:"index:idx_user;not null"`
+    CopyID     *uint64    `json:"copy_id" gorm:"index:idx_copy"`
     BorrowDate time.Time  `json:"borrow_date" gorm:"autoCreateTime"`
     DueDate    time.Time  `json:"due_date" gorm:"not null;index:idx_due_date"`
     ReturnDate *time.Time `json:"return_date,omitempty" gorm:"index:idx_return_date"`
     Status     string     `json:"status" gorm:"type:enum('borrowed','returned','overdue');default:'borrowed';index:idx_status"`
     RenewCount int        `json:"renew_count" gorm:"default:0"`
     Fine       float64    `json:"fine" gorm:"type:decimal(10,2);default:0"`
+    RemindedAt *time.Time `json:"reminded_at,omitempty"` // 已发送到期提醒的时间，续借后清空
+
+    // 归还时的图书状况，损坏/遗失需赔偿（已计入 Fine）
+    ReturnCondition *string `json:"return_condition" gorm:"type:enum('good','damaged','lost');index:idx_return_condition"`
+    ReturnRemark    string  `json:"return_remark" gorm:"type:varchar(255)"`
+    CompensationFee float64 `json:"compensation_fee" gorm:"type:decimal(10,2);default:0"`
+
+    // 馆员在流通台代为借出/归还时记录操作人，读者自助借还为空
+    CheckoutStaffID *uint64 `json:"checkout_staff_id" gorm:"index:idx_checkout_staff"`
+    CheckinStaffID  *uint64 `json:"checkin_staff_id" gorm:"index:idx_checkin_staff"`
+
     CreatedAt  time.Time  `json:"created_at" gorm:"autoCreateTime"`
     UpdatedAt  time.Time  `json:"updated_at" gorm:"autoUpdateTime"`
 
     Book Book `gorm:"foreignKey:BookID"`
     User User `gorm:"foreignKey:UserID"`
+    Copy *BookCopy `gorm:"foreignKey:CopyID"`
 }
+
+// 归还状况说明
+const (
+    ReturnConditionGood    = "good"    // 完好
+    ReturnConditionDamaged = "damaged" // 损坏（送修）
+    ReturnConditionLost    = "lost"    // 遗失
+)
@@ -0,0 +1,28 @@
+package model
+
+import (
+	"time"
+)
+
+// FineTransaction 罚金流水，Amount 为正表示增加欠款，为负表示减少欠款
+type FineTransaction struct {
+	ID         uint64    `json:"id" gorm:"primaryKey;autoIncrement"`
+	UserID     uint64    `json:"user_id" gorm:"index:idx_user;not null"`
+	BorrowID   *uint64   `json:"borrow_id" gorm:"index:idx_borrow"`
+	Type       string    `json:"type" gorm:"type:enum('charge','payment','waiver','adjustment');not null;index:idx_type"`
+	Amount     float64   `json:"amount" gorm:"type:decimal(10,2);not null"`
+	Reason     string    `json:"reason" gorm:"type:varchar(255)"`
+	OperatorID *uint64   `json:"operator_id"` // 登记该笔流水的管理员，系统自动产生的为空
+	CreatedAt  time.Time `json:"created_at" gorm:"autoCreateTime;index:idx_created_at"`
+
+	User   User          `gorm:"foreignKey:UserID"`
+	Borrow *BorrowRecord `gorm:"foreignKey:BorrowID"`
+}
+
+// 罚金流水类型说明
+const (
+	FineTypeCharge     = "charge"     // 产生罚金（归还时结算）
+	FineTypePayment    = "payment"    // 缴纳
+	FineTypeWaiver     = "waiver"     // 减免
+	FineTypeAdjustment = "adjustment" // 人工调整，可正可负
+)
@@ -0,0 +1,21 @@
+package model
+
+import (
+	"time"
+)
+
+// LoanPolicy 借阅规则，Role/CategoryID 为空表示对所有角色/分类生效
+type LoanPolicy struct {
+	ID            uint64    `json:"id" gorm:"primaryKey;autoIncrement"`
+	Role          *string   `json:"role" gorm:"type:varchar(30);index:idx_role_category"`
+	CategoryID    *uint     `json:"category_id" gorm:"index:idx_role_category"`
+	LoanDays      int       `json:"loan_days" gorm:"not null"`
+	MaxRenewCount int       `json:"max_renew_count" gorm:"default:0"`
+	RenewDays     int       `json:"renew_days" gorm:"not null"`
+	MaxLoans      int       `json:"max_loans" gorm:"default:0"`                    // 0 表示仅受用户借阅上限约束
+	FineRate      float64   `json:"fine_rate" gorm:"type:decimal(10,2);default:0"` // 逾期罚金（元/天）
+	FineCap       float64   `json:"fine_cap" gorm:"type:decimal(10,2);default:0"`  // 单笔罚金上限，0 表示不封顶
+	Description   string    `json:"description" gorm:"type:varchar(200)"`
+	CreatedAt     time.Time `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt     time.Time `json:"updated_at" gorm:"autoUpdateTime"`
+}
@@ -0,0 +1,17 @@
+package model
+
+import (
+	"time"
+)
+
+// Notification 站内消息
+type Notification struct {
+	ID        uint64     `json:"id" gorm:"primaryKey;autoIncrement"`
+	UserID    uint64     `json:"user_id" gorm:"index:idx_user_read;not null"`
+	Event     string     `json:"event" gorm:"type:varchar(50);not null"`
+	Title     string     `json:"title" gorm:"type:varchar(200)"`
+	Content   string     `json:"content" gorm:"type:text"`
+	Payload   string     `json:"payload" gorm:"type:text"` // 事件数据（JSON）
+	ReadAt    *time.Time `json:"read_at" gorm:"index:idx_user_read"`
+	CreatedAt time.Time  `json:"created_at" gorm:"autoCreateTime"`
+}
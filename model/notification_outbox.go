@@ -0,0 +1,40 @@
+package model
+
+import (
+	"time"
+)
+
+// NotificationOutbox 通知发件箱，每条记录对应一个渠道的一次投递，失败后按退避时间重试
+type NotificationOutbox struct {
+	ID            uint64     `json:"id" gorm:"primaryKey;autoIncrement"`
+	Event         string     `json:"event" gorm:"type:varchar(50);not null;index:idx_event"`
+	Channel       string     `json:"channel" gorm:"type:varchar(20);not null"`
+	UserID        uint64     `json:"user_id" gorm:"index:idx_user;not null"`
+	Recipient     string     `json:"recipient" gorm:"type:varchar(100)"`
+	Subject       string     `json:"subject" gorm:"type:varchar(200)"`
+	Body          string     `json:"body" gorm:"type:text"`
+	Payload       string     `json:"payload" gorm:"type:text"` // 事件数据（JSON）
+	Status        string     `json:"status" gorm:"type:enum('pending','sent','failed');default:'pending';index:idx_status_next"`
+	Attempts      int        `json:"attempts" gorm:"default:0"`
+	NextAttemptAt time.Time  `json:"next_attempt_at" gorm:"index:idx_status_next"`
+	LastError     string     `json:"last_error" gorm:"type:varchar(500)"`
+	SentAt        *time.Time `json:"sent_at"`
+	CreatedAt     time.Time  `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt     time.Time  `json:"updated_at" gorm:"autoUpdateTime"`
+}
+
+// 发件箱状态说明
+const (
+	OutboxStatusPending = "pending" // 待发送（含等待重试）
+	OutboxStatusSent    = "sent"    // 已发送
+	OutboxStatusFailed  = "failed"  // 超过重试次数，放弃发送
+)
+
+// 通知事件
+const (
+	NotifyEventReservationReady   = "reservation_ready"   // 预约的图书可借
+	NotifyEventDueSoon            = "due_soon"            // 借阅即将到期
+	NotifyEventOverdue            = "overdue"             // 借阅已逾期
+	NotifyEventReservationExpired = "reservation_expired" // 预约超时未借已失效
+	NotifyEventFineCharged        = "fine_charged"        // 归还结算产生罚金
+)
@@ -0,0 +1,27 @@
+package model
+
+import (
+	"time"
+)
+
+// Review 图书评价，每位读者对同一本书只能评价一次
+type Review struct {
+	ID           uint64    `json:"id" gorm:"primaryKey;autoIncrement"`
+	BookID       uint64    `json:"book_id" gorm:"uniqueIndex:idx_book_user;not null"`
+	UserID       uint64    `json:"user_id" gorm:"uniqueIndex:idx_book_user;index:idx_user;not null"`
+	Rating       int       `json:"rating" gorm:"type:tinyint;not null"`
+	Content      string    `json:"content" gorm:"type:text"`
+	Status       string    `json:"status" gorm:"type:enum('visible','hidden');default:'visible';index:idx_status"`
+	HiddenReason string    `json:"hidden_reason" gorm:"type:varchar(255)"`
+	CreatedAt    time.Time `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt    time.Time `json:"updated_at" gorm:"autoUpdateTime"`
+
+	Book Book `gorm:"foreignKey:BookID"`
+	User User `gorm:"foreignKey:UserID"`
+}
+
+// 评价状态说明
+const (
+	ReviewStatusVisible = "visible" // 正常展示
+	ReviewStatusHidden  = "hidden"  // 被管理员隐藏，不计入评分
+)
@@ -0,0 +1,92 @@
+package model
+
+import "time"
+
+// Role 角色，权限通过 RolePermission 授予。内置角色由系统启动时写入
+type Role struct {
+	ID          uint64           `json:"id" gorm:"primaryKey;autoIncrement"`
+	Name        string           `json:"name" gorm:"type:varchar(30);unique;not null"`
+	Description string           `json:"description" gorm:"type:varchar(255)"`
+	Builtin     bool             `json:"builtin" gorm:"default:false"`
+	Permissions []RolePermission `gorm:"foreignKey:RoleID"`
+	CreatedAt   time.Time        `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt   time.Time        `json:"updated_at" gorm:"autoUpdateTime"`
+}
+
+// RolePermission 角色被授予的一项权限
+type RolePermission struct {
+	ID         uint64 `json:"id" gorm:"primaryKey;autoIncrement"`
+	RoleID     uint64 `json:"role_id" gorm:"not null;uniqueIndex:idx_role_permission,priority:1"`
+	Permission string `json:"permission" gorm:"type:varchar(50);not null;uniqueIndex:idx_role_permission,priority:2"`
+}
+
+// 内置角色
+const (
+	RoleAdmin     = "admin"     // 拥有全部权限，不可修改
+	RoleLibrarian = "librarian" // 流通管理
+	RoleCataloger = "cataloger" // 编目管理
+	RoleAuditor   = "auditor"   // 只读查看统计与审计
+	RoleUser      = "user"      // 普通读者，无管理权限
+)
+
+// 权限
+const (
+	PermUsersRead         = "users:read"
+	PermUsersWrite        = "users:write"
+	PermRolesManage       = "roles:manage"
+	PermBooksWrite        = "books:write"
+	PermCategoriesWrite   = "categories:write"
+	PermReviewsModerate   = "reviews:moderate"
+	PermCirculationRead   = "circulation:read"
+	PermCirculationWrite  = "circulation:write"
+	PermLoanPoliciesRead  = "loan_policies:read"
+	PermLoanPoliciesWrite = "loan_policies:write"
+	PermStatsRead         = "stats:read"
+	PermAuditRead         = "audit:read"
+)
+
+// AllPermissions 系统支持的全部权限
+var AllPermissions = []string{
+	PermUsersRead,
+	PermUsersWrite,
+	PermRolesManage,
+	PermBooksWrite,
+	PermCategoriesWrite,
+	PermReviewsModerate,
+	PermCirculationRead,
+	PermCirculationWrite,
+	PermLoanPoliciesRead,
+	PermLoanPoliciesWrite,
+	PermStatsRead,
+	PermAuditRead,
+}
+
+// BuiltinRoles 内置角色及其初始权限，admin 的权限不落库，始终为全部权限
+var BuiltinRoles = []Role{
+	{Name: RoleAdmin, Description: "系统管理员"},
+	{Name: RoleLibrarian, Description: "馆员，负责借还、罚金等流通业务", Permissions: []RolePermission{
+		{Permission: PermCirculationRead},
+		{Permission: PermCirculationWrite},
+		{Permission: PermUsersRead},
+		{Permission: PermLoanPoliciesRead},
+	}},
+	{Name: RoleCataloger, Description: "编目员，负责图书与分类", Permissions: []RolePermission{
+		{Permission: PermBooksWrite},
+		{Permission: PermCategoriesWrite},
+	}},
+	{Name: RoleAuditor, Description: "审计员，只读查看统计与审计日志", Permissions: []RolePermission{
+		{Permission: PermStatsRead},
+		{Permission: PermAuditRead},
+	}},
+	{Name: RoleUser, Description: "普通读者"},
+}
+
+// IsValidPermission 判断权限是否为系统支持的权限
+func IsValidPermission(permission string) bool {
+	for _, p := range AllPermissions {
+		if p == permission {
+			return true
+		}
+	}
+	return false
+}
@@ -2,6 +2,8 @@ package model
 
 import(
 	"time"
+
+	"gorm.io/gorm"
 )
 
 type User struct {
@@ -10,11 +12,20 @@ type User struct {
 	Password 		string 		`json:"password" gorm:"type:varchar(255);not null"`
 	Email 			string 		`json:"email" gorm:"type:varchar(100);unique;not null"`
 	Phone 			string 		`json:"phone" gorm:"varchar(11)"`
-	Role 			string 		`json:"role" gorm:"type:enum('admin','user');default:'user'"`
-	Status			string 		`json:"status" gorm:"type:enum('active','disabled');default:'active'"`
+	CardNumber		*string		`json:"card_number" gorm:"type:varchar(20);unique"` // 借书证号，馆员可凭此代借
+	Role 			string 		`json:"role" gorm:"type:varchar(30);default:'user';index"`
+	Status			string 		`json:"status" gorm:"type:enum('active','disabled','pending_verification');default:'active'"`
 	BorrowLimit 	int	   		`json:"borrow_limit" gorm:"default:5"`
 	BorrowingCount 	int			`json:"borrowing_count" gorm:"default:0"`
     OverdueCount 	int			`json:"overdue_count" gorm:"default:0"`
 	CreatedAt		time.Time	`json:"created_at" gorm:"autoCreateTime"`
 	UpdatedAt   	time.Time	`json:"updated_at" gorm:"autoUpdateTime"`
+	DeletedAt		gorm.DeletedAt	`json:"-" gorm:"index"`
 }
+
+// 用户状态
+const (
+	UserStatusActive              = "active"
+	UserStatusDisabled            = "disabled"
+	UserStatusPendingVerification = "pending_verification" // 已注册但尚未验证邮箱
+)
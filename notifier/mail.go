@@ -0,0 +1,27 @@
+package notifier
+
+import (
+	"context"
+	"log"
+)
+
+// MailSender 发送账号相关的事务邮件（如重置密码），即时发送，不经过通知发件箱
+type MailSender interface {
+	SendMail(ctx context.Context, to, subject, body string) error
+}
+
+func (n *SMTPNotifier) SendMail(ctx context.Context, to, subject, body string) error {
+	return n.Send(ctx, Message{To: to, Subject: subject, Body: body})
+}
+
+// LogMailSender 未配置 SMTP 时使用，只记录日志，不输出邮件正文以免泄露其中的凭证
+type LogMailSender struct{}
+
+func NewLogMailSender() *LogMailSender {
+	return &LogMailSender{}
+}
+
+func (s *LogMailSender) SendMail(ctx context.Context, to, subject, body string) error {
+	log.Printf("未配置 SMTP_HOST，邮件未发送: to=%s subject=%s", to, subject)
+	return nil
+}
@@ -0,0 +1,24 @@
+package notifier
+
+import (
+	"context"
+)
+
+// Message 一条待发送的通知
+type Message struct {
+	Event   string                 `json:"event"`
+	UserID  uint64                 `json:"user_id"`
+	To      string                 `json:"to"` // 邮箱等收件地址，webhook 渠道可为空
+	Subject string                 `json:"subject"`
+	Body    string                 `json:"body"`
+	Data    map[string]interface{} `json:"data,omitempty"`
+}
+
+// Notifier 通知渠道，新增渠道只需实现该接口并在初始化时注册
+type Notifier interface {
+	// Channel 渠道名称，写入发件箱用于重试时找回对应渠道
+	Channel() string
+	// RequiresRecipient 是否需要收件地址（没有地址的用户将跳过该渠道）
+	RequiresRecipient() bool
+	Send(ctx context.Context, msg Message) error
+}
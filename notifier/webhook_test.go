@@ -0,0 +1,117 @@
+package notifier
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+type webhookRequest struct {
+	header http.Header
+	body   []byte
+}
+
+func newWebhookServer(t *testing.T, status int) (*httptest.Server, <-chan webhookRequest) {
+	t.Helper()
+	received := make(chan webhookRequest, 1)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			t.Errorf("method = %s, want POST", r.Method)
+		}
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			t.Error(err)
+		}
+		received <- webhookRequest{header: r.Header.Clone(), body: body}
+		w.WriteHeader(status)
+	}))
+	t.Cleanup(srv.Close)
+	return srv, received
+}
+
+func testMessage() Message {
+	return Message{
+		Event:   "due_soon",
+		UserID:  7,
+		Subject: "《三体》将于 3 天后到期",
+		Body:    "请按时归还",
+		Data:    map[string]interface{}{"borrow_id": float64(12)},
+	}
+}
+
+func TestWebhookSignsBody(t *testing.T) {
+	srv, received := newWebhookServer(t, http.StatusNoContent)
+	n := NewWebhookNotifier(srv.URL, "s3cret")
+
+	if err := n.Send(context.Background(), testMessage()); err != nil {
+		t.Fatal(err)
+	}
+	req := <-received
+
+	mac := hmac.New(sha256.New, []byte("s3cret"))
+	mac.Write(req.body)
+	want := "sha256=" + hex.EncodeToString(mac.Sum(nil))
+	if got := req.header.Get("X-Notify-Signature"); !hmac.Equal([]byte(got), []byte(want)) {
+		t.Fatalf("signature = %q, want %q", got, want)
+	}
+	if got := req.header.Get("X-Notify-Event"); got != "due_soon" {
+		t.Errorf("X-Notify-Event = %q", got)
+	}
+	if got := req.header.Get("Content-Type"); got != "application/json" {
+		t.Errorf("Content-Type = %q", got)
+	}
+
+	var payload webhookPayload
+	if err := json.Unmarshal(req.body, &payload); err != nil {
+		t.Fatal(err)
+	}
+	if payload.UserID != 7 || payload.Subject != testMessage().Subject || payload.Data["borrow_id"] != float64(12) {
+		t.Errorf("payload = %+v", payload)
+	}
+	if payload.SentAt.IsZero() {
+		t.Error("sent_at not set")
+	}
+}
+
+func TestWebhookSignatureDependsOnSecret(t *testing.T) {
+	srv, received := newWebhookServer(t, http.StatusOK)
+	n := NewWebhookNotifier(srv.URL, "other")
+
+	if err := n.Send(context.Background(), testMessage()); err != nil {
+		t.Fatal(err)
+	}
+	req := <-received
+
+	mac := hmac.New(sha256.New, []byte("s3cret"))
+	mac.Write(req.body)
+	if req.header.Get("X-Notify-Signature") == "sha256="+hex.EncodeToString(mac.Sum(nil)) {
+		t.Fatal("signature verified with the wrong secret")
+	}
+}
+
+func TestWebhookWithoutSecretIsUnsigned(t *testing.T) {
+	srv, received := newWebhookServer(t, http.StatusOK)
+	n := NewWebhookNotifier(srv.URL, "")
+
+	if err := n.Send(context.Background(), testMessage()); err != nil {
+		t.Fatal(err)
+	}
+	if sig := (<-received).header.Get("X-Notify-Signature"); sig != "" {
+		t.Fatalf("X-Notify-Signature = %q, want none", sig)
+	}
+}
+
+func TestWebhookNon2xxIsError(t *testing.T) {
+	srv, _ := newWebhookServer(t, http.StatusBadGateway)
+	n := NewWebhookNotifier(srv.URL, "s3cret")
+
+	if err := n.Send(context.Background(), testMessage()); err == nil {
+		t.Fatal("want error for 502 response")
+	}
+}
@@ -0,0 +1,62 @@
+package repository
+
+import (
+	"context"
+	"library-system/dto/request"
+	"library-system/model"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// AuditRepository 审计日志只提供写入和查询，不提供修改与删除
+type AuditRepository struct {
+	db *gorm.DB
+}
+
+func NewAuditRepository(db *gorm.DB) *AuditRepository {
+	return &AuditRepository{db: db}
+}
+
+func (r *AuditRepository) DB() *gorm.DB {
+	return r.db
+}
+
+func (r *AuditRepository) CreateEvent(ctx context.Context, tx *gorm.DB, event *model.AuditEvent) error {
+	return gorm.G[model.AuditEvent](tx).Create(ctx, event)
+}
+
+// GetEventList 分页查询审计日志，start、end 为空时不限制时间
+func (r *AuditRepository) GetEventList(ctx context.Context, req *request.GetAuditEventListRequest, start, end *time.Time) ([]model.AuditEvent, int64, error) {
+	db := r.db.WithContext(ctx).Model(&model.AuditEvent{})
+	if req.ActorID != nil {
+		db = db.Where("actor_id = ?", *req.ActorID)
+	}
+	if req.EntityType != "" {
+		db = db.Where("entity_type = ?", req.EntityType)
+	}
+	if req.EntityID != nil {
+		db = db.Where("entity_id = ?", *req.EntityID)
+	}
+	if req.Action != "" {
+		db = db.Where("action = ?", req.Action)
+	}
+	if start != nil {
+		db = db.Where("created_at >= ?", *start)
+	}
+	if end != nil {
+		db = db.Where("created_at <= ?", *end)
+	}
+
+	var total int64
+	if err := db.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var events []model.AuditEvent
+	err := db.Order("id DESC").
+		Offset((req.Page - 1) * req.Limit).
+		Limit(req.Limit).
+		Find(&events).Error
+	return events, total, err
+}
@@ -0,0 +1,141 @@
+package repository
+
+import (
+	"context"
+	"library-system/model"
+	"library-system/utils"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type BookCopyRepository struct {
+	db *gorm.DB
+}
+
+func NewBookCopyRepository(db *gorm.DB) *BookCopyRepository {
+	return &BookCopyRepository{db: db}
+}
+
+func (r *BookCopyRepository) DB() *gorm.DB {
+	return r.db
+}
+
+func (r *BookCopyRepository) CreateCopy(ctx context.Context, tx *gorm.DB, bookCopy *model.BookCopy) error {
+	return gorm.G[model.BookCopy](tx).Create(ctx, bookCopy)
+}
+
+func (r *BookCopyRepository) GetCopyByID(ctx context.Context, id uint64) (model.BookCopy, error) {
+	return gorm.G[model.BookCopy](r.db).Where("id = ?", id).First(ctx)
+}
+
+func (r *BookCopyRepository) GetCopyByBarcode(ctx context.Context, barcode string) (model.BookCopy, error) {
+	return gorm.G[model.BookCopy](r.db).Where("barcode = ?", barcode).First(ctx)
+}
+
+func (r *BookCopyRepository) GetCopyByIDWithLock(ctx context.Context, tx *gorm.DB, id uint64) (model.BookCopy, error) {
+	txLock := tx.Clauses(clause.Locking{Strength: "UPDATE"})
+
+	return gorm.G[model.BookCopy](txLock).Where("id = ?", id).First(ctx)
+}
+
+// GetAvailableCopyWithLock 锁定该图书的一本在架副本（自助借书时自动分配）
+func (r *BookCopyRepository) GetAvailableCopyWithLock(ctx context.Context, tx *gorm.DB, bookID uint64) (model.BookCopy, error) {
+	txLock := tx.Clauses(clause.Locking{Strength: "UPDATE"})
+
+	return gorm.G[model.BookCopy](txLock).
+		Where("book_id = ? AND status = ?", bookID, model.CopyStatusAvailable).
+		Order("id ASC").
+		First(ctx)
+}
+
+// GetUnlinkedLoanCopyWithLock 锁定一本未关联到在借记录的借出副本（用于归还迁移前产生的旧借阅记录）
+func (r *BookCopyRepository) GetUnlinkedLoanCopyWithLock(ctx context.Context, tx *gorm.DB, bookID uint64) (model.BookCopy, error) {
+	txLock := tx.Clauses(clause.Locking{Strength: "UPDATE"})
+
+	linked := tx.Model(&model.BorrowRecord{}).
+		Select("copy_id").
+		Where("book_id = ? AND copy_id IS NOT NULL AND status IN ?", bookID, []string{"borrowed", "overdue"})
+
+	return gorm.G[model.BookCopy](txLock).
+		Where("book_id = ? AND status = ? AND id NOT IN (?)", bookID, model.CopyStatusOnLoan, linked).
+		Order("id ASC").
+		First(ctx)
+}
+
+func (r *BookCopyRepository) GetCopiesByBookID(ctx context.Context, bookID uint64, status *string) ([]model.BookCopy, error) {
+	db := r.db.WithContext(ctx).Where("book_id = ?", bookID)
+	if status != nil {
+		db = db.Where("status = ?", *status)
+	}
+
+	var copies []model.BookCopy
+	err := db.Order("id ASC").Find(&copies).Error
+	return copies, err
+}
+
+func (r *BookCopyRepository) CountCopiesByBookID(ctx context.Context, bookID uint64) (int64, error) {
+	var count int64
+	err := r.db.WithContext(ctx).Model(&model.BookCopy{}).Where("book_id = ?", bookID).Count(&count).Error
+	return count, err
+}
+
+func (r *BookCopyRepository) UpdateCopyFields(ctx context.Context, tx *gorm.DB, id uint64, fields map[string]interface{}) error {
+	return tx.WithContext(ctx).Model(&model.BookCopy{}).Where("id = ?", id).Updates(fields).Error
+}
+
+func (r *BookCopyRepository) DeleteCopy(ctx context.Context, tx *gorm.DB, id uint64) error {
+	return tx.WithContext(ctx).Delete(&model.BookCopy{}, id).Error
+}
+
+// HasBorrowHistory 副本是否被借阅记录引用过
+func (r *BookCopyRepository) HasBorrowHistory(ctx context.Context, copyID uint64) (bool, error) {
+	var count int64
+	err := r.db.WithContext(ctx).Model(&model.BorrowRecord{}).Where("copy_id = ?", copyID).Count(&count).Error
+	return count > 0, err
+}
+
+// SyncBookStock 按副本状态重新计算图书的馆藏总量
+func (r *BookCopyRepository) SyncBookStock(ctx context.Context, tx *gorm.DB, bookID uint64) error {
+	circulating := tx.Model(&model.BookCopy{}).
+		Select("COUNT(*)").
+		Where("book_id = ? AND status IN ?", bookID, model.CirculatingCopyStatuses)
+
+	return tx.WithContext(ctx).Model(&model.Book{}).Where("id = ?", bookID).
+		UpdateColumn("stock", circulating).Error
+}
+
+// BackfillLegacyStock 为只有 stock 计数、尚无副本记录的旧图书补建副本
+func (r *BookCopyRepository) BackfillLegacyStock(ctx context.Context) (int, error) {
+	var books []model.Book
+	err := r.db.WithContext(ctx).
+		Where("stock > 0 AND NOT EXISTS (SELECT 1 FROM book_copies bc WHERE bc.book_id = books.id)").
+		Find(&books).Error
+	if err != nil {
+		return 0, err
+	}
+
+	created := 0
+	err = r.db.Transaction(func(tx *gorm.DB) error {
+		for _, book := range books {
+			for i := 0; i < book.Stock; i++ {
+				bookCopy := model.BookCopy{
+					BookID:  book.ID,
+					Barcode: utils.GenerateBarcode(book.ISBN, i+1),
+					Status:  model.CopyStatusAvailable,
+				}
+				// 已借出的数量对应标记为借出中
+				if i < book.BorrowCount {
+					bookCopy.Status = model.CopyStatusOnLoan
+				}
+				if err := r.CreateCopy(ctx, tx, &bookCopy); err != nil {
+					return err
+				}
+				created++
+			}
+		}
+		return nil
+	})
+
+	return created, err
+}
@@ -2,8 +2,10 @@ package repository
 
 import (
 	"context"
+	"fmt"
 	"library-system/dto/request"
 	"library-system/model"
+	"unicode/utf8"
 
 	"gorm.io/gorm"
 	"gorm.io/gorm/clause"
@@ -25,39 +27,77 @@ func (r *BookRepository) GetBookByISBN(ctx context.Context, ISBN string) (model.
 	return gorm.G[model.Book](r.db).Where("isbn = ?", ISBN).First(ctx)
 }
 
+// GetExistingISBNs 返回 isbns 中已存在于馆藏的 ISBN。回收站中的图书仍占用 ISBN 唯一索引，一并返回
+func (r *BookRepository) GetExistingISBNs(ctx context.Context, isbns []string) ([]string, error) {
+	var existing []string
+	if len(isbns) == 0 {
+		return existing, nil
+	}
+	err := r.db.WithContext(ctx).Unscoped().Model(&model.Book{}).Where("isbn IN ?", isbns).Pluck("isbn", &existing).Error
+	return existing, err
+}
+
 func (r *BookRepository) GetBookByID(ctx context.Context, id uint64) (model.Book, error) {
 	return gorm.G[model.Book](r.db).Where("id = ?", id).First(ctx)
 }
 
-func (r *BookRepository) CreateBook(ctx context.Context, book *model.Book) error {
-	return gorm.G[model.Book](r.db).Create(ctx, book)
+func (r *BookRepository) CreateBook(ctx context.Context, tx *gorm.DB, book *model.Book) error {
+	return gorm.G[model.Book](tx).Create(ctx, book)
 }
 
-func (r *BookRepository) GetBookList(ctx context.Context, req *request.GetBookListRequest) ([]model.Book, int64, error) {
-	db := r.db.WithContext(ctx).Model(&model.Book{}).Preload("Category")
+// 分面维度，统计某一维度时不应用该维度自身的筛选条件，便于在同级选项间切换
+const (
+	bookFacetNone         = ""
+	bookFacetCategory     = "category"
+	bookFacetAuthor       = "author"
+	bookFacetPublisher    = "publisher"
+	bookFacetYear         = "year"
+	bookFacetAvailability = "availability"
+)
 
+// applyBookListFilters 应用图书列表的筛选条件，skip 指定需要忽略的分面维度
+func applyBookListFilters(db *gorm.DB, req *request.GetBookListRequest, skip string) *gorm.DB {
 	if req.Title != nil {
-		db = db.Where("title LIKE ?", "%"+*req.Title+"%")
+		db = db.Where("books.title LIKE ?", "%"+*req.Title+"%")
 	}
-	if req.Author != nil {
-		db = db.Where("author LIKE ?", "%"+*req.Author+"%")
+	if req.Author != nil && skip != bookFacetAuthor {
+		db = db.Where("books.author LIKE ?", "%"+*req.Author+"%")
 	}
 	if req.ISBN != nil {
-		db = db.Where("isbn = ?", req.ISBN)
+		db = db.Where("books.isbn = ?", req.ISBN)
 	}
-	if req.Publisher != nil {
-		db = db.Where("publisher = ?", req.Publisher)
+	if req.Publisher != nil && skip != bookFacetPublisher {
+		db = db.Where("books.publisher = ?", req.Publisher)
 	}
-	if req.CategoryID != nil {
-		db = db.Where("category_id = ?", req.CategoryID)
+	if skip != bookFacetCategory {
+		if len(req.CategoryIDs) > 0 {
+			db = db.Where("books.category_id IN ?", req.CategoryIDs)
+		} else if req.CategoryID != nil {
+			db = db.Where("books.category_id = ?", req.CategoryID)
+		}
+	}
+	if skip != bookFacetYear {
+		// 按日期区间比较，避免对列做函数运算导致无法走索引
+		if req.YearFrom != nil {
+			db = db.Where("books.publish_date >= ?", fmt.Sprintf("%04d-01-01", *req.YearFrom))
+		}
+		if req.YearTo != nil {
+			db = db.Where("books.publish_date < ?", fmt.Sprintf("%04d-01-01", *req.YearTo+1))
+		}
 	}
-	if req.AvailableOnly != nil {
+	if req.AvailableOnly != nil && skip != bookFacetAvailability {
 		if *req.AvailableOnly {
-			db = db.Where("stock - borrow_count > 0")
+			db = db.Where("books.stock - books.borrow_count > 0")
 		} else {
-			db = db.Where("stock - borrow_count <= 0")
+			db = db.Where("books.stock - books.borrow_count <= 0")
 		}
 	}
+	return db
+}
+
+func (r *BookRepository) GetBookList(ctx context.Context, req *request.GetBookListRequest) ([]model.Book, int64, error) {
+	db := r.db.WithContext(ctx).Model(&model.Book{}).Preload("Category")
+	db = applyBookListFilters(db, req, bookFacetNone)
 
 	var total int64
 	if err := db.Count(&total).Error; err != nil {
@@ -89,15 +129,229 @@ func (r *BookRepository) GetBookList(ctx context.Context, req *request.GetBookLi
 	return books, total, nil
 }
 
-func (r *BookRepository) UpdateBookFields(ctx context.Context, id uint64, fields map[string]interface{}) error {
-	return r.db.WithContext(ctx).Model(model.Book{}).Where("id = ?", id).Updates(fields).Error
+// FacetCount 分面统计项
+type FacetCount struct {
+	Key   string
+	Label string
+	Count int64
+}
+
+// DecadeCount 出版年代统计项，Decade 为年代起始年份
+type DecadeCount struct {
+	Decade int
+	Count  int64
+}
+
+// AvailabilityCount 可借/不可借图书数量
+type AvailabilityCount struct {
+	Available   int64
+	Unavailable int64
+}
+
+// BookFacets 图书列表的分面统计结果
+type BookFacets struct {
+	Categories   []FacetCount
+	Authors      []FacetCount
+	Publishers   []FacetCount
+	Decades      []DecadeCount
+	Availability AvailabilityCount
 }
 
-func (r *BookRepository) DeleteBookByID(ctx context.Context, id uint64) error {
-	_, err := gorm.G[model.Book](r.db).Where("id = ?", id).Delete(ctx)
+// GetBookFacets 基于当前筛选条件统计各分面的图书数量，作者与出版社只返回数量最多的 limit 项
+func (r *BookRepository) GetBookFacets(ctx context.Context, req *request.GetBookListRequest, limit int) (*BookFacets, error) {
+	facets := &BookFacets{}
+	base := func(skip string) *gorm.DB {
+		return applyBookListFilters(r.db.WithContext(ctx).Model(&model.Book{}), req, skip)
+	}
+
+	err := base(bookFacetCategory).
+		Select("CAST(books.category_id AS CHAR) AS `key`, COALESCE(categories.name, '') AS label, COUNT(*) AS count").
+		Joins("LEFT JOIN categories ON categories.id = books.category_id").
+		Group("books.category_id, categories.name").
+		Order("count DESC, books.category_id").
+		Find(&facets.Categories).Error
+	if err != nil {
+		return nil, err
+	}
+
+	err = base(bookFacetAuthor).
+		Select("books.author AS `key`, books.author AS label, COUNT(*) AS count").
+		Group("books.author").
+		Order("count DESC, books.author").
+		Limit(limit).
+		Find(&facets.Authors).Error
+	if err != nil {
+		return nil, err
+	}
+
+	err = base(bookFacetPublisher).
+		Select("books.publisher AS `key`, books.publisher AS label, COUNT(*) AS count").
+		Where("books.publisher <> ''").
+		Group("books.publisher").
+		Order("count DESC, books.publisher").
+		Limit(limit).
+		Find(&facets.Publishers).Error
+	if err != nil {
+		return nil, err
+	}
+
+	err = base(bookFacetYear).
+		Select("FLOOR(YEAR(books.publish_date) / 10) * 10 AS decade, COUNT(*) AS count").
+		Where("books.publish_date IS NOT NULL").
+		Group("decade").
+		Order("decade DESC").
+		Find(&facets.Decades).Error
+	if err != nil {
+		return nil, err
+	}
+
+	err = base(bookFacetAvailability).
+		Select("COALESCE(SUM(books.stock - books.borrow_count > 0), 0) AS available, " +
+			"COALESCE(SUM(books.stock - books.borrow_count <= 0), 0) AS unavailable").
+		Take(&facets.Availability).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return facets, nil
+}
+
+// BookExportRow 导出用的图书行，附带分类名与副本统计
+type BookExportRow struct {
+	model.Book
+	CategoryName    string
+	CopyCount       int64
+	AvailableCopies int64
+}
+
+// StreamBooksForExport 按 ID 游标分批读取全部图书，每批交给 fn 处理，避免一次性载入内存
+func (r *BookRepository) StreamBooksForExport(ctx context.Context, batchSize int, fn func(rows []BookExportRow) error) error {
+	var lastID uint64
+	for {
+		var rows []BookExportRow
+		err := r.db.WithContext(ctx).
+			Table("books").
+			Select("books.*, COALESCE(categories.name, '') AS category_name, "+
+				"(SELECT COUNT(*) FROM book_copies bc WHERE bc.book_id = books.id) AS copy_count, "+
+				"(SELECT COUNT(*) FROM book_copies bc WHERE bc.book_id = books.id AND bc.status = ?) AS available_copies",
+				model.CopyStatusAvailable).
+			Joins("LEFT JOIN categories ON categories.id = books.category_id").
+			Where("books.id > ?", lastID).
+			Order("books.id ASC").
+			Limit(batchSize).
+			Find(&rows).Error
+		if err != nil {
+			return err
+		}
+		if len(rows) == 0 {
+			return nil
+		}
+		if err := fn(rows); err != nil {
+			return err
+		}
+		if len(rows) < batchSize {
+			return nil
+		}
+		lastID = rows[len(rows)-1].ID
+	}
+}
+
+// 全文索引覆盖的列，需与 model.Book 上的 idx_fulltext 保持一致
+const bookFulltextMatch = "MATCH(title, author, publisher, description) AGAINST(? IN NATURAL LANGUAGE MODE)"
+
+// BookSearchHit 检索命中的图书及相关度
+type BookSearchHit struct {
+	ID    uint64
+	Score float64
+}
+
+// SearchBooks 全文检索图书，按相关度排序。
+// ngram 分词的最小长度为 2，单字检索退化为书名/作者模糊匹配
+func (r *BookRepository) SearchBooks(ctx context.Context, req *request.SearchBooksRequest) ([]BookSearchHit, int64, error) {
+	db := r.db.WithContext(ctx).Model(&model.Book{})
+
+	fulltext := utf8.RuneCountInString(req.Q) >= 2
+	if fulltext {
+		db = db.Where(bookFulltextMatch, req.Q)
+	} else {
+		db = db.Where("title LIKE ? OR author LIKE ?", "%"+req.Q+"%", "%"+req.Q+"%")
+	}
+	if req.CategoryID != nil {
+		db = db.Where("category_id = ?", *req.CategoryID)
+	}
+	if req.AvailableOnly != nil && *req.AvailableOnly {
+		db = db.Where("stock - borrow_count > 0")
+	}
+
+	var total int64
+	if err := db.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	if fulltext {
+		db = db.Select("id, "+bookFulltextMatch+" AS score", req.Q).Order("score DESC, id DESC")
+	} else {
+		db = db.Select("id, 0 AS score").Order("id DESC")
+	}
+
+	var hits []BookSearchHit
+	err := db.Offset((req.Page - 1) * req.Limit).Limit(req.Limit).Find(&hits).Error
+	return hits, total, err
+}
+
+// GetBooksByIDs 按 ID 批量获取图书（含分类）
+func (r *BookRepository) GetBooksByIDs(ctx context.Context, ids []uint64) ([]model.Book, error) {
+	var books []model.Book
+	if len(ids) == 0 {
+		return books, nil
+	}
+	err := r.db.WithContext(ctx).Preload("Category").Where("id IN ?", ids).Find(&books).Error
+	return books, err
+}
+
+func (r *BookRepository) UpdateBookFields(ctx context.Context, tx *gorm.DB, id uint64, fields map[string]interface{}) error {
+	return tx.WithContext(ctx).Model(model.Book{}).Where("id = ?", id).Updates(fields).Error
+}
+
+func (r *BookRepository) DeleteBookByID(ctx context.Context, tx *gorm.DB, id uint64) error {
+	_, err := gorm.G[model.Book](tx).Where("id = ?", id).Delete(ctx)
 	return err
 }
 
+// GetTrashedBooks 分页查询回收站中的图书，按删除时间倒序
+func (r *BookRepository) GetTrashedBooks(ctx context.Context, page, limit int) ([]model.Book, int64, error) {
+	db := r.db.WithContext(ctx).Unscoped().Model(&model.Book{}).Where("deleted_at IS NOT NULL")
+
+	var total int64
+	if err := db.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	// Unscoped 会传递给预加载，分类同样被删除时也能取到名称
+	var books []model.Book
+	err := db.Preload("Category").
+		Order("deleted_at DESC, id DESC").
+		Offset((page - 1) * limit).
+		Limit(limit).
+		Find(&books).Error
+	return books, total, err
+}
+
+// GetTrashedBookByID 获取回收站中的图书
+func (r *BookRepository) GetTrashedBookByID(ctx context.Context, id uint64) (model.Book, error) {
+	return gorm.G[model.Book](r.db.Unscoped()).Where("id = ? AND deleted_at IS NOT NULL", id).First(ctx)
+}
+
+// GetTrashedBookByISBN 按 ISBN 获取回收站中的图书
+func (r *BookRepository) GetTrashedBookByISBN(ctx context.Context, ISBN string) (model.Book, error) {
+	return gorm.G[model.Book](r.db.Unscoped()).Where("isbn = ? AND deleted_at IS NOT NULL", ISBN).First(ctx)
+}
+
+// RestoreBook 将图书移出回收站
+func (r *BookRepository) RestoreBook(ctx context.Context, tx *gorm.DB, id uint64) error {
+	return tx.WithContext(ctx).Unscoped().Model(&model.Book{}).Where("id = ?", id).Update("deleted_at", nil).Error
+}
+
 func (r *BookRepository) GetBookByIDWithLock(ctx context.Context, tx *gorm.DB, id uint64) (model.Book, error) {
 	txLock := tx.Clauses(clause.Locking{Strength: "UPDATE"})
 	
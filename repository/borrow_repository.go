@@ -7,6 +7,7 @@ import (
 	"time"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type BorrowRepository struct {
@@ -29,12 +30,55 @@ func (r *BorrowRepository) GetBorrowRecordByID(ctx context.Context, id uint64) (
 	return gorm.G[model.BorrowRecord](r.db).Where("id = ?", id).First(ctx)
 }
 
+// GetBorrowRecordByIDWithLock 在事务中加行锁读取借阅记录，用于还书等需要防止并发重复处理的场景
+func (r *BorrowRepository) GetBorrowRecordByIDWithLock(ctx context.Context, tx *gorm.DB, id uint64) (model.BorrowRecord, error) {
+	txLock := tx.Clauses(clause.Locking{Strength: "UPDATE"})
+	return gorm.G[model.BorrowRecord](txLock).Where("id = ?", id).First(ctx)
+}
+
+// GetActiveBorrowByCopyID 获取副本当前的在借记录
+func (r *BorrowRepository) GetActiveBorrowByCopyID(ctx context.Context, copyID uint64) (model.BorrowRecord, error) {
+	return gorm.G[model.BorrowRecord](r.db).Where("copy_id = ? AND status IN ?", copyID, []string{"borrowed", "overdue"}).First(ctx)
+}
+
 func (r *BorrowRepository) GetAllDueRecord(ctx context.Context, tx *gorm.DB, now time.Time) ([]model.BorrowRecord, error) {
-	return gorm.G[model.BorrowRecord](tx).Where("status = ? AND return_date IS NULL AND due_date < ?","borrowed", now).Find(ctx)
+	return gorm.G[model.BorrowRecord](tx).Where("status = ? AND return_date IS NULL AND due_date < ?","borrowed", now).
+		Preload("Book", func(db gorm.PreloadBuilder) error {return nil}).
+		Preload("User", func(db gorm.PreloadBuilder) error {return nil}).Find(ctx)
 }
 
 func (r *BorrowRepository) GetDueRecordByUserID(ctx context.Context, tx *gorm.DB, id uint64, now time.Time) ([]model.BorrowRecord, error) {
-	return gorm.G[model.BorrowRecord](tx).Where("user_id = ? AND status = ? AND return_date IS NULL AND due_date < ?", id, "borrowed", now).Find(ctx)
+	return gorm.G[model.BorrowRecord](tx).Where("user_id = ? AND status = ? AND return_date IS NULL AND due_date < ?", id, "borrowed", now).
+		Preload("Book", func(db gorm.PreloadBuilder) error {return nil}).
+		Preload("User", func(db gorm.PreloadBuilder) error {return nil}).Find(ctx)
+}
+
+// CountActiveBorrows 统计用户未归还的借阅数量，指定分类时只统计该分类下的图书
+func (r *BorrowRepository) CountActiveBorrows(ctx context.Context, tx *gorm.DB, userID uint64, categoryID *uint) (int64, error) {
+	db := tx.WithContext(ctx).Model(&model.BorrowRecord{}).
+		Where("borrow_records.user_id = ? AND borrow_records.status IN ?", userID, []string{"borrowed", "overdue"})
+	if categoryID != nil {
+		db = db.Joins("JOIN books b ON b.id = borrow_records.book_id").Where("b.category_id = ?", *categoryID)
+	}
+
+	var count int64
+	err := db.Count(&count).Error
+	return count, err
+}
+
+// GetDueSoonUnremindedRecords 获取在 until 之前到期且尚未提醒的在借记录
+func (r *BorrowRepository) GetDueSoonUnremindedRecords(ctx context.Context, now, until time.Time) ([]model.BorrowRecord, error) {
+	return gorm.G[model.BorrowRecord](r.db).Where("status = ? AND return_date IS NULL AND reminded_at IS NULL AND due_date >= ? AND due_date <= ?", "borrowed", now, until).
+		Preload("Book", func(db gorm.PreloadBuilder) error {return nil}).
+		Preload("User", func(db gorm.PreloadBuilder) error {return nil}).Find(ctx)
+}
+
+// MarkReminded 标记已发送到期提醒，仅当记录仍在借且未提醒过时生效，返回更新的行数
+func (r *BorrowRepository) MarkReminded(ctx context.Context, tx *gorm.DB, id uint64, now time.Time) (int64, error) {
+	result := tx.WithContext(ctx).Model(&model.BorrowRecord{}).
+		Where("id = ? AND return_date IS NULL AND reminded_at IS NULL", id).
+		Update("reminded_at", now)
+	return result.RowsAffected, result.Error
 }
 
 func (r *BorrowRepository) UpdateFields(ctx context.Context, tx *gorm.DB, id uint64, fields map[string]interface{}) error {
@@ -44,8 +88,9 @@ func (r *BorrowRepository) UpdateFields(ctx context.Context, tx *gorm.DB, id uin
 func (r *BorrowRepository) GetBorrowRecordByIDWithBook(ctx context.Context, id uint64) (model.BorrowRecord, error) {
 	return gorm.G[model.BorrowRecord](r.db).Where("id = ?", id).Preload("Book", func(db gorm.PreloadBuilder) error {return nil}).First(ctx)
 }
+// GetBorrowRecordList 分页查询借阅记录，已删除的图书和用户仍会被预加载
 func (r *BorrowRepository) GetBorrowRecordList(ctx context.Context, req *request.GetBorrowRecordListRequest) ([]model.BorrowRecord, int64, error) {
-	db := r.db.WithContext(ctx).Model(model.BorrowRecord{}).Preload("Book").Preload("User")
+	db := r.db.WithContext(ctx).Unscoped().Model(model.BorrowRecord{}).Preload("Book").Preload("User")
 
 	if req.UserID != nil {
 		db = db.Where("user_id = ?", *req.UserID)
@@ -105,7 +150,7 @@ func (r *BorrowRepository) GetBorrowRecordList(ctx context.Context, req *request
 }
 
 func (r *BorrowRepository) GetRecordByUserIDWithPreload(ctx context.Context, userID uint64) ([]model.BorrowRecord, error){
-	return gorm.G[model.BorrowRecord](r.db).Where("user_id = ?", userID).
+	return gorm.G[model.BorrowRecord](r.db.Unscoped()).Where("user_id = ?", userID).
 	Preload("Book", func(db gorm.PreloadBuilder) error {return nil}).
 	Preload("User", func(db gorm.PreloadBuilder) error {return nil}).Find(ctx)
 }
\ No newline at end of file
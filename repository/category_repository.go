@@ -66,16 +66,16 @@ func (r *CategoryRepository) GetBookCountByCategoryID(ctx context.Context, categ
 	return count, err
 }
 
-func (r *CategoryRepository) CreateCategory(ctx context. Context, category *model.Category) error {
-	return r.db.WithContext(ctx).Create(category).Error
+func (r *CategoryRepository) CreateCategory(ctx context. Context, tx *gorm.DB, category *model.Category) error {
+	return tx.WithContext(ctx).Create(category).Error
 }
 
-func (r *CategoryRepository) UpdateCategory(ctx context. Context, id uint, updates map[string]interface{}) error {
-	return r.db.WithContext(ctx).Model(&model.Category{}).Where("id = ?", id).Updates(updates).Error
+func (r *CategoryRepository) UpdateCategory(ctx context. Context, tx *gorm.DB, id uint, updates map[string]interface{}) error {
+	return tx.WithContext(ctx).Model(&model.Category{}).Where("id = ?", id).Updates(updates).Error
 }
 
-func (r *CategoryRepository) DeleteCategory(ctx context.Context, id uint) error {
-	return r.db.WithContext(ctx).Delete(&model.Category{}, id).Error
+func (r *CategoryRepository) DeleteCategory(ctx context.Context, tx *gorm.DB, id uint) error {
+	return tx.WithContext(ctx).Delete(&model.Category{}, id).Error
 }
 
 func (r *CategoryRepository) HasBooks(ctx context.Context, categoryID uint) (bool, int64, error) {
@@ -88,4 +88,74 @@ func (r *CategoryRepository) HasChildren(ctx context.Context, categoryID uint) (
 	var count int64
 	err := r.db. WithContext(ctx).Model(&model.Category{}).Where("parent_id = ?", categoryID).Count(&count).Error
 	return count > 0, err
-}
\ No newline at end of file
+}
+// GetBookCountsByCategory 按分类统计直属图书数量
+func (r *CategoryRepository) GetBookCountsByCategory(ctx context.Context) (map[uint]int64, error) {
+	var rows []struct {
+		CategoryID uint
+		Count      int64
+	}
+	err := r.db.WithContext(ctx).Model(&model.Book{}).
+		Select("category_id, COUNT(*) AS count").
+		Group("category_id").
+		Find(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	counts := make(map[uint]int64, len(rows))
+	for _, row := range rows {
+		counts[row.CategoryID] = row.Count
+	}
+	return counts, nil
+}
+
+// GetDescendantIDs 获取分类及其所有后代分类的 ID，结果包含 id 本身
+func (r *CategoryRepository) GetDescendantIDs(ctx context.Context, id uint) ([]uint, error) {
+	categories, err := r.GetCategoryList(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	children := make(map[uint][]uint, len(categories))
+	for _, cat := range categories {
+		if cat.ParentID != nil {
+			children[*cat.ParentID] = append(children[*cat.ParentID], cat.ID)
+		}
+	}
+
+	// 广度优先遍历，visited 防止历史脏数据中的环导致死循环
+	ids := []uint{id}
+	visited := map[uint]bool{id: true}
+	for i := 0; i < len(ids); i++ {
+		for _, child := range children[ids[i]] {
+			if !visited[child] {
+				visited[child] = true
+				ids = append(ids, child)
+			}
+		}
+	}
+	return ids, nil
+}
+
+// GetTrashedCategories 获取回收站中的分类，按删除时间倒序
+func (r *CategoryRepository) GetTrashedCategories(ctx context.Context) ([]model.Category, error) {
+	var categories []model.Category
+	err := r.db.WithContext(ctx).Unscoped().
+		Where("deleted_at IS NOT NULL").
+		Order("deleted_at DESC, id DESC").
+		Find(&categories).Error
+	return categories, err
+}
+
+// GetTrashedCategoryByID 获取回收站中的分类
+func (r *CategoryRepository) GetTrashedCategoryByID(ctx context.Context, id uint) (model.Category, error) {
+	var category model.Category
+	err := r.db.WithContext(ctx).Unscoped().Where("id = ? AND deleted_at IS NOT NULL", id).First(&category).Error
+	return category, err
+}
+
+// RestoreCategory 将分类移出回收站
+func (r *CategoryRepository) RestoreCategory(ctx context.Context, tx *gorm.DB, id uint) error {
+	return tx.WithContext(ctx).Unscoped().Model(&model.Category{}).Where("id = ?", id).Update("deleted_at", nil).Error
+}
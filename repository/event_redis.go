@@ -0,0 +1,34 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	UserEventChannelPrefix = "events:user:"       // 用户个人事件频道前缀
+	CirculationChannel     = "events:circulation" // 管理员可见的全部流通事件
+)
+
+// EventRdb 基于 Redis 发布订阅的事件通道，多实例部署时各实例都能收到
+type EventRdb struct {
+	rdb *redis.Client
+}
+
+func NewEventRdb(rdb *redis.Client) *EventRdb {
+	return &EventRdb{rdb: rdb}
+}
+
+func UserEventChannel(userID uint64) string {
+	return fmt.Sprintf("%s%d", UserEventChannelPrefix, userID)
+}
+
+func (r *EventRdb) Publish(ctx context.Context, channel string, payload []byte) error {
+	return r.rdb.Publish(ctx, channel, payload).Err()
+}
+
+func (r *EventRdb) Subscribe(ctx context.Context, channels ...string) *redis.PubSub {
+	return r.rdb.Subscribe(ctx, channels...)
+}
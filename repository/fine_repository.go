@@ -0,0 +1,78 @@
+package repository
+
+import (
+	"context"
+	"library-system/model"
+
+	"gorm.io/gorm"
+)
+
+type FineRepository struct {
+	db *gorm.DB
+}
+
+func NewFineRepository(db *gorm.DB) *FineRepository {
+	return &FineRepository{db: db}
+}
+
+func (r *FineRepository) DB() *gorm.DB {
+	return r.db
+}
+
+func (r *FineRepository) CreateTransaction(ctx context.Context, tx *gorm.DB, t *model.FineTransaction) error {
+	return gorm.G[model.FineTransaction](tx).Create(ctx, t)
+}
+
+// GetBalance 汇总用户全部流水得到当前欠款
+func (r *FineRepository) GetBalance(ctx context.Context, tx *gorm.DB, userID uint64) (float64, error) {
+	var balance float64
+	err := tx.WithContext(ctx).Model(&model.FineTransaction{}).
+		Select("COALESCE(SUM(amount), 0)").
+		Where("user_id = ?", userID).
+		Scan(&balance).Error
+	return balance, err
+}
+
+func (r *FineRepository) GetTransactionsByUserID(ctx context.Context, userID uint64, page, limit int) ([]model.FineTransaction, int64, error) {
+	db := r.db.WithContext(ctx).Model(&model.FineTransaction{}).Where("user_id = ?", userID)
+
+	var total int64
+	if err := db.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var transactions []model.FineTransaction
+	err := db.Order("id DESC").Offset((page - 1) * limit).Limit(limit).Find(&transactions).Error
+	return transactions, total, err
+}
+
+// BackfillReturnedCharges 为启用流水前已归还且有罚金的借阅记录补记罚金
+func (r *FineRepository) BackfillReturnedCharges(ctx context.Context) (int, error) {
+	var records []model.BorrowRecord
+	err := r.db.WithContext(ctx).
+		Where("status = ? AND fine > 0", "returned").
+		Where("NOT EXISTS (SELECT 1 FROM fine_transactions ft WHERE ft.borrow_id = borrow_records.id AND ft.type = ?)", model.FineTypeCharge).
+		Find(&records).Error
+	if err != nil {
+		return 0, err
+	}
+
+	err = r.db.Transaction(func(tx *gorm.DB) error {
+		for _, record := range records {
+			borrowID := record.ID
+			t := model.FineTransaction{
+				UserID:   record.UserID,
+				BorrowID: &borrowID,
+				Type:     model.FineTypeCharge,
+				Amount:   record.Fine,
+				Reason:   "历史借阅罚金",
+			}
+			if err := r.CreateTransaction(ctx, tx, &t); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+
+	return len(records), err
+}
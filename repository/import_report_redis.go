@@ -0,0 +1,31 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	ImportReportPrefix = "import_report:" // 批量导入错误报告前缀
+	ImportReportTTL    = 24 * time.Hour
+)
+
+// ImportReportRdb 暂存批量导入的错误报告，供管理员在有效期内下载
+type ImportReportRdb struct {
+	rdb *redis.Client
+}
+
+func NewImportReportRdb(rdb *redis.Client) *ImportReportRdb {
+	return &ImportReportRdb{rdb: rdb}
+}
+
+func (r *ImportReportRdb) SaveReport(ctx context.Context, reportID string, data []byte) error {
+	return r.rdb.Set(ctx, ImportReportPrefix+reportID, data, ImportReportTTL).Err()
+}
+
+// GetReport 报告不存在或已过期时返回 redis.Nil
+func (r *ImportReportRdb) GetReport(ctx context.Context, reportID string) ([]byte, error) {
+	return r.rdb.Get(ctx, ImportReportPrefix+reportID).Bytes()
+}
@@ -0,0 +1,71 @@
+package repository
+
+import (
+	"context"
+	"library-system/model"
+
+	"gorm.io/gorm"
+)
+
+type LoanPolicyRepository struct {
+	db *gorm.DB
+}
+
+func NewLoanPolicyRepository(db *gorm.DB) *LoanPolicyRepository {
+	return &LoanPolicyRepository{db: db}
+}
+
+func (r *LoanPolicyRepository) DB() *gorm.DB {
+	return r.db
+}
+
+func (r *LoanPolicyRepository) CreatePolicy(ctx context.Context, tx *gorm.DB, policy *model.LoanPolicy) error {
+	return gorm.G[model.LoanPolicy](tx).Create(ctx, policy)
+}
+
+func (r *LoanPolicyRepository) GetPolicyByID(ctx context.Context, id uint64) (model.LoanPolicy, error) {
+	return gorm.G[model.LoanPolicy](r.db).Where("id = ?", id).First(ctx)
+}
+
+func (r *LoanPolicyRepository) GetPolicyList(ctx context.Context) ([]model.LoanPolicy, error) {
+	var policies []model.LoanPolicy
+	err := r.db.WithContext(ctx).Order("id ASC").Find(&policies).Error
+	return policies, err
+}
+
+// GetPolicyByKey 按（角色, 分类）精确查找，nil 匹配通配规则
+func (r *LoanPolicyRepository) GetPolicyByKey(ctx context.Context, role *string, categoryID *uint) (model.LoanPolicy, error) {
+	db := r.db.WithContext(ctx)
+	if role != nil {
+		db = db.Where("role = ?", *role)
+	} else {
+		db = db.Where("role IS NULL")
+	}
+	if categoryID != nil {
+		db = db.Where("category_id = ?", *categoryID)
+	} else {
+		db = db.Where("category_id IS NULL")
+	}
+
+	var policy model.LoanPolicy
+	err := db.First(&policy).Error
+	return policy, err
+}
+
+// GetCandidatePolicies 获取可能适用于该角色和分类的所有规则
+func (r *LoanPolicyRepository) GetCandidatePolicies(ctx context.Context, role string, categoryID uint) ([]model.LoanPolicy, error) {
+	var policies []model.LoanPolicy
+	err := r.db.WithContext(ctx).
+		Where("role = ? OR role IS NULL", role).
+		Where("category_id = ? OR category_id IS NULL", categoryID).
+		Find(&policies).Error
+	return policies, err
+}
+
+func (r *LoanPolicyRepository) UpdatePolicyFields(ctx context.Context, tx *gorm.DB, id uint64, fields map[string]interface{}) error {
+	return tx.WithContext(ctx).Model(&model.LoanPolicy{}).Where("id = ?", id).Updates(fields).Error
+}
+
+func (r *LoanPolicyRepository) DeletePolicy(ctx context.Context, tx *gorm.DB, id uint64) error {
+	return tx.WithContext(ctx).Delete(&model.LoanPolicy{}, id).Error
+}
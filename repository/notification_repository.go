@@ -0,0 +1,97 @@
+package repository
+
+import (
+	"context"
+	"library-system/model"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type NotificationRepository struct {
+	db *gorm.DB
+}
+
+func NewNotificationRepository(db *gorm.DB) *NotificationRepository {
+	return &NotificationRepository{db: db}
+}
+
+func (r *NotificationRepository) DB() *gorm.DB {
+	return r.db
+}
+
+func (r *NotificationRepository) CreateOutbox(ctx context.Context, tx *gorm.DB, entries []model.NotificationOutbox) error {
+	if len(entries) == 0 {
+		return nil
+	}
+	return tx.WithContext(ctx).Create(&entries).Error
+}
+
+// GetDueOutbox 获取到期待发送的通知
+func (r *NotificationRepository) GetDueOutbox(ctx context.Context, now time.Time, limit int) ([]model.NotificationOutbox, error) {
+	var entries []model.NotificationOutbox
+	err := r.db.WithContext(ctx).
+		Where("status = ? AND next_attempt_at <= ?", model.OutboxStatusPending, now).
+		Order("next_attempt_at ASC, id ASC").
+		Limit(limit).
+		Find(&entries).Error
+	return entries, err
+}
+
+func (r *NotificationRepository) UpdateOutboxFields(ctx context.Context, id uint64, fields map[string]interface{}) error {
+	return r.db.WithContext(ctx).Model(&model.NotificationOutbox{}).Where("id = ?", id).Updates(fields).Error
+}
+
+// ClaimOutbox 将通知的下次发送时间推后以占用该条记录，避免多个实例重复发送
+func (r *NotificationRepository) ClaimOutbox(ctx context.Context, id uint64, now, leaseUntil time.Time) (bool, error) {
+	result := r.db.WithContext(ctx).Model(&model.NotificationOutbox{}).
+		Where("id = ? AND status = ? AND next_attempt_at <= ?", id, model.OutboxStatusPending, now).
+		Update("next_attempt_at", leaseUntil)
+	return result.RowsAffected == 1, result.Error
+}
+
+func (r *NotificationRepository) CreateNotification(ctx context.Context, tx *gorm.DB, n *model.Notification) error {
+	return gorm.G[model.Notification](tx).Create(ctx, n)
+}
+
+func (r *NotificationRepository) GetNotificationList(ctx context.Context, userID uint64, unreadOnly bool, page, limit int) ([]model.Notification, int64, error) {
+	db := r.db.WithContext(ctx).Model(&model.Notification{}).Where("user_id = ?", userID)
+	if unreadOnly {
+		db = db.Where("read_at IS NULL")
+	}
+
+	var total int64
+	if err := db.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var notifications []model.Notification
+	err := db.Order("id DESC").Offset((page - 1) * limit).Limit(limit).Find(&notifications).Error
+	return notifications, total, err
+}
+
+func (r *NotificationRepository) CountUnread(ctx context.Context, userID uint64) (int64, error) {
+	var count int64
+	err := r.db.WithContext(ctx).Model(&model.Notification{}).
+		Where("user_id = ? AND read_at IS NULL", userID).
+		Count(&count).Error
+	return count, err
+}
+
+func (r *NotificationRepository) GetNotificationByID(ctx context.Context, id uint64) (model.Notification, error) {
+	return gorm.G[model.Notification](r.db).Where("id = ?", id).First(ctx)
+}
+
+// MarkRead 标记单条消息已读，已读过的保持原已读时间
+func (r *NotificationRepository) MarkRead(ctx context.Context, id uint64, now time.Time) error {
+	return r.db.WithContext(ctx).Model(&model.Notification{}).
+		Where("id = ? AND read_at IS NULL", id).
+		Update("read_at", now).Error
+}
+
+func (r *NotificationRepository) MarkAllRead(ctx context.Context, userID uint64, now time.Time) (int64, error) {
+	result := r.db.WithContext(ctx).Model(&model.Notification{}).
+		Where("user_id = ? AND read_at IS NULL", userID).
+		Update("read_at", now)
+	return result.RowsAffected, result.Error
+}
@@ -25,9 +25,9 @@ func (r *ReservationRepository) CreateReservation(ctx context.Context, reservati
     return gorm.G[model.Reservation](r.db).Create(ctx, reservation)
 }
 
-// GetReservationByID 根据ID获取预约
+// GetReservationByID 根据ID获取预约，已删除的图书和用户仍会被预加载
 func (r *ReservationRepository) GetReservationByID(ctx context.Context, id uint64) (model.Reservation, error) {
-    return gorm.G[model.Reservation](r.db).Where("id = ?", id).
+    return gorm.G[model.Reservation](r.db.Unscoped()).Where("id = ?", id).
 	Preload("Book", func(db gorm.PreloadBuilder) error {return nil}).
 	Preload("User", func(db gorm.PreloadBuilder) error {return nil}).
 	First(ctx)
@@ -72,6 +72,8 @@ func (r *ReservationRepository) GetQueuePosition(ctx context.Context, reservatio
 func (r *ReservationRepository) GetNextWaitingReservation(ctx context. Context, bookID uint64) (*model.Reservation, error) {
     var reservation model.Reservation
     err := r.db.WithContext(ctx).
+        Preload("Book").
+        Preload("User").
         Where("book_id = ? AND status = ? ", bookID, model.ReservationStatusWaiting).
         Order("reserved_at ASC"). // 先预约先得
         First(&reservation).Error
@@ -80,6 +82,9 @@ func (r *ReservationRepository) GetNextWaitingReservation(ctx context. Context,
 
 // UpdateReservationStatus 更新预约状态
 func (r *ReservationRepository) UpdateReservationStatus(ctx context.Context, tx *gorm.DB, id uint64, updates map[string]interface{}) error {
+    if tx == nil {
+        tx = r.db
+    }
     return tx.WithContext(ctx).
         Model(&model.Reservation{}).
         Where("id = ? ", id).
@@ -92,6 +97,8 @@ func (r *ReservationRepository) GetExpiredReservations(ctx context.Context) ([]m
     now := time.Now()
     
     err := r.db.WithContext(ctx).
+        Preload("Book").
+        Preload("User").
         Where("status = ? AND expires_at < ? ", model.ReservationStatusAvailable, now).
         Find(&reservations).Error
     
@@ -0,0 +1,91 @@
+package repository
+
+import (
+	"context"
+	"library-system/model"
+
+	"gorm.io/gorm"
+)
+
+type ReviewRepository struct {
+	db *gorm.DB
+}
+
+func NewReviewRepository(db *gorm.DB) *ReviewRepository {
+	return &ReviewRepository{db: db}
+}
+
+func (r *ReviewRepository) DB() *gorm.DB {
+	return r.db
+}
+
+// CreateReview 同一用户重复评价同一图书时违反唯一索引 idx_book_user，返回 gorm.ErrDuplicatedKey
+func (r *ReviewRepository) CreateReview(ctx context.Context, tx *gorm.DB, review *model.Review) error {
+	err := gorm.G[model.Review](tx).Create(ctx, review)
+	if translator, ok := tx.Dialector.(gorm.ErrorTranslator); ok && err != nil {
+		return translator.Translate(err)
+	}
+	return err
+}
+
+func (r *ReviewRepository) GetReviewByID(ctx context.Context, id uint64) (model.Review, error) {
+	return gorm.G[model.Review](r.db.Unscoped()).Where("id = ?", id).
+		Preload("User", nil).
+		First(ctx)
+}
+
+func (r *ReviewRepository) GetUserReviewForBook(ctx context.Context, userID, bookID uint64) (model.Review, error) {
+	return gorm.G[model.Review](r.db).Where("user_id = ? AND book_id = ?", userID, bookID).First(ctx)
+}
+
+// GetReviewList 分页查询评价，bookID、status 为空时不过滤。已删除用户的评价仍显示其用户名
+func (r *ReviewRepository) GetReviewList(ctx context.Context, bookID *uint64, status *string, page, limit int) ([]model.Review, int64, error) {
+	db := r.db.WithContext(ctx).Unscoped().Model(&model.Review{})
+	if bookID != nil {
+		db = db.Where("book_id = ?", *bookID)
+	}
+	if status != nil {
+		db = db.Where("status = ?", *status)
+	}
+
+	var total int64
+	if err := db.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var reviews []model.Review
+	err := db.Preload("User").Preload("Book").
+		Order("id DESC").
+		Offset((page - 1) * limit).
+		Limit(limit).
+		Find(&reviews).Error
+	return reviews, total, err
+}
+
+func (r *ReviewRepository) UpdateReviewFields(ctx context.Context, tx *gorm.DB, id uint64, fields map[string]interface{}) error {
+	return tx.WithContext(ctx).Model(&model.Review{}).Where("id = ?", id).Updates(fields).Error
+}
+
+func (r *ReviewRepository) DeleteReview(ctx context.Context, tx *gorm.DB, id uint64) error {
+	return tx.WithContext(ctx).Delete(&model.Review{}, id).Error
+}
+
+// HasReturnedBook 用户是否归还过该图书
+func (r *ReviewRepository) HasReturnedBook(ctx context.Context, userID, bookID uint64) (bool, error) {
+	var count int64
+	err := r.db.WithContext(ctx).Model(&model.BorrowRecord{}).
+		Where("user_id = ? AND book_id = ? AND status = ?", userID, bookID, "returned").
+		Count(&count).Error
+	return count > 0, err
+}
+
+// RecalcBookRating 按可见评价重新计算图书评分和评价数
+func (r *ReviewRepository) RecalcBookRating(ctx context.Context, tx *gorm.DB, bookID uint64) error {
+	visible := tx.Model(&model.Review{}).Where("book_id = ? AND status = ?", bookID, model.ReviewStatusVisible)
+
+	return tx.WithContext(ctx).Model(&model.Book{}).Where("id = ?", bookID).
+		UpdateColumns(map[string]interface{}{
+			"rating":       visible.Session(&gorm.Session{}).Select("COALESCE(ROUND(AVG(rating), 2), 0)"),
+			"review_count": visible.Session(&gorm.Session{}).Select("COUNT(*)"),
+		}).Error
+}
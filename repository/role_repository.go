@@ -0,0 +1,96 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"library-system/model"
+
+	"gorm.io/gorm"
+)
+
+type RoleRepository struct {
+	db *gorm.DB
+}
+
+func NewRoleRepository(db *gorm.DB) *RoleRepository {
+	return &RoleRepository{db: db}
+}
+
+func (r *RoleRepository) DB() *gorm.DB {
+	return r.db
+}
+
+func (r *RoleRepository) CreateRole(ctx context.Context, tx *gorm.DB, role *model.Role) error {
+	return gorm.G[model.Role](tx).Create(ctx, role)
+}
+
+func (r *RoleRepository) GetRoleByID(ctx context.Context, id uint64) (model.Role, error) {
+	return gorm.G[model.Role](r.db).Preload("Permissions", nil).Where("id = ?", id).First(ctx)
+}
+
+func (r *RoleRepository) GetRoleByName(ctx context.Context, name string) (model.Role, error) {
+	return gorm.G[model.Role](r.db).Preload("Permissions", nil).Where("name = ?", name).First(ctx)
+}
+
+func (r *RoleRepository) GetRoleList(ctx context.Context) ([]model.Role, error) {
+	var roles []model.Role
+	err := r.db.WithContext(ctx).Preload("Permissions").Order("id ASC").Find(&roles).Error
+	return roles, err
+}
+
+func (r *RoleRepository) UpdateRoleFields(ctx context.Context, tx *gorm.DB, id uint64, fields map[string]interface{}) error {
+	return tx.WithContext(ctx).Model(&model.Role{}).Where("id = ?", id).Updates(fields).Error
+}
+
+// ReplacePermissions 用 permissions 整体替换角色已授予的权限
+func (r *RoleRepository) ReplacePermissions(ctx context.Context, tx *gorm.DB, roleID uint64, permissions []string) error {
+	if err := tx.WithContext(ctx).Where("role_id = ?", roleID).Delete(&model.RolePermission{}).Error; err != nil {
+		return err
+	}
+	if len(permissions) == 0 {
+		return nil
+	}
+
+	grants := make([]model.RolePermission, 0, len(permissions))
+	for _, p := range permissions {
+		grants = append(grants, model.RolePermission{RoleID: roleID, Permission: p})
+	}
+	return tx.WithContext(ctx).Create(&grants).Error
+}
+
+func (r *RoleRepository) DeleteRole(ctx context.Context, tx *gorm.DB, id uint64) error {
+	if err := tx.WithContext(ctx).Where("role_id = ?", id).Delete(&model.RolePermission{}).Error; err != nil {
+		return err
+	}
+	return tx.WithContext(ctx).Delete(&model.Role{}, id).Error
+}
+
+// CountUsersWithRole 统计使用该角色的用户数，包含回收站中的用户，避免恢复后角色不存在
+func (r *RoleRepository) CountUsersWithRole(ctx context.Context, name string) (int64, error) {
+	var count int64
+	err := r.db.WithContext(ctx).Unscoped().Model(&model.User{}).Where("role = ?", name).Count(&count).Error
+	return count, err
+}
+
+// SeedBuiltinRoles 写入缺失的内置角色及其初始权限，已存在的角色保持不变，返回新建的数量
+func (r *RoleRepository) SeedBuiltinRoles(ctx context.Context) (int, error) {
+	created := 0
+	for _, builtin := range model.BuiltinRoles {
+		_, err := r.GetRoleByName(ctx, builtin.Name)
+		if err == nil {
+			continue
+		}
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return created, err
+		}
+
+		role := builtin
+		role.Builtin = true
+		role.Permissions = append([]model.RolePermission(nil), builtin.Permissions...)
+		if err := r.db.WithContext(ctx).Create(&role).Error; err != nil {
+			return created, err
+		}
+		created++
+	}
+	return created, nil
+}
@@ -265,7 +265,7 @@ type PopularBook struct {
 	Rating      float64
 }
 
-func (r *StatsRepository) GetPopularBooks(ctx context.Context, limit int, startDate *time.Time, categoryID *uint) ([]PopularBook, error) {
+func (r *StatsRepository) GetPopularBooks(ctx context.Context, limit int, startDate *time.Time, categoryIDs []uint) ([]PopularBook, error) {
 	var results []PopularBook
 
 	query := r.db.WithContext(ctx).
@@ -277,8 +277,8 @@ func (r *StatsRepository) GetPopularBooks(ctx context.Context, limit int, startD
 		query = query.Where("br.borrow_date >= ?", startDate)
 	}
 
-	if categoryID != nil {
-		query = query.Where("b.category_id = ?", *categoryID)
+	if len(categoryIDs) > 0 {
+		query = query.Where("b.category_id IN ?", categoryIDs)
 	}
 
 	err := query.
@@ -310,10 +310,97 @@ func (r *StatsRepository) GetCategoryStats(ctx context.Context) ([]CategoryStats
 			COUNT(DISTINCT b.id) as book_count,
 			COALESCE(SUM(b.borrow_count), 0) as borrow_count
 		`).
-		Joins("LEFT JOIN books b ON c.id = b.category_id").
+		Joins("LEFT JOIN books b ON c.id = b.category_id AND b.deleted_at IS NULL").
+		Where("c.deleted_at IS NULL").
 		Group("c.id, c. name").
 		Order("borrow_count DESC").
 		Scan(&results).Error
 
 	return results, err
 }
+
+
+// ========== 损坏/遗失报表 ==========
+
+type DamagedItem struct {
+	BorrowID        uint64
+	BookID          uint64
+	Title           string
+	ISBN            string
+	CopyID          *uint64
+	Barcode         string
+	UserID          uint64
+	Username        string
+	Condition       string
+	Remark          string
+	CompensationFee float64
+	ReturnDate      time.Time
+}
+
+type DamagedItemsSummary struct {
+	DamagedCount      int64
+	LostCount         int64
+	TotalCompensation float64
+}
+
+func (r *StatsRepository) damagedItemsQuery(ctx context.Context, condition *string, startDate, endDate *time.Time) *gorm.DB {
+	query := r.db.WithContext(ctx).
+		Table("borrow_records br").
+		Where("br.return_condition IN ?", []string{model.ReturnConditionDamaged, model.ReturnConditionLost})
+
+	if condition != nil {
+		query = query.Where("br.return_condition = ?", *condition)
+	}
+	if startDate != nil {
+		query = query.Where("br.return_date >= ?", *startDate)
+	}
+	if endDate != nil {
+		query = query.Where("br.return_date <= ?", *endDate)
+	}
+	return query
+}
+
+func (r *StatsRepository) GetDamagedItems(ctx context.Context, condition *string, startDate, endDate *time.Time, page, limit int) ([]DamagedItem, int64, error) {
+	var total int64
+	if err := r.damagedItemsQuery(ctx, condition, startDate, endDate).Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var results []DamagedItem
+	err := r.damagedItemsQuery(ctx, condition, startDate, endDate).
+		Select(`
+			br.id as borrow_id,
+			br.book_id,
+			b.title,
+			b.isbn,
+			br.copy_id,
+			COALESCE(bc.barcode, '') as barcode,
+			br.user_id,
+			u.username,
+			br.return_condition as ` + "`condition`" + `,
+			br.return_remark as remark,
+			br.compensation_fee,
+			br.return_date
+		`).
+		Joins("JOIN books b ON br.book_id = b.id").
+		Joins("JOIN users u ON br.user_id = u.id").
+		Joins("LEFT JOIN book_copies bc ON br.copy_id = bc.id").
+		Order("br.return_date DESC").
+		Offset((page - 1) * limit).
+		Limit(limit).
+		Scan(&results).Error
+
+	return results, total, err
+}
+
+func (r *StatsRepository) GetDamagedItemsSummary(ctx context.Context, condition *string, startDate, endDate *time.Time) (DamagedItemsSummary, error) {
+	var summary DamagedItemsSummary
+	err := r.damagedItemsQuery(ctx, condition, startDate, endDate).
+		Select(`
+			COALESCE(SUM(br.return_condition = 'damaged'), 0) as damaged_count,
+			COALESCE(SUM(br.return_condition = 'lost'), 0) as lost_count,
+			COALESCE(SUM(br.compensation_fee), 0) as total_compensation
+		`).
+		Scan(&summary).Error
+	return summary, err
+}
@@ -17,9 +17,40 @@ var Rdb *TokenRdb
 const (
 	RefreshTokenPrefix = "refresh_token:"  // Refresh Token 前缀
 	BlacklistPrefix    = "blacklist:"      // 黑名单前缀
+	StreamTicketPrefix = "stream_ticket:"  // stream_ticket:{票据哈希} -> 建立事件流所用的 Access Token 信息
 	RefreshTokenTTL    = 7 * 24 * time. Hour // 7天
 )
 
+// 每个会话即一个 Token 家族：刷新时轮换出的 Token 都属于同一会话，
+// 已轮换的 Refresh Token 再次出现说明可能被盗用，整个家族随之吊销
+const (
+	SessionPrefix       = "session:"        // session:{userID}:{sessionID} 会话信息（Hash）
+	UserSessionsPrefix  = "user_sessions:"  // user_sessions:{userID} 用户的会话 ID 集合
+	SessionTokensPrefix = "session_tokens:" // session_tokens:{userID}:{sessionID} 会话签发过的全部 TokenID
+	RotatedTokenPrefix  = "rotated_token:"  // rotated_token:{userID}:{tokenID} -> 已轮换 Refresh Token 所属的会话 ID
+)
+
+// Session 登录会话，对应一台设备上的一次登录，刷新 Token 时保持同一 ID
+type Session struct {
+	ID         string `redis:"id"`
+	TokenID    string `redis:"token_id"` // 当前有效的 Refresh Token，与 Access Token 共用
+	UserAgent  string `redis:"user_agent"`
+	IP         string `redis:"ip"`
+	CreatedAt  int64  `redis:"created_at"`   // Unix 秒
+	LastUsedAt int64  `redis:"last_used_at"` // 最近一次登录或刷新 Token 的时间
+}
+
+// 一次性令牌的用途，同时作为 Redis 键前缀：
+// {purpose}:{令牌哈希} -> 用户 ID，{purpose}_user:{用户 ID} -> 当前有效的令牌哈希，{purpose}_cooldown:{用户 ID} 为申请冷却期
+const (
+	TokenPurposePasswordReset = "password_reset"
+	TokenPurposeEmailVerify   = "email_verify"
+	TokenPurposeEmailChange   = "email_change"
+)
+
+// PendingEmailPrefix pending_email:{userID} -> 待验证的新邮箱，与 email_change 令牌同时过期
+const PendingEmailPrefix = "pending_email:"
+
 func NewRedis(rdb *redis.Client) {
 	Rdb = &TokenRdb{
 		rdb: rdb,
@@ -36,6 +67,12 @@ func (r *TokenRdb)GetRefreshToken(ctx context.Context, userID uint64, tokenID st
 	return r.rdb.Get(ctx, key).Result()
 }
 
+// ConsumeRefreshToken 原子地取出并删除 Refresh Token，保证同一 Token 只能刷新一次；不存在时返回 redis.Nil
+func (r *TokenRdb)ConsumeRefreshToken(ctx context.Context, userID uint64, tokenID string) (string, error) {
+	key := fmt.Sprintf("%s%d:%s", RefreshTokenPrefix, userID, tokenID)
+	return r.rdb.GetDel(ctx, key).Result()
+}
+
 // DeleteRefreshToken 删除 Refresh Token
 func (r *TokenRdb)DeleteRefreshToken(ctx context.Context, userID uint64, tokenID string) error {
 	key := fmt. Sprintf("%s%d:%s", RefreshTokenPrefix, userID, tokenID)
@@ -70,4 +107,162 @@ func (r *TokenRdb)IsInBlacklist(ctx context.Context, tokenID string) (bool, erro
 		return false, err
 	}
 	return result > 0, nil
-}
\ No newline at end of file
+}
+
+// StoreOneTimeToken 保存一次性令牌的哈希（值为用户 ID），并作废该用户之前签发的同类令牌
+func (r *TokenRdb)StoreOneTimeToken(ctx context.Context, purpose string, userID uint64, tokenHash string, ttl time.Duration) error {
+	userKey := fmt.Sprintf("%s_user:%d", purpose, userID)
+	if old, err := r.rdb.Get(ctx, userKey).Result(); err == nil {
+		if err := r.rdb.Del(ctx, purpose+":"+old).Err(); err != nil {
+			return err
+		}
+	} else if err != redis.Nil {
+		return err
+	}
+
+	pipe := r.rdb.TxPipeline()
+	pipe.Set(ctx, purpose+":"+tokenHash, userID, ttl)
+	pipe.Set(ctx, userKey, tokenHash, ttl)
+	_, err := pipe.Exec(ctx)
+	return err
+}
+
+// ConsumeOneTimeToken 取出并删除一次性令牌，保证只能使用一次；令牌不存在时返回 redis.Nil
+func (r *TokenRdb)ConsumeOneTimeToken(ctx context.Context, purpose, tokenHash string) (uint64, error) {
+	userID, err := r.rdb.GetDel(ctx, purpose+":"+tokenHash).Uint64()
+	if err != nil {
+		return 0, err
+	}
+	if err := r.rdb.Del(ctx, fmt.Sprintf("%s_user:%d", purpose, userID)).Err(); err != nil {
+		return 0, err
+	}
+	return userID, nil
+}
+
+// HasOneTimeToken 用户当前是否持有未使用、未过期的同类令牌
+func (r *TokenRdb)HasOneTimeToken(ctx context.Context, purpose string, userID uint64) (bool, error) {
+	n, err := r.rdb.Exists(ctx, fmt.Sprintf("%s_user:%d", purpose, userID)).Result()
+	return n > 0, err
+}
+
+// StorePendingEmail 保存用户待验证的新邮箱，覆盖之前未完成的修改
+func (r *TokenRdb)StorePendingEmail(ctx context.Context, userID uint64, email string, ttl time.Duration) error {
+	return r.rdb.Set(ctx, fmt.Sprintf("%s%d", PendingEmailPrefix, userID), email, ttl).Err()
+}
+
+// ConsumePendingEmail 取出并删除待验证的新邮箱，不存在时返回 redis.Nil
+func (r *TokenRdb)ConsumePendingEmail(ctx context.Context, userID uint64) (string, error) {
+	return r.rdb.GetDel(ctx, fmt.Sprintf("%s%d", PendingEmailPrefix, userID)).Result()
+}
+
+// AcquireOneTimeTokenCooldown 同一用户申请同类令牌的冷却期，冷却期内再次申请返回 false
+func (r *TokenRdb)AcquireOneTimeTokenCooldown(ctx context.Context, purpose string, userID uint64, cooldown time.Duration) (bool, error) {
+	key := fmt.Sprintf("%s_cooldown:%d", purpose, userID)
+	return r.rdb.SetNX(ctx, key, "1", cooldown).Result()
+}
+
+// SaveSession 写入会话，有效期与 Refresh Token 一致
+func (r *TokenRdb)SaveSession(ctx context.Context, userID uint64, session *Session) error {
+	key := fmt.Sprintf("%s%d:%s", SessionPrefix, userID, session.ID)
+	setKey := fmt.Sprintf("%s%d", UserSessionsPrefix, userID)
+
+	pipe := r.rdb.TxPipeline()
+	pipe.HSet(ctx, key, session)
+	pipe.Expire(ctx, key, RefreshTokenTTL)
+	pipe.SAdd(ctx, setKey, session.ID)
+	pipe.Expire(ctx, setKey, RefreshTokenTTL)
+	_, err := pipe.Exec(ctx)
+	return err
+}
+
+// GetSession 获取会话，不存在时返回 redis.Nil
+func (r *TokenRdb)GetSession(ctx context.Context, userID uint64, sessionID string) (*Session, error) {
+	key := fmt.Sprintf("%s%d:%s", SessionPrefix, userID, sessionID)
+	res := r.rdb.HGetAll(ctx, key)
+	if err := res.Err(); err != nil {
+		return nil, err
+	}
+	if len(res.Val()) == 0 {
+		return nil, redis.Nil
+	}
+
+	var session Session
+	if err := res.Scan(&session); err != nil {
+		return nil, err
+	}
+	return &session, nil
+}
+
+// ListSessions 获取用户的全部会话，顺带清理集合中已过期的会话 ID
+func (r *TokenRdb)ListSessions(ctx context.Context, userID uint64) ([]Session, error) {
+	setKey := fmt.Sprintf("%s%d", UserSessionsPrefix, userID)
+	ids, err := r.rdb.SMembers(ctx, setKey).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	sessions := make([]Session, 0, len(ids))
+	for _, id := range ids {
+		session, err := r.GetSession(ctx, userID, id)
+		if err == redis.Nil {
+			if err := r.rdb.SRem(ctx, setKey, id).Err(); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		sessions = append(sessions, *session)
+	}
+	return sessions, nil
+}
+
+// DeleteSession 删除会话记录，不处理其 Token
+func (r *TokenRdb)DeleteSession(ctx context.Context, userID uint64, sessionID string) error {
+	pipe := r.rdb.TxPipeline()
+	pipe.Del(ctx, fmt.Sprintf("%s%d:%s", SessionPrefix, userID, sessionID))
+	pipe.Del(ctx, fmt.Sprintf("%s%d:%s", SessionTokensPrefix, userID, sessionID))
+	pipe.SRem(ctx, fmt.Sprintf("%s%d", UserSessionsPrefix, userID), sessionID)
+	_, err := pipe.Exec(ctx)
+	return err
+}
+
+// AddSessionToken 记录会话签发的 TokenID，吊销会话时据此将整个家族的 Access Token 加入黑名单
+func (r *TokenRdb)AddSessionToken(ctx context.Context, userID uint64, sessionID, tokenID string) error {
+	key := fmt.Sprintf("%s%d:%s", SessionTokensPrefix, userID, sessionID)
+
+	pipe := r.rdb.TxPipeline()
+	pipe.SAdd(ctx, key, tokenID)
+	pipe.Expire(ctx, key, RefreshTokenTTL)
+	_, err := pipe.Exec(ctx)
+	return err
+}
+
+// GetSessionTokens 获取会话签发过的全部 TokenID
+func (r *TokenRdb)GetSessionTokens(ctx context.Context, userID uint64, sessionID string) ([]string, error) {
+	key := fmt.Sprintf("%s%d:%s", SessionTokensPrefix, userID, sessionID)
+	return r.rdb.SMembers(ctx, key).Result()
+}
+
+// MarkTokenRotated 记录已轮换的 Refresh Token 所属会话，保留到该 Token 自然过期
+func (r *TokenRdb)MarkTokenRotated(ctx context.Context, userID uint64, tokenID, sessionID string) error {
+	key := fmt.Sprintf("%s%d:%s", RotatedTokenPrefix, userID, tokenID)
+	return r.rdb.Set(ctx, key, sessionID, RefreshTokenTTL).Err()
+}
+
+// GetRotatedTokenSession 查询已轮换的 Refresh Token 所属会话，未轮换过时返回 redis.Nil
+func (r *TokenRdb)GetRotatedTokenSession(ctx context.Context, userID uint64, tokenID string) (string, error) {
+	key := fmt.Sprintf("%s%d:%s", RotatedTokenPrefix, userID, tokenID)
+	return r.rdb.Get(ctx, key).Result()
+}
+
+// StoreStreamTicket 保存事件流票据
+func (r *TokenRdb)StoreStreamTicket(ctx context.Context, ticketHash string, payload []byte, ttl time.Duration) error {
+	return r.rdb.Set(ctx, StreamTicketPrefix+ticketHash, payload, ttl).Err()
+}
+
+// ConsumeStreamTicket 取出并删除事件流票据，票据只能使用一次；不存在时返回 redis.Nil
+func (r *TokenRdb)ConsumeStreamTicket(ctx context.Context, ticketHash string) ([]byte, error) {
+	return r.rdb.GetDel(ctx, StreamTicketPrefix+ticketHash).Bytes()
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"library-system/dto/request"
 	"library-system/model"
+	"time"
 
 	"gorm.io/gorm"
 	"gorm.io/gorm/clause"
@@ -21,14 +22,19 @@ func (r *UserRepository) DB() *gorm.DB {
 	return r.db
 }
 
-func (r *UserRepository) CreateUser(ctx context.Context, user *model.User) error {
-	return gorm.G[model.User](r.db).Create(ctx, user)
+func (r *UserRepository) CreateUser(ctx context.Context, tx *gorm.DB, user *model.User) error {
+	return gorm.G[model.User](tx).Create(ctx, user)
 }
 
 func (r *UserRepository) GetUserByUserID(ctx context.Context, id uint64) (model.User, error) {
 	return gorm.G[model.User](r.db).Where("id = ?", id).First(ctx)
 }
 
+// GetUserByUserIDUnscoped 根据 ID 获取用户，包括已删除的用户，供历史统计使用
+func (r *UserRepository) GetUserByUserIDUnscoped(ctx context.Context, id uint64) (model.User, error) {
+	return gorm.G[model.User](r.db.Unscoped()).Where("id = ?", id).First(ctx)
+}
+
 func (r *UserRepository) GetUserByUsername(ctx context.Context, username string) (model.User, error) {
 	return gorm.G[model.User](r.db).Where("username = ?", username).First(ctx)
 }
@@ -37,6 +43,25 @@ func (r *UserRepository) GetUserByEmail(ctx context.Context, email string) (mode
 	return gorm.G[model.User](r.db).Where("email = ?", email).First(ctx)
 }
 
+// GetUserByUsernameUnscoped 包含回收站中的用户，用于检查用户名是否被占用
+func (r *UserRepository) GetUserByUsernameUnscoped(ctx context.Context, username string) (model.User, error) {
+	return gorm.G[model.User](r.db.Unscoped()).Where("username = ?", username).First(ctx)
+}
+
+// GetUserByEmailUnscoped 包含回收站中的用户，用于检查邮箱是否被占用
+func (r *UserRepository) GetUserByEmailUnscoped(ctx context.Context, email string) (model.User, error) {
+	return gorm.G[model.User](r.db.Unscoped()).Where("email = ?", email).First(ctx)
+}
+
+func (r *UserRepository) GetUserByCardNumber(ctx context.Context, cardNumber string) (model.User, error) {
+	return gorm.G[model.User](r.db).Where("card_number = ?", cardNumber).First(ctx)
+}
+
+// GetUserByCardNumberUnscoped 包含回收站中的用户，用于检查借书证号是否被占用
+func (r *UserRepository) GetUserByCardNumberUnscoped(ctx context.Context, cardNumber string) (model.User, error) {
+	return gorm.G[model.User](r.db.Unscoped()).Where("card_number = ?", cardNumber).First(ctx)
+}
+
 func (r *UserRepository) GetUsersByRole(ctx context.Context, role string) ([]model.User, error) {
 	return gorm.G[model.User](r.db).Where("role = ?", role).Find(ctx)
 }
@@ -45,6 +70,16 @@ func (r *UserRepository) GetUsersByStatus(ctx context.Context, status string) ([
 	return gorm.G[model.User](r.db).Where("status = ?", status).Find(ctx)
 }
 
+// GetUsersByStatusCreatedBefore 获取指定状态、在某时间之前注册的用户，用于清理长期未验证的账号
+func (r *UserRepository) GetUsersByStatusCreatedBefore(ctx context.Context, status string, before time.Time) ([]model.User, error) {
+	return gorm.G[model.User](r.db).Where("status = ? AND created_at < ?", status, before).Find(ctx)
+}
+
+// PurgeUserByStatus 彻底删除（不进回收站）仍处于指定状态的用户，状态已变化时不删除，返回删除的行数
+func (r *UserRepository) PurgeUserByStatus(ctx context.Context, tx *gorm.DB, id uint64, status string) (int, error) {
+	return gorm.G[model.User](tx.Unscoped()).Where("id = ? AND status = ?", id, status).Delete(ctx)
+}
+
 func (r *UserRepository) UpdateUserFields(ctx context.Context, db *gorm.DB, id uint64, fields map[string]interface{}) error {
 	return db.WithContext(ctx).Model(&model.User{}).Where("id = ?", id).Updates(fields).Error
 }
@@ -78,11 +113,52 @@ func (r *UserRepository) GetUserList(ctx context.Context, query *request.GetUser
 	return users, total, nil
 }
 
-func (r *UserRepository) DeleteUserByID(ctx context.Context, id uint64) error {
-	_, err := gorm.G[model.User](r.db).Where("id = ?", id).Delete(ctx)
+func (r *UserRepository) DeleteUserByID(ctx context.Context, tx *gorm.DB, id uint64) error {
+	_, err := gorm.G[model.User](tx).Where("id = ?", id).Delete(ctx)
 	return err
 }
 
+// GetUserByUsernameOrEmailUnscoped 按用户名或邮箱查找用户，包括回收站中的用户（其用户名、邮箱仍占用唯一索引）
+func (r *UserRepository) GetUserByUsernameOrEmailUnscoped(ctx context.Context, username, email string) (model.User, error) {
+	return gorm.G[model.User](r.db.Unscoped()).Where("username = ? OR email = ?", username, email).First(ctx)
+}
+
+// HasActiveReservations 检查用户是否有等待中或待取书的预约
+func (r *UserRepository) HasActiveReservations(ctx context.Context, id uint64) (bool, error) {
+	var count int64
+	err := r.db.WithContext(ctx).Model(&model.Reservation{}).
+		Where("user_id = ? AND status IN ?", id, []string{model.ReservationStatusWaiting, model.ReservationStatusAvailable}).
+		Count(&count).Error
+	return count > 0, err
+}
+
+// GetTrashedUsers 分页查询回收站中的用户，按删除时间倒序
+func (r *UserRepository) GetTrashedUsers(ctx context.Context, page, limit int) ([]model.User, int64, error) {
+	db := r.db.WithContext(ctx).Unscoped().Model(&model.User{}).Where("deleted_at IS NOT NULL")
+
+	var total int64
+	if err := db.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var users []model.User
+	err := db.Order("deleted_at DESC, id DESC").
+		Offset((page - 1) * limit).
+		Limit(limit).
+		Find(&users).Error
+	return users, total, err
+}
+
+// GetTrashedUserByID 获取回收站中的用户
+func (r *UserRepository) GetTrashedUserByID(ctx context.Context, id uint64) (model.User, error) {
+	return gorm.G[model.User](r.db.Unscoped()).Where("id = ? AND deleted_at IS NOT NULL", id).First(ctx)
+}
+
+// RestoreUser 将用户移出回收站
+func (r *UserRepository) RestoreUser(ctx context.Context, tx *gorm.DB, id uint64) error {
+	return tx.WithContext(ctx).Unscoped().Model(&model.User{}).Where("id = ?", id).Update("deleted_at", nil).Error
+}
+
 func (r *UserRepository) GetUserByIDWithLock(ctx context.Context, tx *gorm.DB, id uint64) (model.User, error) {
 	txLock := tx.Clauses(clause.Locking{Strength: "UPDATE"})
 	
@@ -3,6 +3,7 @@ package router
 import (
 	"library-system/controller"
 	"library-system/middleware"
+	"library-system/model"
 
 	"github.com/gin-gonic/gin"
 )
@@ -15,12 +16,29 @@ func SetupRouter(ctl *controller.Controller) *gin.Engine {
 	userCtl := ctl.UserController
 	bookCtl := ctl.BookController
 	borrowCtl := ctl.BorrowController
+	copyCtl := ctl.BookCopyController
 	categoryCtl := ctl.CategoryController
 	statsCtl := ctl.StatsController
+	policyCtl := ctl.LoanPolicyController
+	fineCtl := ctl.FineController
+	notificationCtl := ctl.NotificationController
+	eventCtl := ctl.EventController
+	reviewCtl := ctl.ReviewController
+	importCtl := ctl.BookImportController
+	exportCtl := ctl.BookExportController
+	coverCtl := ctl.BookCoverController
+	auditCtl := ctl.AuditController
+	roleCtl := ctl.RoleController
+	sessionCtl := ctl.SessionController
+	jwksCtl := ctl.JWKSController
 
+	r.Use(middleware.RequestID())
 	r.Use(middleware.ErrorHandler())
 	r.Use(gin.Recovery())
 
+	// 公开的 Token 验证公钥
+	r.GET("/.well-known/jwks.json", jwksCtl.GetJWKS)
+
 	api := r.Group("/api")
 	{
 		users := api.Group("/users")
@@ -28,6 +46,10 @@ func SetupRouter(ctl *controller.Controller) *gin.Engine {
 			users.POST("/register", userCtl.Register)
 			users.POST("/login", userCtl.Login)
 			users.POST("/refresh-token", userCtl.RefreshToken)
+			users.POST("/forgot-password", userCtl.ForgotPassword)
+			users.POST("/reset-password", userCtl.ResetPassword)
+			users.POST("/verify-email", userCtl.VerifyEmail)
+			users.POST("/resend-verification", userCtl.ResendVerification)
 
 			auth := users.Group("", middleware.AuthMiddleware())
 			{
@@ -35,13 +57,24 @@ func SetupRouter(ctl *controller.Controller) *gin.Engine {
 				auth.GET("/me", userCtl.GetUserMsg)
 				auth.PUT("/me", userCtl.UpdateUser)
 				auth.POST("/change-password", userCtl.ChangePwd)
+				auth.GET("/me/sessions", sessionCtl.GetMySessions)
+				auth.DELETE("/me/sessions", sessionCtl.RevokeAllMySessions)
+				auth.DELETE("/me/sessions/:id", sessionCtl.RevokeMySession)
+
+				read := auth.Group("", middleware.RequirePermission(model.PermUsersRead))
+				{
+					read.GET("", userCtl.GetUserList)
+					read.GET("/trash", userCtl.GetUserTrash)
+					read.GET("/:id/sessions", sessionCtl.GetUserSessions)
+				}
 
-				admin := auth.Group("", middleware.RoleMiddleware())
+				write := auth.Group("", middleware.RequirePermission(model.PermUsersWrite))
 				{
-					admin.GET("", userCtl.GetUserList)
-					admin.POST("", userCtl.CreateUser)
-					admin.PUT("/:id", userCtl.UpdateUserByAdmin)
-					admin.DELETE("/:id", userCtl.DeleteUser)
+					write.POST("", userCtl.CreateUser)
+					write.POST("/:id/restore", userCtl.RestoreUser)
+					write.POST("/:id/logout", sessionCtl.ForceLogout)
+					write.PUT("/:id", userCtl.UpdateUserByAdmin)
+					write.DELETE("/:id", userCtl.DeleteUser)
 				}
 			}
 		}
@@ -50,16 +83,41 @@ func SetupRouter(ctl *controller.Controller) *gin.Engine {
 		{
 			// 具体路径必须在动态路由 :id 之前定义
 			books.GET("", bookCtl.GetBookList)
+			books.GET("/search", bookCtl.SearchBooks)
 			books.GET("/:id", bookCtl.GetBookDetails)
+			books.GET("/:id/reviews", reviewCtl.GetReviewList)
 
 			auth := books.Group("", middleware.AuthMiddleware())
 			{
-				admin := auth.Group("", middleware.RoleMiddleware())
+				auth.POST("/:id/reviews", middleware.RequireVerifiedEmail(), reviewCtl.CreateReview)
+				auth.PUT("/:id/reviews/:review_id", middleware.RequireVerifiedEmail(), reviewCtl.UpdateReview)
+				auth.DELETE("/:id/reviews/:review_id", reviewCtl.DeleteReview)
+
+				admin := auth.Group("", middleware.RequirePermission(model.PermBooksWrite))
 				{
 					admin.POST("", bookCtl.CreateBook)
 					admin.POST("/batch", bookCtl.BatchCreateBook)
+					admin.POST("/lookup", bookCtl.LookupBook)
+					admin.POST("/import", importCtl.ImportBooks)
+					admin.GET("/import/reports/:report_id", importCtl.DownloadImportReport)
+					admin.GET("/export", exportCtl.ExportBooks)
+					admin.GET("/trash", bookCtl.GetBookTrash)
+					admin.POST("/:id/restore", bookCtl.RestoreBook)
 					admin.PUT("/:id", bookCtl.UpdateBook)
 					admin.DELETE("/:id", bookCtl.DeleteBook)
+					admin.POST("/:id/cover", coverCtl.UploadCover)
+
+					// 馆藏副本管理
+					admin.GET("/:id/copies", copyCtl.GetBookCopyList)
+					admin.POST("/:id/copies", copyCtl.CreateBookCopy)
+					admin.PUT("/:id/copies/:copy_id", copyCtl.UpdateBookCopy)
+					admin.DELETE("/:id/copies/:copy_id", copyCtl.DeleteBookCopy)
+				}
+
+				// 评价审核
+				moderate := auth.Group("", middleware.RequirePermission(model.PermReviewsModerate))
+				{
+					moderate.PUT("/:id/reviews/:review_id/visibility", reviewCtl.ModerateReview)
 				}
 			}
 		}
@@ -68,34 +126,114 @@ func SetupRouter(ctl *controller.Controller) *gin.Engine {
 		{
 			auth := borrow.Group("", middleware.AuthMiddleware())
 			{
-				auth.POST("", borrowCtl.BorrowBook)
-				auth.POST("/:borrow_id/return", borrowCtl.ReturnBook)
-				auth.POST("/:borrow_id/renew", borrowCtl.RenewBorrow)
 				auth.GET("", borrowCtl.GetBorrowRecordList)
 				auth.GET("/current", borrowCtl.GetCurrentRecord)
+
+				// 未验证邮箱的用户只能查看
+				verified := auth.Group("", middleware.RequireVerifiedEmail())
+				{
+					verified.POST("", borrowCtl.BorrowBook)
+					verified.POST("/return", borrowCtl.ReturnBookByBarcode)
+					verified.POST("/:borrow_id/return", borrowCtl.ReturnBook)
+					verified.POST("/:borrow_id/renew", borrowCtl.RenewBorrow)
+				}
 			}
 		}
 
+		// 流通台：馆员代读者借还
+		circulation := api.Group("/circulation", middleware.AuthMiddleware(), middleware.RequirePermission(model.PermCirculationWrite))
+		{
+			circulation.POST("/checkout", borrowCtl.Checkout)
+			circulation.POST("/checkin", borrowCtl.Checkin)
+		}
+
 		reservations := api.Group("/reservations")
 		reservations.Use(middleware.AuthMiddleware())
 		{
-			reservations.POST("", ctl.ReservationController.CreateReservation)
+			reservations.POST("", middleware.RequireVerifiedEmail(), ctl.ReservationController.CreateReservation)
 			reservations.DELETE("/:id", ctl.ReservationController.CancelReservation)
 			reservations.GET("/my", ctl.ReservationController.GetMyReservations)
 		}
 
+		loanPolicies := api.Group("/loan-policies", middleware.AuthMiddleware())
+		{
+			read := loanPolicies.Group("", middleware.RequirePermission(model.PermLoanPoliciesRead))
+			{
+				read.GET("", policyCtl.GetLoanPolicyList)
+				read.GET("/resolve", policyCtl.ResolveLoanPolicy)
+			}
+
+			write := loanPolicies.Group("", middleware.RequirePermission(model.PermLoanPoliciesWrite))
+			{
+				write.POST("", policyCtl.CreateLoanPolicy)
+				write.PUT("/:id", policyCtl.UpdateLoanPolicy)
+				write.DELETE("/:id", policyCtl.DeleteLoanPolicy)
+			}
+		}
+
+		fines := api.Group("/fines", middleware.AuthMiddleware())
+		{
+			fines.GET("/me", fineCtl.GetMyFines)
+
+			fines.GET("/users/:user_id", middleware.RequirePermission(model.PermCirculationRead), fineCtl.GetUserFines)
+
+			write := fines.Group("/users", middleware.RequirePermission(model.PermCirculationWrite))
+			{
+				write.POST("/:user_id/payments", fineCtl.RecordPayment)
+				write.POST("/:user_id/waivers", fineCtl.WaiveFine)
+				write.POST("/:user_id/adjustments", fineCtl.AdjustFine)
+			}
+		}
+
+		notifications := api.Group("/notifications", middleware.AuthMiddleware())
+		{
+			notifications.GET("", notificationCtl.GetNotificationList)
+			notifications.GET("/unread-count", notificationCtl.GetUnreadCount)
+			notifications.PUT("/read-all", notificationCtl.MarkAllRead)
+			notifications.PUT("/:id/read", notificationCtl.MarkRead)
+		}
+
+		reviews := api.Group("/reviews", middleware.AuthMiddleware(), middleware.RequirePermission(model.PermReviewsModerate))
+		{
+			reviews.GET("", reviewCtl.GetModerationList)
+		}
+
+		events := api.Group("/events")
+		{
+			events.POST("/ticket", middleware.AuthMiddleware(), eventCtl.CreateTicket)
+			events.GET("/stream", middleware.StreamAuthMiddleware(), eventCtl.Stream)
+		}
+
+		admin := api.Group("/admin", middleware.AuthMiddleware())
+		{
+			admin.GET("/audit", middleware.RequirePermission(model.PermAuditRead), auditCtl.GetAuditEventList)
+
+			// 角色与权限管理
+			roles := admin.Group("", middleware.RequirePermission(model.PermRolesManage))
+			{
+				roles.GET("/permissions", roleCtl.GetPermissionList)
+				roles.GET("/roles", roleCtl.GetRoleList)
+				roles.POST("/roles", roleCtl.CreateRole)
+				roles.PUT("/roles/:id", roleCtl.UpdateRole)
+				roles.DELETE("/roles/:id", roleCtl.DeleteRole)
+			}
+		}
+
 		categories := api.Group("/categories")
 		{
 			// 公开接口（不需要认证）
 			categories.GET("", categoryCtl.GetCategoryList)
+			categories.GET("/tree", categoryCtl.GetCategoryTree)
 			categories.GET("/:id", categoryCtl.GetCategoryDetail)
 
 			// 管理员接口
 			auth := categories.Group("", middleware.AuthMiddleware())
 			{
-				admin := auth.Group("", middleware.RoleMiddleware())
+				admin := auth.Group("", middleware.RequirePermission(model.PermCategoriesWrite))
 				{
+					admin.GET("/trash", categoryCtl.GetCategoryTrash)
 					admin.POST("", categoryCtl.CreateCategory)
+					admin.POST("/:id/restore", categoryCtl.RestoreCategory)
 					admin.PUT("/:id", categoryCtl.UpdateCategory)
 					admin.DELETE("/:id", categoryCtl.DeleteCategory)
 				}
@@ -114,11 +252,12 @@ func SetupRouter(ctl *controller.Controller) *gin.Engine {
 			auth.GET("/user/:user_id", statsCtl.GetUserStats)
 
 			// 管理员接口
-			admin := auth.Group("", middleware.RoleMiddleware())
+			admin := auth.Group("", middleware.RequirePermission(model.PermStatsRead))
 			{
 				admin.GET("/overview", statsCtl.GetOverview)
 				admin.GET("/borrow", statsCtl.GetBorrowStats)
 				admin.GET("/categories", statsCtl.GetCategoryStats)
+				admin.GET("/damaged-items", statsCtl.GetDamagedItems)
 			}
 		}
 	}
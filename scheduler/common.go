@@ -3,4 +3,8 @@ package scheduler
 type Scheduler struct {
 	OverdueScheduler *OverdueScheduler
 	ReservationScheduler *ReservationScheduler
+	NotificationScheduler *NotificationScheduler
+	DueReminderScheduler *DueReminderScheduler
+	KeyRotationScheduler *KeyRotationScheduler
+	UnverifiedUserScheduler *UnverifiedUserScheduler
 }
\ No newline at end of file
@@ -0,0 +1,62 @@
+package scheduler
+
+import (
+	"context"
+	"library-system/service"
+	"log"
+	"time"
+
+	"github.com/robfig/cron/v3"
+)
+
+// DueReminderScheduler 到期提醒定时任务调度器
+type DueReminderScheduler struct {
+	overdueService *service.OverdueService
+	days           int // 到期前多少天提醒
+	cron           *cron.Cron
+}
+
+// NewDueReminderScheduler 创建调度器
+func NewDueReminderScheduler(overdueService *service.OverdueService, days int) *DueReminderScheduler {
+	return &DueReminderScheduler{
+		overdueService: overdueService,
+		days:           days,
+		cron:           cron.New(),
+	}
+}
+
+// Start 启动定时任务
+func (s *DueReminderScheduler) Start(cronExpr string) error {
+	_, err := s.cron.AddFunc(cronExpr, func() {
+		ctx := context.Background()
+		startTime := time.Now()
+
+		log.Println("[定时任务] 开始发送到期提醒...")
+
+		count, err := s.overdueService.SendDueReminders(ctx, s.days)
+		if err != nil {
+			log.Printf("[定时任务] 到期提醒失败: %v\n", err)
+			return
+		}
+
+		duration := time.Since(startTime)
+		log.Printf("[定时任务] 完成到期提醒，提醒了 %d 条记录，耗时 %v\n", count, duration)
+	})
+
+	if err != nil {
+		return err
+	}
+
+	s.cron.Start()
+	log.Printf("[定时任务] 到期提醒已启动（提前 %d 天），执行计划:  %s\n", s.days, cronExpr)
+
+	return nil
+}
+
+// Stop 停止定时任务
+func (s *DueReminderScheduler) Stop() {
+	if s.cron != nil {
+		s.cron.Stop()
+		log.Println("[定时任务] 到期提醒已停止")
+	}
+}
@@ -0,0 +1,55 @@
+package scheduler
+
+import (
+	"library-system/utils"
+	"log"
+	"time"
+
+	"github.com/robfig/cron/v3"
+)
+
+// KeyRotationScheduler 签名密钥轮换定时任务：重新加载密钥目录，到期时生成新密钥
+type KeyRotationScheduler struct {
+	keys     *utils.KeySet
+	interval time.Duration // 签名密钥的使用期限，0 表示只重新加载
+	cron     *cron.Cron
+}
+
+func NewKeyRotationScheduler(keys *utils.KeySet, interval time.Duration) *KeyRotationScheduler {
+	return &KeyRotationScheduler{
+		keys:     keys,
+		interval: interval,
+		cron:     cron.New(),
+	}
+}
+
+// Start 启动定时任务
+func (s *KeyRotationScheduler) Start(cronExpr string) error {
+	_, err := s.cron.AddFunc(cronExpr, func() {
+		kid, err := s.keys.Rotate(s.interval)
+		if err != nil {
+			log.Printf("[定时任务] 签名密钥轮换失败: %v\n", err)
+			return
+		}
+		if kid != "" {
+			log.Printf("[定时任务] 已生成新的签名密钥 %s\n", kid)
+		}
+	})
+
+	if err != nil {
+		return err
+	}
+
+	s.cron.Start()
+	log.Printf("[定时任务] 签名密钥轮换已启动，执行计划:  %s\n", cronExpr)
+
+	return nil
+}
+
+// Stop 停止定时任务
+func (s *KeyRotationScheduler) Stop() {
+	if s.cron != nil {
+		s.cron.Stop()
+		log.Println("[定时任务] 签名密钥轮换已停止")
+	}
+}
@@ -0,0 +1,53 @@
+package scheduler
+
+import (
+	"context"
+	"library-system/service"
+	"log"
+
+	"github.com/robfig/cron/v3"
+)
+
+// NotificationScheduler 通知发件箱投递定时任务
+type NotificationScheduler struct {
+	notificationService *service.NotificationService
+	cron                *cron.Cron
+}
+
+func NewNotificationScheduler(notificationService *service.NotificationService) *NotificationScheduler {
+	return &NotificationScheduler{
+		notificationService: notificationService,
+		cron:                cron.New(),
+	}
+}
+
+// Start 启动定时任务
+func (s *NotificationScheduler) Start(cronExpr string) error {
+	_, err := s.cron.AddFunc(cronExpr, func() {
+		sent, failed, err := s.notificationService.Dispatch(context.Background())
+		if err != nil {
+			log.Printf("[定时任务] 通知投递失败: %v\n", err)
+			return
+		}
+		if sent > 0 || failed > 0 {
+			log.Printf("[定时任务] 通知投递完成，成功 %d 条，失败 %d 条\n", sent, failed)
+		}
+	})
+
+	if err != nil {
+		return err
+	}
+
+	s.cron.Start()
+	log.Printf("[定时任务] 通知投递已启动，执行计划:  %s\n", cronExpr)
+
+	return nil
+}
+
+// Stop 停止定时任务
+func (s *NotificationScheduler) Stop() {
+	if s.cron != nil {
+		s.cron.Stop()
+		log.Println("[定时任务] 通知投递已停止")
+	}
+}
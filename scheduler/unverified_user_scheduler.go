@@ -0,0 +1,60 @@
+package scheduler
+
+import (
+	"context"
+	"library-system/service"
+	"log"
+	"time"
+
+	"github.com/robfig/cron/v3"
+)
+
+// UnverifiedUserScheduler 清理长期未验证邮箱的注册账号
+type UnverifiedUserScheduler struct {
+	userService *service.UserService
+	cron        *cron.Cron
+}
+
+// NewUnverifiedUserScheduler 创建调度器
+func NewUnverifiedUserScheduler(userService *service.UserService) *UnverifiedUserScheduler {
+	return &UnverifiedUserScheduler{
+		userService: userService,
+		cron:        cron.New(),
+	}
+}
+
+// Start 启动定时任务
+func (s *UnverifiedUserScheduler) Start(cronExpr string) error {
+	_, err := s.cron.AddFunc(cronExpr, func() {
+		ctx := context.Background()
+		startTime := time.Now()
+
+		count, err := s.userService.PurgeUnverifiedUsers(ctx)
+		if err != nil {
+			log.Printf("[定时任务] 清理未验证账号失败: %v\n", err)
+			return
+		}
+
+		if count > 0 {
+			duration := time.Since(startTime)
+			log.Printf("[定时任务] 完成清理未验证账号，删除了 %d 个账号，耗时 %v\n", count, duration)
+		}
+	})
+
+	if err != nil {
+		return err
+	}
+
+	s.cron.Start()
+	log.Printf("[定时任务] 未验证账号清理已启动，执行计划:  %s\n", cronExpr)
+
+	return nil
+}
+
+// Stop 停止定时任务
+func (s *UnverifiedUserScheduler) Stop() {
+	if s.cron != nil {
+		s.cron.Stop()
+		log.Println("[定时任务] 未验证账号清理已停止")
+	}
+}
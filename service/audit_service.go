@@ -0,0 +1,212 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"library-system/common"
+	"library-system/dto/request"
+	"library-system/dto/response"
+	"library-system/model"
+	"library-system/repository"
+	"log"
+	"math"
+	"reflect"
+	"time"
+	"unicode"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// 不写入审计日志的字段：敏感信息与自动维护的时间戳
+var auditIgnoredFields = map[string]bool{
+	"password":   true,
+	"created_at": true,
+	"updated_at": true,
+}
+
+// AuditService 审计日志服务，由各业务服务在写操作后调用
+type AuditService struct {
+	auditRepo *repository.AuditRepository
+}
+
+func NewAuditService(auditRepo *repository.AuditRepository) *AuditService {
+	return &AuditService{auditRepo: auditRepo}
+}
+
+// Record 在事务 tx 中写入审计事件，随业务变更一同提交或回滚。
+// before、after 为变更前后的快照，可以是模型或字段 map：创建时 before 为 nil，删除时 after 为 nil；
+// 更新时 after 可只包含修改的字段，只记录取值发生变化的字段
+func (s *AuditService) Record(ctx context.Context, tx *gorm.DB, action, entityType string, entityID uint64, before, after interface{}) error {
+	changes, err := auditDiff(before, after)
+	if err != nil {
+		return err
+	}
+
+	event := model.AuditEvent{
+		Action:     action,
+		EntityType: entityType,
+		EntityID:   entityID,
+		Changes:    changes,
+	}
+	if meta := common.RequestMetaFrom(ctx); meta != nil {
+		if meta.UserID != 0 {
+			actorID := meta.UserID
+			event.ActorID = &actorID
+		}
+		event.ActorName = meta.Username
+		event.IP = meta.IP
+		event.RequestID = meta.RequestID
+	}
+
+	return s.auditRepo.CreateEvent(ctx, tx, &event)
+}
+
+// Log 用于变更不在数据库事务中的场景（如 Redis 中的会话），写入失败只记录日志，不影响已完成的操作
+func (s *AuditService) Log(ctx context.Context, action, entityType string, entityID uint64, before, after interface{}) {
+	if err := s.Record(ctx, s.auditRepo.DB(), action, entityType, entityID, before, after); err != nil {
+		log.Printf("写入审计日志失败 %s/%s#%d: %v", entityType, action, entityID, err)
+	}
+}
+
+// GetAuditEventList 按操作人、对象、动作与时间范围查询审计日志
+func (s *AuditService) GetAuditEventList(ctx context.Context, req *request.GetAuditEventListRequest) (*response.GetAuditEventListResponse, error) {
+	if req.Page <= 0 {
+		req.Page = 1
+	}
+	if req.Limit <= 0 || req.Limit > 100 {
+		req.Limit = 20
+	}
+
+	start, err := parseAuditTime(req.StartTime, false)
+	if err != nil {
+		return nil, err
+	}
+	end, err := parseAuditTime(req.EndTime, true)
+	if err != nil {
+		return nil, err
+	}
+	if start != nil && end != nil && start.After(*end) {
+		return nil, common.ErrInvalidAuditRange
+	}
+
+	events, total, err := s.auditRepo.GetEventList(ctx, req, start, end)
+	if err != nil {
+		return nil, err
+	}
+
+	items := make([]response.AuditEventItem, 0, len(events))
+	for _, e := range events {
+		changes := make(map[string]response.AuditFieldChange, len(e.Changes))
+		for field, change := range e.Changes {
+			changes[field] = response.AuditFieldChange{Before: change.Before, After: change.After}
+		}
+		items = append(items, response.AuditEventItem{
+			ID:         e.ID,
+			ActorID:    e.ActorID,
+			ActorName:  e.ActorName,
+			Action:     e.Action,
+			EntityType: e.EntityType,
+			EntityID:   e.EntityID,
+			Changes:    changes,
+			IP:         e.IP,
+			RequestID:  e.RequestID,
+			CreatedAt:  e.CreatedAt.UTC().Format(time.RFC3339),
+		})
+	}
+
+	return &response.GetAuditEventListResponse{
+		Total:      total,
+		Page:       req.Page,
+		Limit:      req.Limit,
+		TotalPages: int(math.Ceil(float64(total) / float64(req.Limit))),
+		Events:     items,
+	}, nil
+}
+
+// parseAuditTime 解析 RFC3339 或 YYYY-MM-DD，endOfDay 为 true 时日期取当天最后一秒
+func parseAuditTime(value string, endOfDay bool) (*time.Time, error) {
+	if value == "" {
+		return nil, nil
+	}
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return &t, nil
+	}
+	t, err := time.ParseInLocation("2006-01-02", value, time.Local)
+	if err != nil {
+		return nil, common.ErrInvalidAuditTime
+	}
+	if endOfDay {
+		t = t.Add(24*time.Hour - time.Second)
+	}
+	return &t, nil
+}
+
+// auditDiff 计算前后快照的字段差异
+func auditDiff(before, after interface{}) (model.AuditChanges, error) {
+	b, err := auditSnapshot(before)
+	if err != nil {
+		return nil, err
+	}
+	a, err := auditSnapshot(after)
+	if err != nil {
+		return nil, err
+	}
+
+	changes := make(model.AuditChanges)
+	switch {
+	case b == nil:
+		for field, v := range a {
+			changes[field] = model.AuditChange{After: v}
+		}
+	case a == nil:
+		for field, v := range b {
+			changes[field] = model.AuditChange{Before: v}
+		}
+	default:
+		for field, v := range a {
+			if old := b[field]; !reflect.DeepEqual(old, v) {
+				changes[field] = model.AuditChange{Before: old, After: v}
+			}
+		}
+	}
+	return changes, nil
+}
+
+// auditSnapshot 将模型或字段 map 经 JSON 转为统一的 map，便于比较。
+// 未声明 json 标签的关联对象（如 Book.Category）以大写字段名出现，不记录
+func auditSnapshot(v interface{}) (map[string]interface{}, error) {
+	if v == nil {
+		return nil, nil
+	}
+	if fields, ok := v.(map[string]interface{}); ok {
+		normalized := make(map[string]interface{}, len(fields))
+		for k, val := range fields {
+			// gorm.Expr 记录其 SQL，NULL 视为空值
+			if expr, ok := val.(clause.Expr); ok {
+				if expr.SQL == "NULL" {
+					val = nil
+				} else {
+					val = expr.SQL
+				}
+			}
+			normalized[k] = val
+		}
+		v = normalized
+	}
+
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	var snapshot map[string]interface{}
+	if err := json.Unmarshal(data, &snapshot); err != nil {
+		return nil, err
+	}
+	for k := range snapshot {
+		if auditIgnoredFields[k] || (k != "" && unicode.IsUpper([]rune(k)[0])) {
+			delete(snapshot, k)
+		}
+	}
+	return snapshot, nil
+}
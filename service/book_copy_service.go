@@ -0,0 +1,260 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"library-system/common"
+	"library-system/dto/request"
+	"library-system/dto/response"
+	"library-system/model"
+	"library-system/repository"
+	"library-system/utils"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type BookCopyService struct {
+	copyRepo     *repository.BookCopyRepository
+	bookRepo     *repository.BookRepository
+	auditService *AuditService
+}
+
+func NewBookCopyService(copyRepo *repository.BookCopyRepository, bookRepo *repository.BookRepository, auditService *AuditService) *BookCopyService {
+	return &BookCopyService{copyRepo: copyRepo, bookRepo: bookRepo, auditService: auditService}
+}
+
+// GetBookCopyList 获取图书的全部副本
+func (s *BookCopyService) GetBookCopyList(ctx context.Context, bookID uint64, req *request.GetBookCopyListRequest) (*response.GetBookCopyListResponse, error) {
+	book, err := s.bookRepo.GetBookByID(ctx, bookID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, common.ErrBookNotFound
+		}
+		return nil, err
+	}
+
+	copies, err := s.copyRepo.GetCopiesByBookID(ctx, bookID, req.Status)
+	if err != nil {
+		return nil, err
+	}
+
+	statusCounts := make(map[string]int)
+	items := make([]response.BookCopyItem, 0, len(copies))
+	for _, c := range copies {
+		statusCounts[c.Status]++
+		items = append(items, toBookCopyItem(c))
+	}
+
+	return &response.GetBookCopyListResponse{
+		BookID:       book.ID,
+		Title:        book.Title,
+		Total:        len(items),
+		StatusCounts: statusCounts,
+		Copies:       items,
+	}, nil
+}
+
+// CreateBookCopy 新增副本，未指定条码时按 ISBN 自动生成
+func (s *BookCopyService) CreateBookCopy(ctx context.Context, bookID uint64, req *request.CreateBookCopyRequest) (*response.BookCopyItem, error) {
+	book, err := s.bookRepo.GetBookByID(ctx, bookID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, common.ErrBookNotFound
+		}
+		return nil, err
+	}
+
+	bookCopy := model.BookCopy{
+		BookID: bookID,
+		Status: model.CopyStatusAvailable,
+	}
+
+	if req.Barcode != nil {
+		if err := s.checkBarcodeUnique(ctx, *req.Barcode, 0); err != nil {
+			return nil, err
+		}
+		bookCopy.Barcode = *req.Barcode
+	} else {
+		barcode, err := s.nextBarcode(ctx, book)
+		if err != nil {
+			return nil, err
+		}
+		bookCopy.Barcode = barcode
+	}
+	if req.ShelfLocation != nil {
+		bookCopy.ShelfLocation = *req.ShelfLocation
+	}
+	if req.AcquiredAt != nil {
+		t, err := time.Parse("2006-01-02", *req.AcquiredAt)
+		if err != nil {
+			return nil, common.ErrBadRequest
+		}
+		bookCopy.AcquiredAt = &t
+	}
+	if req.Remark != nil {
+		bookCopy.Remark = *req.Remark
+	}
+
+	err = s.copyRepo.DB().Transaction(func(tx *gorm.DB) error {
+		if err := s.copyRepo.CreateCopy(ctx, tx, &bookCopy); err != nil {
+			return err
+		}
+		if err := s.copyRepo.SyncBookStock(ctx, tx, bookID); err != nil {
+			return err
+		}
+		return s.auditService.Record(ctx, tx, model.AuditActionCreate, model.AuditEntityBookCopy, bookCopy.ID, nil, bookCopy)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	item := toBookCopyItem(bookCopy)
+	return &item, nil
+}
+
+// UpdateBookCopy 修改副本信息或状态（借出中的副本只能通过归还改变状态）
+func (s *BookCopyService) UpdateBookCopy(ctx context.Context, bookID, copyID uint64, req *request.UpdateBookCopyRequest) (*response.BookCopyItem, error) {
+	if req.Barcode == nil && req.ShelfLocation == nil && req.AcquiredAt == nil && req.Status == nil && req.Remark == nil {
+		return nil, common.ErrBadRequest
+	}
+
+	updates := make(map[string]interface{})
+	if req.Barcode != nil {
+		if err := s.checkBarcodeUnique(ctx, *req.Barcode, copyID); err != nil {
+			return nil, err
+		}
+		updates["barcode"] = *req.Barcode
+	}
+	if req.ShelfLocation != nil {
+		updates["shelf_location"] = *req.ShelfLocation
+	}
+	if req.AcquiredAt != nil {
+		t, err := time.Parse("2006-01-02", *req.AcquiredAt)
+		if err != nil {
+			return nil, common.ErrBadRequest
+		}
+		updates["acquired_at"] = t
+	}
+	if req.Remark != nil {
+		updates["remark"] = *req.Remark
+	}
+
+	var updated model.BookCopy
+	err := s.copyRepo.DB().Transaction(func(tx *gorm.DB) error {
+		bookCopy, err := s.copyRepo.GetCopyByIDWithLock(ctx, tx, copyID)
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return common.ErrCopyNotFound
+			}
+			return err
+		}
+		if bookCopy.BookID != bookID {
+			return common.ErrCopyNotFound
+		}
+
+		if req.Status != nil && *req.Status != bookCopy.Status {
+			if bookCopy.Status == model.CopyStatusOnLoan {
+				return common.ErrCopyOnLoan
+			}
+			updates["status"] = *req.Status
+		}
+
+		if err := s.copyRepo.UpdateCopyFields(ctx, tx, copyID, updates); err != nil {
+			return err
+		}
+		if err := s.copyRepo.SyncBookStock(ctx, tx, bookID); err != nil {
+			return err
+		}
+
+		updated, err = s.copyRepo.GetCopyByIDWithLock(ctx, tx, copyID)
+		if err != nil {
+			return err
+		}
+		return s.auditService.Record(ctx, tx, model.AuditActionUpdate, model.AuditEntityBookCopy, copyID, bookCopy, updated)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	item := toBookCopyItem(updated)
+	return &item, nil
+}
+
+// DeleteBookCopy 删除副本，有借阅历史的副本只能标记为剔旧
+func (s *BookCopyService) DeleteBookCopy(ctx context.Context, bookID, copyID uint64) error {
+	bookCopy, err := s.copyRepo.GetCopyByID(ctx, copyID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return common.ErrCopyNotFound
+		}
+		return err
+	}
+	if bookCopy.BookID != bookID {
+		return common.ErrCopyNotFound
+	}
+	if bookCopy.Status == model.CopyStatusOnLoan {
+		return common.ErrCopyOnLoan
+	}
+
+	hasHistory, err := s.copyRepo.HasBorrowHistory(ctx, copyID)
+	if err != nil {
+		return err
+	}
+	if hasHistory {
+		return common.NewBizError(400, "无法删除该副本", 400).WithDetails(map[string]interface{}{
+			"reason": "副本存在借阅历史，请将状态改为 withdrawn",
+		})
+	}
+
+	return s.copyRepo.DB().Transaction(func(tx *gorm.DB) error {
+		if err := s.copyRepo.DeleteCopy(ctx, tx, copyID); err != nil {
+			return err
+		}
+		if err := s.copyRepo.SyncBookStock(ctx, tx, bookID); err != nil {
+			return err
+		}
+		return s.auditService.Record(ctx, tx, model.AuditActionDelete, model.AuditEntityBookCopy, copyID, bookCopy, nil)
+	})
+}
+
+func (s *BookCopyService) checkBarcodeUnique(ctx context.Context, barcode string, selfID uint64) error {
+	existing, err := s.copyRepo.GetCopyByBarcode(ctx, barcode)
+	if err == nil && existing.ID != selfID {
+		return common.ErrBarcodeExist
+	} else if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return err
+	}
+	return nil
+}
+
+// nextBarcode 生成该图书下一个未被占用的默认条码
+func (s *BookCopyService) nextBarcode(ctx context.Context, book model.Book) (string, error) {
+	count, err := s.copyRepo.CountCopiesByBookID(ctx, book.ID)
+	if err != nil {
+		return "", err
+	}
+
+	for seq := int(count) + 1; ; seq++ {
+		barcode := utils.GenerateBarcode(book.ISBN, seq)
+		if _, err := s.copyRepo.GetCopyByBarcode(ctx, barcode); errors.Is(err, gorm.ErrRecordNotFound) {
+			return barcode, nil
+		} else if err != nil {
+			return "", err
+		}
+	}
+}
+
+func toBookCopyItem(c model.BookCopy) response.BookCopyItem {
+	return response.BookCopyItem{
+		ID:            c.ID,
+		BookID:        c.BookID,
+		Barcode:       c.Barcode,
+		ShelfLocation: c.ShelfLocation,
+		AcquiredAt:    c.AcquiredAt,
+		Status:        c.Status,
+		Remark:        c.Remark,
+		CreatedAt:     c.CreatedAt,
+		UpdatedAt:     c.UpdatedAt,
+	}
+}
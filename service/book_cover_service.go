@@ -0,0 +1,191 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"image"
+	_ "image/gif"
+	"image/jpeg"
+	_ "image/png"
+	"io"
+	"library-system/common"
+	"library-system/dto/response"
+	"library-system/model"
+	"library-system/repository"
+	"library-system/storage"
+	"library-system/utils"
+	"log"
+	"net/http"
+	"path"
+	"strings"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+const (
+	maxCoverSize   = 5 << 20
+	maxCoverPixels = 16_000_000 // 解码前按宽×高拦截超大图片，防止占满内存：解码结果与 RGBA 副本合计约 100MB
+)
+
+// coverThumbnailSizes 缩略图规格，按宽度等比缩放
+var coverThumbnailSizes = []struct {
+	Name  string
+	Width int
+}{
+	{"small", 150},
+	{"medium", 400},
+}
+
+// coverExtensions 允许上传的封面类型（按文件内容识别）
+var coverExtensions = map[string]string{
+	"image/jpeg": "jpg",
+	"image/png":  "png",
+	"image/gif":  "gif",
+}
+
+type BookCoverService struct {
+	bookRepo     *repository.BookRepository
+	store        storage.BlobStore
+	auditService *AuditService
+}
+
+func NewBookCoverService(bookRepo *repository.BookRepository, store storage.BlobStore, auditService *AuditService) *BookCoverService {
+	return &BookCoverService{
+		bookRepo:     bookRepo,
+		store:        store,
+		auditService: auditService,
+	}
+}
+
+// decodeCover 解码图片并转为 RGBA，各规格缩略图复用同一份像素，解码结果随即可被回收
+func decodeCover(data []byte) (*image.RGBA, error) {
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	return utils.FlattenRGBA(img), nil
+}
+
+// UploadCover 保存封面原图并生成 JPEG 缩略图，更新图书的封面地址
+func (s *BookCoverService) UploadCover(ctx context.Context, bookID uint64, r io.Reader) (*response.UploadCoverResponse, error) {
+	book, err := s.bookRepo.GetBookByID(ctx, bookID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, common.ErrBookNotFound
+		}
+		return nil, err
+	}
+
+	data, err := io.ReadAll(io.LimitReader(r, maxCoverSize+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(data) > maxCoverSize {
+		return nil, common.ErrCoverTooLarge
+	}
+
+	// 不信任客户端声明的类型，按文件头识别
+	contentType := http.DetectContentType(data)
+	ext, ok := coverExtensions[contentType]
+	if !ok {
+		return nil, common.ErrCoverUnsupportedType
+	}
+
+	cfg, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil || cfg.Width == 0 || cfg.Height == 0 || cfg.Width*cfg.Height > maxCoverPixels {
+		return nil, common.ErrCoverInvalidImage
+	}
+	img, err := decodeCover(data)
+	if err != nil {
+		return nil, common.ErrCoverInvalidImage
+	}
+
+	// 每次上传使用新的 key，避免浏览器与 CDN 缓存旧封面
+	base := fmt.Sprintf("covers/%d/%s", book.ID, strings.ReplaceAll(uuid.NewString(), "-", ""))
+	originalKey := base + "." + ext
+
+	var written []string
+	cleanup := func() {
+		for _, key := range written {
+			if err := s.store.Delete(ctx, key); err != nil {
+				log.Printf("清理封面文件 %s 失败: %v", key, err)
+			}
+		}
+	}
+
+	if err := s.store.Put(ctx, originalKey, data, contentType); err != nil {
+		return nil, err
+	}
+	written = append(written, originalKey)
+
+	thumbnails := make(model.CoverThumbnails, len(coverThumbnailSizes))
+	for _, size := range coverThumbnailSizes {
+		var buf bytes.Buffer
+		if err := jpeg.Encode(&buf, utils.Thumbnail(img, size.Width), &jpeg.Options{Quality: 85}); err != nil {
+			cleanup()
+			return nil, err
+		}
+		key := coverThumbnailKey(originalKey, size.Width)
+		if err := s.store.Put(ctx, key, buf.Bytes(), "image/jpeg"); err != nil {
+			cleanup()
+			return nil, err
+		}
+		written = append(written, key)
+		thumbnails[size.Name] = s.store.URL(key)
+	}
+
+	coverURL := s.store.URL(originalKey)
+	err = s.bookRepo.DB().Transaction(func(tx *gorm.DB) error {
+		err := s.bookRepo.UpdateBookFields(ctx, tx, book.ID, map[string]interface{}{
+			"cover_url":        coverURL,
+			"cover_thumbnails": thumbnails,
+			"cover_key":        originalKey,
+		})
+		if err != nil {
+			return err
+		}
+		return s.auditService.Record(ctx, tx, model.AuditActionUploadCover, model.AuditEntityBook, book.ID, book, map[string]interface{}{
+			"cover_url":        coverURL,
+			"cover_thumbnails": thumbnails,
+		})
+	})
+	if err != nil {
+		cleanup()
+		return nil, err
+	}
+
+	// 新封面已生效，旧文件清理失败只记录日志
+	if book.CoverKey != "" {
+		s.deleteCoverFiles(ctx, book.CoverKey)
+	}
+
+	return &response.UploadCoverResponse{
+		BookID:      book.ID,
+		CoverURL:    coverURL,
+		Thumbnails:  thumbnails,
+		ContentType: contentType,
+		Size:        len(data),
+		Width:       cfg.Width,
+		Height:      cfg.Height,
+	}, nil
+}
+
+func (s *BookCoverService) deleteCoverFiles(ctx context.Context, originalKey string) {
+	keys := []string{originalKey}
+	for _, size := range coverThumbnailSizes {
+		keys = append(keys, coverThumbnailKey(originalKey, size.Width))
+	}
+	for _, key := range keys {
+		if err := s.store.Delete(ctx, key); err != nil {
+			log.Printf("清理旧封面文件 %s 失败: %v", key, err)
+		}
+	}
+}
+
+// coverThumbnailKey 由原图 key 推出缩略图 key，如 covers/1/abc.png -> covers/1/abc-w150.jpg
+func coverThumbnailKey(originalKey string, width int) string {
+	return fmt.Sprintf("%s-w%d.jpg", strings.TrimSuffix(originalKey, path.Ext(originalKey)), width)
+}
@@ -0,0 +1,81 @@
+package service
+
+import (
+	"context"
+	"io"
+	"library-system/common"
+	"library-system/exporter"
+	"library-system/repository"
+)
+
+// 每批从数据库读取的图书数量，同时也是向客户端推送数据的粒度
+const exportBatchSize = 500
+
+type BookExportService struct {
+	bookRepo *repository.BookRepository
+}
+
+func NewBookExportService(bookRepo *repository.BookRepository) *BookExportService {
+	return &BookExportService{
+		bookRepo: bookRepo,
+	}
+}
+
+// ExportMeta 校验导出格式并返回响应类型与扩展名，应在开始写响应前调用
+func (s *BookExportService) ExportMeta(format string) (exporter.Meta, error) {
+	meta, ok := exporter.Lookup(format)
+	if !ok {
+		return exporter.Meta{}, common.ErrExportUnsupportedFormat
+	}
+	return meta, nil
+}
+
+// ExportBooks 以指定格式将全部图书流式写入 w，每批写完即推送给客户端
+func (s *BookExportService) ExportBooks(ctx context.Context, format string, w io.Writer) error {
+	enc, err := exporter.NewEncoder(format, w)
+	if err != nil {
+		return common.ErrExportUnsupportedFormat
+	}
+
+	if err := enc.Begin(); err != nil {
+		return err
+	}
+
+	err = s.bookRepo.StreamBooksForExport(ctx, exportBatchSize, func(rows []repository.BookExportRow) error {
+		for i := range rows {
+			if err := enc.Encode(toExportRecord(&rows[i])); err != nil {
+				return err
+			}
+		}
+		return enc.Flush()
+	})
+	if err != nil {
+		return err
+	}
+
+	return enc.End()
+}
+
+func toExportRecord(row *repository.BookExportRow) *exporter.Record {
+	return &exporter.Record{
+		ID:              row.ID,
+		Title:           row.Title,
+		Author:          row.Author,
+		ISBN:            row.ISBN,
+		CategoryID:      row.CategoryID,
+		CategoryName:    row.CategoryName,
+		Publisher:       row.Publisher,
+		PublishDate:     row.PublishDate,
+		Price:           row.Price,
+		Description:     row.Description,
+		CoverURL:        row.CoverURL,
+		Stock:           row.Stock,
+		BorrowCount:     row.BorrowCount,
+		CopyCount:       row.CopyCount,
+		AvailableCopies: row.AvailableCopies,
+		Rating:          row.Rating,
+		ReviewCount:     row.ReviewCount,
+		CreatedAt:       row.CreatedAt,
+		UpdatedAt:       row.UpdatedAt,
+	}
+}
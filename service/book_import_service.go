@@ -0,0 +1,505 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"library-system/common"
+	"library-system/dto/request"
+	"library-system/dto/response"
+	"library-system/model"
+	"library-system/repository"
+	"library-system/spreadsheet"
+	"library-system/utils"
+	"log"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+	"gorm.io/gorm"
+)
+
+const (
+	maxImportFileSize     = 10 << 20
+	maxImportRows         = 5000
+	maxImportStock        = 1000 // 单行最多生成的副本数量
+	maxInlineImportErrors = 100  // 响应中最多返回的错误条数，完整内容见错误报告
+)
+
+// importFields 可导入的字段，顺序即自动识别与报告输出的顺序
+var importFields = []string{
+	"title", "author", "isbn", "category", "category_id", "publisher",
+	"publish_date", "price", "stock", "description", "cover_url",
+}
+
+// importFieldAliases 未指定列映射时按表头自动识别，比较时忽略大小写
+var importFieldAliases = map[string][]string{
+	"title":        {"title", "书名", "标题", "题名"},
+	"author":       {"author", "作者", "著者"},
+	"isbn":         {"isbn", "isbn13", "isbn-13", "isbn10", "isbn-10"},
+	"category":     {"category", "category_name", "分类", "类别", "分类名称"},
+	"category_id":  {"category_id", "分类id", "分类编号"},
+	"publisher":    {"publisher", "出版社"},
+	"publish_date": {"publish_date", "出版日期", "出版时间"},
+	"price":        {"price", "价格", "定价"},
+	"stock":        {"stock", "库存", "数量", "册数"},
+	"description":  {"description", "简介", "描述", "内容简介"},
+	"cover_url":    {"cover_url", "封面", "封面地址"},
+}
+
+// 导入文件中常见的日期写法
+var importDateLayouts = []string{
+	"2006-01-02", "2006-1-2", "2006/01/02", "2006/1/2", "2006.01.02", "2006.1.2",
+	"2006-01", "2006/01", "2006",
+}
+
+type BookImportService struct {
+	bookRepo     *repository.BookRepository
+	categoryRepo *repository.CategoryRepository
+	copyRepo     *repository.BookCopyRepository
+	reportRdb    *repository.ImportReportRdb
+	auditService *AuditService
+}
+
+func NewBookImportService(bookRepo *repository.BookRepository, categoryRepo *repository.CategoryRepository, copyRepo *repository.BookCopyRepository, reportRdb *repository.ImportReportRdb, auditService *AuditService) *BookImportService {
+	return &BookImportService{
+		bookRepo:     bookRepo,
+		categoryRepo: categoryRepo,
+		copyRepo:     copyRepo,
+		reportRdb:    reportRdb,
+		auditService: auditService,
+	}
+}
+
+// importRow 校验通过、待写入的一行
+type importRow struct {
+	row  int
+	book model.Book
+}
+
+// ImportBooks 从 CSV/XLSX 批量导入图书。
+// 默认逐行写入，出错的行记录到报告中；Atomic 模式下任一行出错则整体不写入
+func (s *BookImportService) ImportBooks(ctx context.Context, req *request.ImportBooksRequest, filename string, r io.ReaderAt, size int64) (*response.ImportBooksResponse, error) {
+	if size > maxImportFileSize {
+		return nil, common.ErrImportTooLarge
+	}
+
+	rows, err := spreadsheet.Read(filename, r, size)
+	if err != nil {
+		if errors.Is(err, spreadsheet.ErrUnsupportedFormat) {
+			return nil, common.ErrImportUnsupportedFormat
+		}
+		if errors.Is(err, spreadsheet.ErrInvalidFile) {
+			log.Printf("导入文件 %s 解析失败: %v", filename, err)
+			return nil, common.ErrImportInvalidFile
+		}
+		return nil, err
+	}
+
+	// 第一个非空行作为表头
+	headerIdx := -1
+	for i, row := range rows {
+		if !isBlankRow(row) {
+			headerIdx = i
+			break
+		}
+	}
+	if headerIdx < 0 {
+		return nil, common.ErrImportInvalidFile
+	}
+
+	columns, err := resolveImportColumns(rows[headerIdx], req.Mapping)
+	if err != nil {
+		return nil, err
+	}
+
+	dataRows := 0
+	for _, row := range rows[headerIdx+1:] {
+		if !isBlankRow(row) {
+			dataRows++
+		}
+	}
+	if dataRows > maxImportRows {
+		bizErr := *common.ErrImportTooLarge
+		return nil, bizErr.WithDetails(map[string]interface{}{
+			"max_rows": maxImportRows,
+			"rows":     dataRows,
+		})
+	}
+
+	categories, err := s.categoryRepo.GetCategoryList(ctx)
+	if err != nil {
+		return nil, err
+	}
+	categoryByName := make(map[string]uint, len(categories))
+	categoryByID := make(map[uint]bool, len(categories))
+	for _, cat := range categories {
+		categoryByName[strings.ToLower(strings.TrimSpace(cat.Name))] = cat.ID
+		categoryByID[cat.ID] = true
+	}
+
+	resp := &response.ImportBooksResponse{
+		DryRun:  req.DryRun,
+		Atomic:  req.Atomic,
+		Columns: make(map[string]string, len(columns)),
+		Errors:  make([]response.ImportRowError, 0),
+	}
+	for field, col := range columns {
+		resp.Columns[field] = strings.TrimSpace(rows[headerIdx][col])
+	}
+
+	var rowErrors []response.ImportRowError
+	failedRows := make(map[int]bool)
+	fail := func(row int, isbn, field, msg string) {
+		rowErrors = append(rowErrors, response.ImportRowError{Row: row, ISBN: isbn, Field: field, Message: msg})
+		failedRows[row] = true
+	}
+
+	var parsed []importRow
+	seenISBN := make(map[string]int)
+	for i := headerIdx + 1; i < len(rows); i++ {
+		if isBlankRow(rows[i]) {
+			continue
+		}
+		resp.TotalRows++
+		line := i + 1
+
+		book, errs := parseImportRow(rows[i], columns, categoryByName, categoryByID)
+		for _, e := range errs {
+			fail(line, book.ISBN, e.Field, e.Message)
+		}
+		if len(errs) > 0 {
+			continue
+		}
+		if first, ok := seenISBN[book.ISBN]; ok {
+			fail(line, book.ISBN, "isbn", fmt.Sprintf("与第 %d 行 ISBN 重复", first))
+			continue
+		}
+		seenISBN[book.ISBN] = line
+		parsed = append(parsed, importRow{row: line, book: book})
+	}
+
+	// 馆内可能以 ISBN-13 或 ISBN-10 登记过同一本书
+	existing, err := s.existingISBNs(ctx, parsed)
+	if err != nil {
+		return nil, err
+	}
+	valid := make([]importRow, 0, len(parsed))
+	for _, item := range parsed {
+		if existing[item.book.ISBN] || existing[utils.ISBN13To10(item.book.ISBN)] {
+			fail(item.row, item.book.ISBN, "isbn", "ISBN已存在")
+			continue
+		}
+		valid = append(valid, item)
+	}
+	resp.ValidRows = len(valid)
+
+	if !req.DryRun && len(valid) > 0 {
+		if req.Atomic {
+			if len(rowErrors) == 0 {
+				if failed, err := s.writeAtomic(ctx, valid); err != nil {
+					log.Printf("批量导入写入失败（第 %d 行）: %v", failed, err)
+					fail(failed, "", "", "写入失败，本次导入已全部回滚")
+				} else {
+					resp.ImportedCount = len(valid)
+				}
+			}
+		} else {
+			for i := range valid {
+				item := &valid[i]
+				err := s.bookRepo.DB().Transaction(func(tx *gorm.DB) error {
+					return s.createBook(ctx, tx, &item.book)
+				})
+				if err != nil {
+					log.Printf("批量导入第 %d 行写入失败: %v", item.row, err)
+					fail(item.row, item.book.ISBN, "", "写入失败")
+					continue
+				}
+				resp.ImportedCount++
+			}
+		}
+	}
+
+	resp.Committed = resp.ImportedCount > 0
+	resp.FailedCount = len(failedRows)
+
+	sort.SliceStable(rowErrors, func(i, j int) bool { return rowErrors[i].Row < rowErrors[j].Row })
+	if len(rowErrors) > 0 {
+		resp.Errors = rowErrors
+		if len(rowErrors) > maxInlineImportErrors {
+			resp.Errors = rowErrors[:maxInlineImportErrors]
+			resp.ErrorsTruncated = true
+		}
+		reportID, err := s.saveReport(ctx, rowErrors)
+		if err != nil {
+			// 报告只是辅助下载，保存失败不影响导入结果
+			log.Printf("保存导入报告失败: %v", err)
+		} else {
+			resp.ReportID = reportID
+		}
+	}
+
+	return resp, nil
+}
+
+// GetImportReport 获取导入错误报告（CSV）
+func (s *BookImportService) GetImportReport(ctx context.Context, reportID string) ([]byte, error) {
+	if _, err := uuid.Parse(reportID); err != nil {
+		return nil, common.ErrImportReportNotFound
+	}
+	data, err := s.reportRdb.GetReport(ctx, reportID)
+	if err != nil {
+		if errors.Is(err, redis.Nil) {
+			return nil, common.ErrImportReportNotFound
+		}
+		return nil, err
+	}
+	return data, nil
+}
+
+// writeAtomic 在同一事务中写入全部行，失败时返回出错的行号
+func (s *BookImportService) writeAtomic(ctx context.Context, rows []importRow) (int, error) {
+	failed := 0
+	err := s.bookRepo.DB().Transaction(func(tx *gorm.DB) error {
+		for i := range rows {
+			if err := s.createBook(ctx, tx, &rows[i].book); err != nil {
+				failed = rows[i].row
+				return err
+			}
+		}
+		return nil
+	})
+	return failed, err
+}
+
+// createBook 写入一行导入的图书及其副本，并记录审计日志
+func (s *BookImportService) createBook(ctx context.Context, tx *gorm.DB, book *model.Book) error {
+	if err := createBookWithCopies(ctx, tx, s.bookRepo, s.copyRepo, book); err != nil {
+		return err
+	}
+	return s.auditService.Record(ctx, tx, model.AuditActionImport, model.AuditEntityBook, book.ID, nil, book)
+}
+
+func (s *BookImportService) existingISBNs(ctx context.Context, rows []importRow) (map[string]bool, error) {
+	candidates := make([]string, 0, len(rows)*2)
+	for _, item := range rows {
+		candidates = append(candidates, item.book.ISBN)
+		if isbn10 := utils.ISBN13To10(item.book.ISBN); isbn10 != "" {
+			candidates = append(candidates, isbn10)
+		}
+	}
+
+	existing := make(map[string]bool)
+	for start := 0; start < len(candidates); start += 500 {
+		end := min(start+500, len(candidates))
+		isbns, err := s.bookRepo.GetExistingISBNs(ctx, candidates[start:end])
+		if err != nil {
+			return nil, err
+		}
+		for _, isbn := range isbns {
+			existing[isbn] = true
+		}
+	}
+	return existing, nil
+}
+
+func (s *BookImportService) saveReport(ctx context.Context, rowErrors []response.ImportRowError) (string, error) {
+	records := make([][]string, 0, len(rowErrors)+1)
+	records = append(records, []string{"行号", "ISBN", "字段", "错误信息"})
+	for _, e := range rowErrors {
+		records = append(records, []string{strconv.Itoa(e.Row), e.ISBN, e.Field, e.Message})
+	}
+	data, err := spreadsheet.WriteCSV(records)
+	if err != nil {
+		return "", err
+	}
+
+	reportID := uuid.NewString()
+	if err := s.reportRdb.SaveReport(ctx, reportID, data); err != nil {
+		return "", err
+	}
+	return reportID, nil
+}
+
+// resolveImportColumns 确定每个字段对应的列号：先应用显式映射，其余按内置表头识别
+func resolveImportColumns(header []string, mapping string) (map[string]int, error) {
+	headerIndex := make(map[string]int, len(header))
+	for i, h := range header {
+		key := strings.ToLower(strings.TrimSpace(h))
+		if _, ok := headerIndex[key]; !ok && key != "" {
+			headerIndex[key] = i
+		}
+	}
+
+	columns := make(map[string]int)
+	if strings.TrimSpace(mapping) != "" {
+		var explicit map[string]string
+		if err := json.Unmarshal([]byte(mapping), &explicit); err != nil {
+			return nil, common.ErrImportInvalidMapping
+		}
+		for field, h := range explicit {
+			if _, ok := importFieldAliases[field]; !ok {
+				bizErr := *common.ErrImportInvalidMapping
+				return nil, bizErr.WithDetails(map[string]interface{}{"unknown_field": field})
+			}
+			col, ok := headerIndex[strings.ToLower(strings.TrimSpace(h))]
+			if !ok {
+				bizErr := *common.ErrImportInvalidMapping
+				return nil, bizErr.WithDetails(map[string]interface{}{"field": field, "header_not_found": h})
+			}
+			columns[field] = col
+		}
+	}
+
+	for _, field := range importFields {
+		if _, ok := columns[field]; ok {
+			continue
+		}
+		for _, alias := range importFieldAliases[field] {
+			if col, ok := headerIndex[alias]; ok {
+				columns[field] = col
+				break
+			}
+		}
+	}
+
+	var missing []string
+	for _, field := range []string{"title", "author", "isbn", "publisher"} {
+		if _, ok := columns[field]; !ok {
+			missing = append(missing, field)
+		}
+	}
+	_, hasName := columns["category"]
+	_, hasID := columns["category_id"]
+	if !hasName && !hasID {
+		missing = append(missing, "category")
+	}
+	if len(missing) > 0 {
+		bizErr := *common.ErrImportMissingColumns
+		return nil, bizErr.WithDetails(map[string]interface{}{"missing_columns": missing})
+	}
+
+	return columns, nil
+}
+
+type importFieldError struct {
+	Field   string
+	Message string
+}
+
+// parseImportRow 按与 CreateBookRequest 一致的规则校验一行，返回该行全部字段错误
+func parseImportRow(row []string, columns map[string]int, categoryByName map[string]uint, categoryByID map[uint]bool) (model.Book, []importFieldError) {
+	get := func(field string) string {
+		col, ok := columns[field]
+		if !ok || col >= len(row) {
+			return ""
+		}
+		return strings.TrimSpace(row[col])
+	}
+
+	var book model.Book
+	var errs []importFieldError
+	fail := func(field, msg string) {
+		errs = append(errs, importFieldError{Field: field, Message: msg})
+	}
+	text := func(field, label string, maxLen int, required bool) string {
+		v := get(field)
+		if v == "" && required {
+			fail(field, label+"不能为空")
+		} else if utf8.RuneCountInString(v) > maxLen {
+			fail(field, fmt.Sprintf("%s不能超过 %d 个字符", label, maxLen))
+		}
+		return v
+	}
+
+	book.Title = text("title", "书名", 200, true)
+	book.Author = text("author", "作者", 100, true)
+	book.Publisher = text("publisher", "出版社", 100, true)
+	book.Description = text("description", "简介", 1000, false)
+	book.CoverURL = text("cover_url", "封面地址", 500, false)
+
+	if raw := get("isbn"); raw == "" {
+		fail("isbn", "ISBN不能为空")
+	} else if isbn, err := utils.NormalizeISBN(raw); err != nil {
+		book.ISBN = raw
+		fail("isbn", "ISBN格式或校验位错误")
+	} else {
+		book.ISBN = isbn
+	}
+
+	if v := get("category_id"); v != "" {
+		id, err := strconv.ParseUint(v, 10, 32)
+		if err != nil || !categoryByID[uint(id)] {
+			fail("category_id", fmt.Sprintf("分类ID %s 不存在", v))
+		} else {
+			book.CategoryID = uint(id)
+		}
+	} else if v := get("category"); v != "" {
+		id, ok := categoryByName[strings.ToLower(v)]
+		if !ok {
+			fail("category", fmt.Sprintf("分类「%s」不存在", v))
+		} else {
+			book.CategoryID = id
+		}
+	} else {
+		fail("category", "分类不能为空")
+	}
+
+	if v := get("publish_date"); v != "" {
+		t, ok := parseImportDate(v)
+		if !ok {
+			fail("publish_date", fmt.Sprintf("无法识别的出版日期 %s", v))
+		} else {
+			book.PublishDate = &t
+		}
+	}
+
+	if v := get("price"); v != "" {
+		price, err := strconv.ParseFloat(strings.TrimLeft(v, "¥￥"), 64)
+		if err != nil || price <= 0 {
+			fail("price", "价格必须为正数")
+		} else {
+			book.Price = price
+		}
+	}
+
+	if v := get("stock"); v != "" {
+		stock, err := strconv.Atoi(v)
+		if err != nil || stock < 0 || stock > maxImportStock {
+			fail("stock", fmt.Sprintf("库存需为 0-%d 之间的整数", maxImportStock))
+		} else {
+			book.Stock = stock
+		}
+	}
+
+	return book, errs
+}
+
+// parseImportDate 解析常见日期写法；XLSX 中的日期单元格为 Excel 序列号
+func parseImportDate(v string) (time.Time, bool) {
+	for _, layout := range importDateLayouts {
+		if t, err := time.Parse(layout, v); err == nil {
+			return t, true
+		}
+	}
+	if serial, err := strconv.ParseFloat(v, 64); err == nil && serial > 0 && serial < 2958466 {
+		base := time.Date(1899, 12, 30, 0, 0, 0, 0, time.UTC)
+		return base.AddDate(0, 0, int(serial)), true
+	}
+	return time.Time{}, false
+}
+
+func isBlankRow(row []string) bool {
+	for _, cell := range row {
+		if strings.TrimSpace(cell) != "" {
+			return false
+		}
+	}
+	return true
+}
@@ -3,12 +3,17 @@ package service
 import (
 	"context"
 	"errors"
+	"fmt"
+	"log"
 	"library-system/common"
 	"library-system/dto/request"
 	"library-system/dto/response"
+	"library-system/metadata"
 	"library-system/model"
 	"library-system/repository"
+	"library-system/utils"
 	"math"
+	"strings"
 	"time"
 
 	"gorm.io/gorm"
@@ -17,10 +22,85 @@ import (
 type BookService struct {
 	bookRepo     *repository.BookRepository
 	categoryRepo *repository.CategoryRepository
+	copyRepo     *repository.BookCopyRepository
+	reservationRepo *repository.ReservationRepository
+	auditService *AuditService
+	providers    []metadata.MetadataProvider // 按顺序查询，前一个查不到时再查下一个
 }
 
-func NewBookService(bookRepo *repository.BookRepository, categoryRepo *repository.CategoryRepository) *BookService {
-	return &BookService{bookRepo: bookRepo, categoryRepo: categoryRepo}
+func NewBookService(bookRepo *repository.BookRepository, categoryRepo *repository.CategoryRepository, copyRepo *repository.BookCopyRepository, reservationRepo *repository.ReservationRepository, auditService *AuditService, providers ...metadata.MetadataProvider) *BookService {
+	return &BookService{bookRepo: bookRepo, categoryRepo: categoryRepo, copyRepo: copyRepo, reservationRepo: reservationRepo, auditService: auditService, providers: providers}
+}
+
+// LookupBook 按 ISBN 从元数据源查询图书信息，返回可直接用于创建图书的草稿
+func (s *BookService) LookupBook(ctx context.Context, req *request.LookupBookRequest) (*response.LookupBookResponse, error) {
+	isbn, err := utils.NormalizeISBN(req.ISBN)
+	if err != nil {
+		return nil, common.ErrInvalidISBN
+	}
+
+	var meta *metadata.BookMetadata
+	var source string
+	var lastErr error
+	for _, provider := range s.providers {
+		meta, err = provider.Lookup(ctx, isbn)
+		if err == nil {
+			source = provider.Name()
+			break
+		}
+		if !errors.Is(err, metadata.ErrNotFound) {
+			log.Printf("元数据源 %s 查询 ISBN %s 失败: %v", provider.Name(), isbn, err)
+			lastErr = err
+		}
+	}
+	if meta == nil {
+		if lastErr != nil {
+			return nil, common.ErrMetadataFailed
+		}
+		return nil, common.ErrMetadataNotFound
+	}
+
+	isbn10 := utils.ISBN13To10(isbn)
+	resp := &response.LookupBookResponse{
+		ISBN:     isbn,
+		ISBN10:   isbn10,
+		Source:   source,
+		Pages:    meta.Pages,
+		Subjects: meta.Subjects,
+		Book: response.BookDraft{
+			Title:     truncate(meta.Title, 200),
+			Author:    truncate(strings.Join(meta.Authors, ", "), 100),
+			ISBN:      isbn,
+			Publisher: truncate(meta.Publisher, 100),
+		},
+	}
+	if meta.PublishDate != "" {
+		resp.Book.PublishDate = &meta.PublishDate
+	}
+	if meta.Description != "" {
+		description := truncate(meta.Description, 1000)
+		resp.Book.Description = &description
+	}
+	if meta.CoverURL != "" {
+		resp.Book.CoverURL = &meta.CoverURL
+	}
+
+	// 馆内可能以 ISBN-13 或 ISBN-10 登记过该书
+	for _, candidate := range []string{isbn, isbn10} {
+		if candidate == "" {
+			continue
+		}
+		book, err := s.bookRepo.GetBookByISBN(ctx, candidate)
+		if err == nil {
+			resp.ExistingBookID = &book.ID
+			break
+		}
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, err
+		}
+	}
+
+	return resp, nil
 }
 
 func (s *BookService) CreateBook(ctx context.Context, req *request.CreateBookRequest) (*response.CreateBookResponse, error) {
@@ -29,6 +109,12 @@ func (s *BookService) CreateBook(ctx context.Context, req *request.CreateBookReq
 	} else if !errors.Is(err, gorm.ErrRecordNotFound) {
 		return nil, err
 	}
+	// 回收站中的图书仍占用 ISBN，应恢复原图书而不是重新创建
+	if _, err := s.bookRepo.GetTrashedBookByISBN(ctx, req.ISBN); err == nil {
+		return nil, common.ErrISBNInTrash
+	} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
 
 	category, err := s.categoryRepo.GetCategoryByID(ctx, req.CategoryID)
 	if err != nil {
@@ -67,7 +153,13 @@ func (s *BookService) CreateBook(ctx context.Context, req *request.CreateBookReq
 		book.CoverURL = *req.CoverURL
 	}
 
-	if err := s.bookRepo.CreateBook(ctx, &book); err != nil {
+	err = s.bookRepo.DB().Transaction(func(tx *gorm.DB) error {
+		if err := createBookWithCopies(ctx, tx, s.bookRepo, s.copyRepo, &book); err != nil {
+			return err
+		}
+		return s.auditService.Record(ctx, tx, model.AuditActionCreate, model.AuditEntityBook, book.ID, nil, book)
+	})
+	if err != nil {
 		return nil, err
 	}
 
@@ -91,6 +183,24 @@ func (s *BookService) CreateBook(ctx context.Context, req *request.CreateBookReq
 	return resp, nil
 }
 
+// createBookWithCopies 创建图书，并以 book.Stock 作为初始副本数量按 ISBN 自动生成条码
+func createBookWithCopies(ctx context.Context, tx *gorm.DB, bookRepo *repository.BookRepository, copyRepo *repository.BookCopyRepository, book *model.Book) error {
+	if err := bookRepo.CreateBook(ctx, tx, book); err != nil {
+		return err
+	}
+	for i := 0; i < book.Stock; i++ {
+		bookCopy := model.BookCopy{
+			BookID:  book.ID,
+			Barcode: utils.GenerateBarcode(book.ISBN, i+1),
+			Status:  model.CopyStatusAvailable,
+		}
+		if err := copyRepo.CreateCopy(ctx, tx, &bookCopy); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 func (s *BookService) BatchCreateBook(ctx context.Context, req *request.BatchCreateBookRequest) (*response.BatchCreateBookResponse, error) {
 	resp := &response.BatchCreateBookResponse{
 		FailedItems: make([]response.FailedItems, 0),
@@ -106,6 +216,8 @@ func (s *BookService) BatchCreateBook(ctx context.Context, req *request.BatchCre
 			switch err {
 			case common.ErrISBNExist:
 				fail.Error = "ISBN已存在"
+			case common.ErrISBNInTrash:
+				fail.Error = "ISBN属于回收站中的图书"
 			case common.ErrCategoryNotFound:
 				fail.Error = "分类不存在"
 			default:
@@ -129,6 +241,19 @@ func (s *BookService) GetBookList(ctx context.Context, req *request.GetBookListR
 		req.Limit = 10
 	}
 
+	if req.YearFrom != nil && req.YearTo != nil && *req.YearFrom > *req.YearTo {
+		return nil, common.ErrInvalidYearRange
+	}
+
+	req.CategoryIDs = nil
+	if req.CategoryID != nil && req.IncludeDescendants {
+		ids, err := s.categoryRepo.GetDescendantIDs(ctx, *req.CategoryID)
+		if err != nil {
+			return nil, err
+		}
+		req.CategoryIDs = ids
+	}
+
 	books, count, err := s.bookRepo.GetBookList(ctx, req)
 	if err != nil {
 		return nil, err
@@ -149,16 +274,134 @@ func (s *BookService) GetBookList(ctx context.Context, req *request.GetBookListR
 			Stock:        book.Stock,
 			Available:    book.Stock - book.BorrowCount,
 			CoverURL:     book.CoverURL,
+			CoverThumbnails: book.CoverThumbnails,
 			BorrowCount:  book.BorrowCount,
 		})
 	}
 
-	return &response.GetBookListResponse{
+	resp := &response.GetBookListResponse{
 		Total:      count,
 		Page:       req.Page,
 		Limit:      req.Limit,
 		TotalPages: int(math.Ceil(float64(count) / float64(req.Limit))),
 		Books:      items,
+	}
+
+	if req.IncludeFacets {
+		if req.FacetLimit == 0 {
+			req.FacetLimit = 10
+		}
+		facets, err := s.bookRepo.GetBookFacets(ctx, req, req.FacetLimit)
+		if err != nil {
+			return nil, err
+		}
+		resp.Facets = toBookFacets(facets)
+	}
+
+	return resp, nil
+}
+
+func toBookFacets(f *repository.BookFacets) *response.BookFacets {
+	facets := &response.BookFacets{
+		Categories: toFacetBuckets(f.Categories),
+		Authors:    toFacetBuckets(f.Authors),
+		Publishers: toFacetBuckets(f.Publishers),
+		Years:      make([]response.YearFacetBucket, 0, len(f.Decades)),
+		Availability: response.AvailabilityFacet{
+			Available:   f.Availability.Available,
+			Unavailable: f.Availability.Unavailable,
+		},
+	}
+	for _, d := range f.Decades {
+		facets.Years = append(facets.Years, response.YearFacetBucket{
+			From:  d.Decade,
+			To:    d.Decade + 9,
+			Label: fmt.Sprintf("%d-%d", d.Decade, d.Decade+9),
+			Count: d.Count,
+		})
+	}
+	return facets
+}
+
+func toFacetBuckets(counts []repository.FacetCount) []response.FacetBucket {
+	buckets := make([]response.FacetBucket, 0, len(counts))
+	for _, c := range counts {
+		buckets = append(buckets, response.FacetBucket{
+			Value: c.Key,
+			Label: c.Label,
+			Count: c.Count,
+		})
+	}
+	return buckets
+}
+
+// SearchBooks 全文检索图书，结果按相关度排序并标记命中关键词
+func (s *BookService) SearchBooks(ctx context.Context, req *request.SearchBooksRequest) (*response.SearchBooksResponse, error) {
+	req.Q = strings.TrimSpace(req.Q)
+	if req.Q == "" {
+		return nil, common.ErrEmptySearchQuery
+	}
+	if req.Page == 0 {
+		req.Page = 1
+	}
+	if req.Limit == 0 {
+		req.Limit = 10
+	}
+
+	hits, total, err := s.bookRepo.SearchBooks(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	ids := make([]uint64, 0, len(hits))
+	for _, hit := range hits {
+		ids = append(ids, hit.ID)
+	}
+	books, err := s.bookRepo.GetBooksByIDs(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+	bookMap := make(map[uint64]*model.Book, len(books))
+	for i := range books {
+		bookMap[books[i].ID] = &books[i]
+	}
+
+	terms := utils.SearchTerms(req.Q)
+	items := make([]response.SearchBookItem, 0, len(hits))
+	// 按检索命中顺序组装，保持相关度排序
+	for _, hit := range hits {
+		book, ok := bookMap[hit.ID]
+		if !ok {
+			continue
+		}
+		items = append(items, response.SearchBookItem{
+			ID:           book.ID,
+			Title:        book.Title,
+			Author:       book.Author,
+			ISBN:         book.ISBN,
+			CategoryID:   book.CategoryID,
+			CategoryName: book.Category.Name,
+			Publisher:    book.Publisher,
+			CoverURL:     book.CoverURL,
+			Available:    book.Stock - book.BorrowCount,
+			Rating:       book.Rating,
+			Score:        math.Round(hit.Score*1e4) / 1e4,
+			Highlight: response.BookHighlight{
+				Title:       utils.Highlight(book.Title, terms),
+				Author:      utils.Highlight(book.Author, terms),
+				Publisher:   utils.Highlight(book.Publisher, terms),
+				Description: utils.Snippet(book.Description, terms, 60),
+			},
+		})
+	}
+
+	return &response.SearchBooksResponse{
+		Query:      req.Q,
+		Total:      total,
+		Page:       req.Page,
+		Limit:      req.Limit,
+		TotalPages: int(math.Ceil(float64(total) / float64(req.Limit))),
+		Books:      items,
 	}, nil
 }
 
@@ -194,8 +437,10 @@ func (s *BookService) GetBookDetails(ctx context.Context, id uint64) (*response.
         Available: book.Stock - book.BorrowCount,
         Description: book.Description,
         CoverURL: book.CoverURL,
+        CoverThumbnails: book.CoverThumbnails,
         BorrowCount: book.BorrowCount,
         Rating: book.Rating,
+        ReviewCount: book.ReviewCount,
         CreatedAt: book.CreatedAt,
         UpdatedAt: book.UpdatedAt,
     }
@@ -205,7 +450,7 @@ func (s *BookService) GetBookDetails(ctx context.Context, id uint64) (*response.
 
 func (s *BookService) UpdateBook(ctx context.Context, req *request.UpdateBookRequest, id uint64) (*response.UpdateBookResponse, error) {
     if req.Author == nil && req.CategoryID == nil && req.CoverURL == nil && req.Description == nil && req.ISBN == nil && 
-    req.Price == nil && req.PublishDate == nil && req.Publisher == nil && req.Stock == nil && req.Title == nil {
+    req.Price == nil && req.PublishDate == nil && req.Publisher == nil && req.Title == nil {
         return nil, common.ErrBadRequest
     }
 
@@ -235,6 +480,8 @@ func (s *BookService) UpdateBook(ctx context.Context, req *request.UpdateBookReq
     }
     if req.CoverURL != nil {
         Updates["cover_url"] = *req.CoverURL
+        // 手动指定封面地址后，之前上传生成的缩略图不再对应
+        Updates["cover_thumbnails"] = gorm.Expr("NULL")
     }
     if req.Description != nil {
         Updates["description"] = *req.Description
@@ -251,17 +498,17 @@ func (s *BookService) UpdateBook(ctx context.Context, req *request.UpdateBookReq
     if req.Publisher != nil {
         Updates["publisher"] = *req.Publisher
     }
-    var available *int
-    if req.Stock != nil {
-        Updates["stock"] = *req.Stock
-        avail := *req.Stock - book.BorrowCount
-        available = &avail
-    }
     if req.Title != nil {
         Updates["title"] = *req.Title
     }
 
-    if err := s.bookRepo.UpdateBookFields(ctx, id, Updates); err != nil {
+    err = s.bookRepo.DB().Transaction(func(tx *gorm.DB) error {
+        if err := s.bookRepo.UpdateBookFields(ctx, tx, id, Updates); err != nil {
+            return err
+        }
+        return s.auditService.Record(ctx, tx, model.AuditActionUpdate, model.AuditEntityBook, id, book, Updates)
+    })
+    if err != nil {
         return nil, err
     }
 
@@ -275,8 +522,6 @@ func (s *BookService) UpdateBook(ctx context.Context, req *request.UpdateBookReq
         Publisher: req.Publisher,
         PublishDate: req.PublishDate,
         Price: req.Price,
-        Stock: req.Stock,
-        Available: available,
         Description: req.Description,
         CoverURL: req.CoverURL,
         UpdatedAt: time.Now().UTC().Format(time.RFC3339),
@@ -300,10 +545,81 @@ func (s *BookService) DeleteBook(ctx context.Context, id uint64) error {
 		return bizErr
 	}
 
-	err = s.bookRepo.DeleteBookByID(ctx, id)
+	hasReservation, err := s.reservationRepo.HasActiveReservation(ctx, id)
+	if err != nil {
+		return err
+	}
+	if hasReservation {
+		return common.ErrBookHasReservations
+	}
+
+	// 软删除，副本与评价保留，恢复后原样可用
+	return s.bookRepo.DB().Transaction(func(tx *gorm.DB) error {
+		if err := s.bookRepo.DeleteBookByID(ctx, tx, id); err != nil {
+			return err
+		}
+		return s.auditService.Record(ctx, tx, model.AuditActionDelete, model.AuditEntityBook, id, book, nil)
+	})
+}
+
+// GetBookTrash 分页查询回收站中的图书
+func (s *BookService) GetBookTrash(ctx context.Context, req *request.GetBookTrashRequest) (*response.GetBookTrashResponse, error) {
+	if req.Page <= 0 {
+		req.Page = 1
+	}
+	if req.Limit <= 0 || req.Limit > 100 {
+		req.Limit = 10
+	}
+
+	books, total, err := s.bookRepo.GetTrashedBooks(ctx, req.Page, req.Limit)
+	if err != nil {
+		return nil, err
+	}
+
+	items := make([]response.TrashedBookItem, 0, len(books))
+	for _, b := range books {
+		items = append(items, response.TrashedBookItem{
+			ID:           b.ID,
+			Title:        b.Title,
+			Author:       b.Author,
+			ISBN:         b.ISBN,
+			CategoryID:   b.CategoryID,
+			CategoryName: b.Category.Name,
+			DeletedAt:    b.DeletedAt.Time.UTC().Format(time.RFC3339),
+		})
+	}
+
+	return &response.GetBookTrashResponse{
+		Total:      total,
+		Page:       req.Page,
+		Limit:      req.Limit,
+		TotalPages: int(math.Ceil(float64(total) / float64(req.Limit))),
+		Books:      items,
+	}, nil
+}
+
+// RestoreBook 将图书移出回收站，所属分类必须未被删除
+func (s *BookService) RestoreBook(ctx context.Context, id uint64) error {
+	book, err := s.bookRepo.GetTrashedBookByID(ctx, id)
 	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return common.ErrTrashedBookNotFound
+		}
 		return err
 	}
 
-	return nil
+	if _, err := s.categoryRepo.GetCategoryByID(ctx, book.CategoryID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return common.ErrBookCategoryDeleted
+		}
+		return err
+	}
+
+	return s.bookRepo.DB().Transaction(func(tx *gorm.DB) error {
+		if err := s.bookRepo.RestoreBook(ctx, tx, id); err != nil {
+			return err
+		}
+		return s.auditService.Record(ctx, tx, model.AuditActionRestore, model.AuditEntityBook, id,
+			map[string]interface{}{"deleted_at": book.DeletedAt.Time}, map[string]interface{}{"deleted_at": nil})
+	})
 }
\ No newline at end of file
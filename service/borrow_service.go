@@ -3,6 +3,7 @@ package service
 import (
 	"context"
 	"errors"
+	"fmt"
 	"library-system/common"
 	"library-system/dto/request"
 	"library-system/dto/response"
@@ -10,6 +11,7 @@ import (
 	"library-system/repository"
 	"log"
 	"math"
+	"strings"
 	"time"
 
 	"gorm.io/gorm"
@@ -24,6 +26,12 @@ const (
 
 	// 最大续借次数
 	MaxRenewCount = 2
+
+	// 遗失赔偿：按图书定价全额赔偿
+	LostCompensationRate = 1.0
+
+	// 损坏赔偿：按图书定价的 30% 收取维修费
+	DamagedCompensationRate = 0.3
 )
 
 type BorrowService struct {
@@ -33,6 +41,12 @@ type BorrowService struct {
 	overdueService *OverdueService
 	reservationService *ReservationService
 	reservationRepo *repository.ReservationRepository
+	copyRepo       *repository.BookCopyRepository
+	policyService  *LoanPolicyService
+	fineService    *FineService
+	notificationService *NotificationService
+	eventService   *EventService
+	auditService   *AuditService
 }
 
 func NewBorrowService(
@@ -42,6 +56,12 @@ func NewBorrowService(
 	reservationRepo *repository.ReservationRepository,
 	reservationService *ReservationService,
 	overdueService *OverdueService,
+	copyRepo *repository.BookCopyRepository,
+	policyService *LoanPolicyService,
+	fineService *FineService,
+	notificationService *NotificationService,
+	eventService *EventService,
+	auditService *AuditService,
 ) *BorrowService {
 	return &BorrowService{
 		borrowRepo:     borrowRepo,
@@ -50,10 +70,91 @@ func NewBorrowService(
 		reservationRepo: reservationRepo,
 		reservationService: reservationService,
 		overdueService: overdueService,
+		copyRepo:       copyRepo,
+		policyService:  policyService,
+		fineService:    fineService,
+		notificationService: notificationService,
+		eventService:   eventService,
+		auditService:   auditService,
 	}
 }
 
 func (s *BorrowService) BorrowBook(ctx context.Context, userID uint64, req *request.BorrowBookRequest) (*response.BorrowBookResponse, error) {
+	return s.borrowBook(ctx, userID, nil, req)
+}
+
+// CheckoutForPatron 馆员在流通台为指定读者借书，与自助借书执行相同的数量、逾期、罚金与预约检查
+func (s *BorrowService) CheckoutForPatron(ctx context.Context, staffID uint64, req *request.CheckoutRequest) (*response.BorrowBookResponse, error) {
+	patron, err := s.resolvePatron(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	if patron.Status == model.UserStatusDisabled {
+		return nil, common.ErrUserDisabled
+	}
+
+	return s.borrowBook(ctx, patron.ID, &staffID, &request.BorrowBookRequest{
+		BookId:     req.BookId,
+		Barcode:    req.Barcode,
+		BorrowDays: req.BorrowDays,
+	})
+}
+
+// resolvePatron 按用户 ID、用户名或借书证号查找读者，三者必须且只能提供一个
+func (s *BorrowService) resolvePatron(ctx context.Context, req *request.CheckoutRequest) (model.User, error) {
+	provided := 0
+	for _, set := range []bool{req.UserID != nil, req.Username != nil, req.CardNumber != nil} {
+		if set {
+			provided++
+		}
+	}
+	if provided != 1 {
+		return model.User{}, common.ErrBadRequest
+	}
+
+	var patron model.User
+	var err error
+	switch {
+	case req.UserID != nil:
+		patron, err = s.userRepo.GetUserByUserID(ctx, *req.UserID)
+	case req.Username != nil:
+		patron, err = s.userRepo.GetUserByUsername(ctx, *req.Username)
+	default:
+		patron, err = s.userRepo.GetUserByCardNumber(ctx, *req.CardNumber)
+	}
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return model.User{}, common.ErrPatronNotFound
+		}
+		return model.User{}, err
+	}
+	return patron, nil
+}
+
+// borrowBook 借书，staffID 为代借的馆员，自助借书时为 nil
+func (s *BorrowService) borrowBook(ctx context.Context, userID uint64, staffID *uint64, req *request.BorrowBookRequest) (*response.BorrowBookResponse, error) {
+	if req.BookId == 0 && req.Barcode == nil {
+		return nil, common.ErrBadRequest
+	}
+
+	// 扫码借书：通过条码确定图书和副本
+	bookID := req.BookId
+	var scannedCopyID uint64
+	if req.Barcode != nil {
+		scanned, err := s.copyRepo.GetCopyByBarcode(ctx, *req.Barcode)
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return nil, common.ErrCopyNotFound
+			}
+			return nil, err
+		}
+		if bookID != 0 && bookID != scanned.BookID {
+			return nil, common.ErrBadRequest
+		}
+		bookID = scanned.BookID
+		scannedCopyID = scanned.ID
+	}
+
 	// 借书前先刷新逾期记录
 	err := s.overdueService.RefreshSingleUserOverdue(ctx, userID)
 	if err != nil {
@@ -65,7 +166,7 @@ func (s *BorrowService) BorrowBook(ctx context.Context, userID uint64, req *requ
 
 	err = s.borrowRepo.DB().Transaction(func(tx *gorm.DB) error {
 		// 检查该用户是否有该书的有效预约
-        reservation, err := s.reservationRepo.GetUserReservationForBook(ctx, userID, req.BookId)
+        reservation, err := s.reservationRepo.GetUserReservationForBook(ctx, userID, bookID)
         if err == nil && reservation.Status == model.ReservationStatusAvailable {
             // 用户有有效预约，标记为已完成
             now := time.Now()
@@ -76,7 +177,7 @@ func (s *BorrowService) BorrowBook(ctx context.Context, userID uint64, req *requ
             s.reservationRepo.UpdateReservationStatus(ctx, tx, reservation.ID, updates)
         } else if errors.Is(err, gorm. ErrRecordNotFound) {
             // 没有预约，检查是否有其他人预约
-            hasReservation, _ := s.reservationRepo.HasActiveReservation(ctx, req.BookId)
+            hasReservation, _ := s.reservationRepo.HasActiveReservation(ctx, bookID)
             if hasReservation {
                 // 有人预约但不是当前用户
                 return common.ErrHasReservation
@@ -95,7 +196,11 @@ func (s *BorrowService) BorrowBook(ctx context.Context, userID uint64, req *requ
 			return common.ErrHasOverdueBooks
 		}
 
-		book, err := s.bookRepo.GetBookByIDWithLock(ctx, tx, req.BookId)
+		if err := s.fineService.CheckBorrowAllowed(ctx, tx, userID); err != nil {
+			return err
+		}
+
+		book, err := s.bookRepo.GetBookByIDWithLock(ctx, tx, bookID)
 		if err != nil {
 			if errors.Is(err, gorm.ErrRecordNotFound) {
 				return common.ErrBookNotFound
@@ -107,26 +212,69 @@ func (s *BorrowService) BorrowBook(ctx context.Context, userID uint64, req *requ
 			return common.ErrBookOutOfStock
 		}
 
-		nowDate := time.Now().UTC()
-		var dueDate time.Time
-		if req.BorrowDays != nil {
-			dueDate = nowDate.AddDate(0, 0, *req.BorrowDays)
+		policy, err := s.policyService.Resolve(ctx, user.Role, book.CategoryID)
+		if err != nil {
+			return err
+		}
+
+		// 规则限定的同时在借数量（分类规则只统计该分类）
+		if policy.MaxLoans > 0 {
+			active, err := s.borrowRepo.CountActiveBorrows(ctx, tx, userID, policy.CategoryID)
+			if err != nil {
+				return err
+			}
+			if active >= int64(policy.MaxLoans) {
+				if policy.CategoryID != nil {
+					return common.ErrCategoryLoanLimitReached
+				}
+				return common.ErrBorrowLimitReached
+			}
+		}
+
+		var bookCopy model.BookCopy
+		if scannedCopyID != 0 {
+			bookCopy, err = s.copyRepo.GetCopyByIDWithLock(ctx, tx, scannedCopyID)
+			if err != nil {
+				return err
+			}
+			if bookCopy.Status != model.CopyStatusAvailable {
+				return common.ErrCopyNotAvailable
+			}
 		} else {
-			dueDate = nowDate.AddDate(0, 0, DefaultBorrowDays)
+			bookCopy, err = s.copyRepo.GetAvailableCopyWithLock(ctx, tx, bookID)
+			if err != nil {
+				if errors.Is(err, gorm.ErrRecordNotFound) {
+					return common.ErrBookOutOfStock
+				}
+				return err
+			}
+		}
+		if err := s.copyRepo.UpdateCopyFields(ctx, tx, bookCopy.ID, map[string]interface{}{"status": model.CopyStatusOnLoan}); err != nil {
+			return err
+		}
+
+		// 可以申请更短的借期，但不能超过规则允许的天数
+		nowDate := time.Now().UTC()
+		borrowDays := policy.LoanDays
+		if req.BorrowDays != nil && *req.BorrowDays > 0 && *req.BorrowDays < borrowDays {
+			borrowDays = *req.BorrowDays
 		}
+		dueDate := nowDate.AddDate(0, 0, borrowDays)
 
 		borrow := model.BorrowRecord{
-			BookID:  req.BookId,
+			BookID:  bookID,
 			UserID:  userID,
+			CopyID:  &bookCopy.ID,
 			DueDate: dueDate,
 			Status:  "borrowed",
+			CheckoutStaffID: staffID,
 		}
 
 		if err := s.borrowRepo.CreateBorrowRecord(ctx, tx, &borrow); err != nil {
 			return err
 		}
 
-		if err := s.bookRepo.IncreaseBorrowCount(ctx, tx, req.BookId, 1); err != nil {
+		if err := s.bookRepo.IncreaseBorrowCount(ctx, tx, bookID, 1); err != nil {
 			return err
 		}
 
@@ -134,17 +282,24 @@ func (s *BorrowService) BorrowBook(ctx context.Context, userID uint64, req *requ
 			return err
 		}
 
+		if err := s.auditService.Record(ctx, tx, model.AuditActionBorrow, model.AuditEntityBorrow, borrow.ID, nil, borrow); err != nil {
+			return err
+		}
+
 		resp = &response.BorrowBookResponse{
 			ID:            borrow.ID,
 			BookID:        borrow.BookID,
 			BookTitle:     book.Title,
+			CopyID:        bookCopy.ID,
+			Barcode:       bookCopy.Barcode,
 			UserID:        userID,
 			Username:      user.Username,
 			BorrowDate:    nowDate,
 			DueDate:       dueDate,
 			Status:        borrow.Status,
 			RenewCount:    0,
-			MaxRenewCount: MaxRenewCount,
+			MaxRenewCount: policy.MaxRenewCount,
+			CheckoutStaffID: staffID,
 		}
 
 		return nil
@@ -154,10 +309,23 @@ func (s *BorrowService) BorrowBook(ctx context.Context, userID uint64, req *requ
 		return nil, err
 	}
 
+	s.eventService.Publish(ctx, EventBookBorrowed, userID, map[string]interface{}{
+		"borrow_id":  resp.ID,
+		"book_id":    resp.BookID,
+		"book_title": resp.BookTitle,
+		"barcode":    resp.Barcode,
+		"due_date":   resp.DueDate,
+	})
+
 	return resp, nil
 }
 
-func (s *BorrowService) ReturnBook(ctx context.Context, borrowID uint64, req *request.ReturnBookRequest) (*response.ReturnBookResponse, error) {
+// ReturnBook 读者自助还书，只能归还本人的借阅
+func (s *BorrowService) ReturnBook(ctx context.Context, userID, borrowID uint64, req *request.ReturnBookRequest) (*response.ReturnBookResponse, error) {
+	if err := checkSelfReturnCondition(req.Condition); err != nil {
+		return nil, err
+	}
+
 	borrow, err := s.borrowRepo.GetBorrowRecordByID(ctx, borrowID)
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
@@ -165,37 +333,112 @@ func (s *BorrowService) ReturnBook(ctx context.Context, borrowID uint64, req *re
 		}
 		return nil, err
 	}
-	if borrow.ReturnDate != nil || borrow.Status == "returned" {
-		return nil, &common.BizError{
-			Code:    400,
-			Message: "该图书已归还",
-		}
+	if borrow.UserID != userID {
+		return nil, common.ErrNotBorrower
 	}
 
-	var isOverdue bool
-	now := time.Now().UTC()
-	if now.After(borrow.DueDate) {
-		isOverdue = true
+	return s.returnBook(ctx, borrowID, nil, req)
+}
+
+// checkSelfReturnCondition 自助还书只能按完好归还，损坏、遗失产生赔偿，须由馆员在流通台登记
+func checkSelfReturnCondition(condition *string) error {
+	if condition != nil && *condition != model.ReturnConditionGood {
+		return common.ErrSelfReturnCondition
+	}
+	return nil
+}
+
+// CheckinBook 馆员在流通台按借阅 ID 或副本条码办理还书，两者必须且只能提供一个
+func (s *BorrowService) CheckinBook(ctx context.Context, staffID uint64, req *request.CheckinRequest) (*response.ReturnBookResponse, error) {
+	if (req.BorrowID == nil) == (req.Barcode == nil) {
+		return nil, common.ErrBadRequest
+	}
+
+	var borrowID uint64
+	if req.BorrowID != nil {
+		borrowID = *req.BorrowID
 	} else {
-		isOverdue = false
+		borrow, err := s.getActiveBorrowByBarcode(ctx, *req.Barcode)
+		if err != nil {
+			return nil, err
+		}
+		borrowID = borrow.ID
+	}
+
+	return s.returnBook(ctx, borrowID, &staffID, &request.ReturnBookRequest{
+		Condition: req.Condition,
+		Remark:    req.Remark,
+	})
+}
+
+// returnBook 还书，staffID 为代还的馆员，自助还书时为 nil
+func (s *BorrowService) returnBook(ctx context.Context, borrowID uint64, staffID *uint64, req *request.ReturnBookRequest) (*response.ReturnBookResponse, error) {
+	borrow, err := s.borrowRepo.GetBorrowRecordByID(ctx, borrowID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, common.ErrBorrowNotFound
+		}
+		return nil, err
+	}
+	if isReturned(borrow) {
+		return nil, errAlreadyReturned()
 	}
 
+	var isOverdue bool
+	now := time.Now().UTC()
 	overdueDays := 0
 
+	condition := model.ReturnConditionGood
+	if req.Condition != nil {
+		condition = *req.Condition
+	}
+
 	var resp *response.ReturnBookResponse
+	var bookTitle string
+	var nextReservation *model.Reservation
 
 	err = s.borrowRepo.DB().Transaction(func(tx *gorm.DB) error {
+		// 加锁后重新检查，防止并发还书重复计费、重复扣减计数
+		borrow, err = s.borrowRepo.GetBorrowRecordByIDWithLock(ctx, tx, borrowID)
+		if err != nil {
+			return err
+		}
+		if isReturned(borrow) {
+			return errAlreadyReturned()
+		}
+		isOverdue = now.After(borrow.DueDate)
+
+		book, err := s.bookRepo.GetBookByIDWithLock(ctx, tx, borrow.BookID)
+		if err != nil {
+			return err
+		}
+		user, err := s.userRepo.GetUserByUserID(ctx, borrow.UserID)
+		if err != nil {
+			return err
+		}
+		policy, err := s.policyService.Resolve(ctx, user.Role, book.CategoryID)
+		if err != nil {
+			return err
+		}
+
 		updates := map[string]interface{}{
-			"return_date": now,
-			"status":      "returned",
+			"return_date":      now,
+			"status":           "returned",
+			"return_condition": condition,
 		}
-		var fine float64
+		if req.Remark != nil {
+			updates["return_remark"] = *req.Remark
+		}
+		if staffID != nil {
+			updates["checkin_staff_id"] = *staffID
+		}
+
+		var overdueFine float64
 		if isOverdue {
 			// 计算逾期天数
 			overdueDays = int(now.Sub(borrow.DueDate).Hours() / 24)
-			// 计算罚金（1元/天）
-			fine = float64(overdueDays) * 1.0
-			updates["fine"] = fine
+			// 按借阅规则计算罚金
+			overdueFine = calcOverdueFine(policy, overdueDays)
 
 			if borrow.Status == "overdue" {
 				if err := s.userRepo.DecreaseOverDueCount(ctx, tx, borrow.UserID, 1); err != nil {
@@ -210,28 +453,63 @@ func (s *BorrowService) ReturnBook(ctx context.Context, borrowID uint64, req *re
 		if err := s.bookRepo.DecreaseBorrowCount(ctx, tx, borrow.BookID, 1); err != nil {
 			return err
 		}
+		// 损坏/遗失按定价计入赔偿
+		compensation := compensationFee(condition, book.Price)
+		fine := overdueFine + compensation
+		if fine > 0 {
+			updates["fine"] = fine
+			updates["compensation_fee"] = compensation
+		}
+
 		if err := s.borrowRepo.UpdateFields(ctx, tx, borrowID, updates); err != nil {
 			return err
 		}
+		// 操作人可能是代为还书的馆员，借阅人见借阅记录
+		if err := s.auditService.Record(ctx, tx, model.AuditActionReturn, model.AuditEntityBorrow, borrowID, borrow, updates); err != nil {
+			return err
+		}
+		if fine > 0 {
+			reason := returnChargeReason(overdueDays, condition)
+			if err := s.fineService.PostCharge(ctx, tx, borrow.UserID, borrowID, fine, reason); err != nil {
+				return err
+			}
+			if err := s.notificationService.NotifyFineCharged(ctx, tx, user, borrowID, book.Title, fine, reason); err != nil {
+				return err
+			}
+		}
+		if err := s.releaseCopy(ctx, tx, &borrow, copyStatusAfterReturn(condition)); err != nil {
+			return err
+		}
 
-		// 新增：还书后通知下一个预约者
-        if err := s.reservationService.NotifyNextReservation(ctx, tx, borrow.BookID); err != nil {
+		if condition != model.ReturnConditionGood {
+			// 副本退出流通，馆藏总量随之减少；没有副本回到书架，无需通知预约者
+			if err := s.copyRepo.SyncBookStock(ctx, tx, borrow.BookID); err != nil {
+				return err
+			}
+		} else if nextReservation, err = s.reservationService.NotifyNextReservation(ctx, tx, borrow.BookID); err != nil {
+			// 新增：还书后通知下一个预约者
             log.Printf("通知预约者失败: %v", err)
             // 不中断还书流程
         }
+		bookTitle = book.Title
 
 		resp = &response.ReturnBookResponse{
-			ID:          borrowID,
-			BookID:      borrow.BookID,
-			UserID:      borrow.UserID,
-			BorrowDate:  borrow.BorrowDate,
-			DueDate:     borrow.DueDate,
-			ReturnDate:  now,
-			Status:      "returned",
-			IsOverdue:   isOverdue,
-			OverdueDays: overdueDays,
-			Fine:        fine,
-			Condition:   req.Condition,
+			ID:           borrowID,
+			BookID:       borrow.BookID,
+			CopyID:       borrow.CopyID,
+			UserID:       borrow.UserID,
+			BorrowDate:   borrow.BorrowDate,
+			DueDate:      borrow.DueDate,
+			ReturnDate:   now,
+			Status:       "returned",
+			IsOverdue:    isOverdue,
+			OverdueDays:  overdueDays,
+			OverdueFine:  overdueFine,
+			Compensation: compensation,
+			Fine:         fine,
+			Condition:    condition,
+			Remark:       req.Remark,
+			CheckinStaffID: staffID,
 		}
 
 		return nil
@@ -241,9 +519,136 @@ func (s *BorrowService) ReturnBook(ctx context.Context, borrowID uint64, req *re
 		return nil, err
 	}
 
+	s.eventService.Publish(ctx, EventBookReturned, resp.UserID, map[string]interface{}{
+		"borrow_id":  resp.ID,
+		"book_id":    resp.BookID,
+		"book_title": bookTitle,
+		"condition":  resp.Condition,
+		"is_overdue": resp.IsOverdue,
+	})
+	if resp.Fine > 0 {
+		s.eventService.Publish(ctx, EventFineCharged, resp.UserID, map[string]interface{}{
+			"borrow_id":    resp.ID,
+			"book_title":   bookTitle,
+			"overdue_fine": resp.OverdueFine,
+			"compensation": resp.Compensation,
+			"amount":       resp.Fine,
+		})
+	}
+	if nextReservation != nil {
+		s.reservationService.PublishReservationAvailable(ctx, nextReservation)
+	}
+
 	return resp, nil
 }
 
+func isReturned(borrow model.BorrowRecord) bool {
+	return borrow.ReturnDate != nil || borrow.Status == "returned"
+}
+
+func errAlreadyReturned() error {
+	return &common.BizError{
+		Code:    400,
+		Message: "该图书已归还",
+	}
+}
+
+// ReturnBookByBarcode 扫码还书：按副本条码找到本人的在借记录后归还
+func (s *BorrowService) ReturnBookByBarcode(ctx context.Context, userID uint64, req *request.ReturnByBarcodeRequest) (*response.ReturnBookResponse, error) {
+	if err := checkSelfReturnCondition(req.Condition); err != nil {
+		return nil, err
+	}
+
+	borrow, err := s.getActiveBorrowByBarcode(ctx, req.Barcode)
+	if err != nil {
+		return nil, err
+	}
+	if borrow.UserID != userID {
+		return nil, common.ErrNotBorrower
+	}
+
+	return s.returnBook(ctx, borrow.ID, nil, &request.ReturnBookRequest{
+		Condition: req.Condition,
+		Remark:    req.Remark,
+	})
+}
+
+// getActiveBorrowByBarcode 按副本条码查找未归还的借阅记录
+func (s *BorrowService) getActiveBorrowByBarcode(ctx context.Context, barcode string) (model.BorrowRecord, error) {
+	bookCopy, err := s.copyRepo.GetCopyByBarcode(ctx, barcode)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return model.BorrowRecord{}, common.ErrCopyNotFound
+		}
+		return model.BorrowRecord{}, err
+	}
+
+	borrow, err := s.borrowRepo.GetActiveBorrowByCopyID(ctx, bookCopy.ID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return model.BorrowRecord{}, common.ErrBorrowNotFound
+		}
+		return model.BorrowRecord{}, err
+	}
+	return borrow, nil
+}
+
+// releaseCopy 归还后更新副本状态；迁移前的旧记录没有关联副本，释放一本未关联的借出副本
+func (s *BorrowService) releaseCopy(ctx context.Context, tx *gorm.DB, borrow *model.BorrowRecord, status string) error {
+	copyID := borrow.CopyID
+	if copyID == nil {
+		legacy, err := s.copyRepo.GetUnlinkedLoanCopyWithLock(ctx, tx, borrow.BookID)
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return nil
+			}
+			return err
+		}
+		copyID = &legacy.ID
+	}
+
+	return s.copyRepo.UpdateCopyFields(ctx, tx, *copyID, map[string]interface{}{"status": status})
+}
+
+// compensationFee 按归还状况计算赔偿金额
+func compensationFee(condition string, price float64) float64 {
+	switch condition {
+	case model.ReturnConditionLost:
+		return math.Round(price*LostCompensationRate*100) / 100
+	case model.ReturnConditionDamaged:
+		return math.Round(price*DamagedCompensationRate*100) / 100
+	default:
+		return 0
+	}
+}
+
+// returnChargeReason 生成归还结算罚金流水的说明
+func returnChargeReason(overdueDays int, condition string) string {
+	var parts []string
+	if overdueDays > 0 {
+		parts = append(parts, fmt.Sprintf("逾期%d天", overdueDays))
+	}
+	switch condition {
+	case model.ReturnConditionLost:
+		parts = append(parts, "图书遗失赔偿")
+	case model.ReturnConditionDamaged:
+		parts = append(parts, "图书损坏赔偿")
+	}
+	return strings.Join(parts, "，")
+}
+
+// copyStatusAfterReturn 归还后副本的去向：损坏送修，遗失下架
+func copyStatusAfterReturn(condition string) string {
+	switch condition {
+	case model.ReturnConditionLost:
+		return model.CopyStatusLost
+	case model.ReturnConditionDamaged:
+		return model.CopyStatusInRepair
+	default:
+		return model.CopyStatusAvailable
+	}
+}
+
 func (s *BorrowService) RenewBorrow(ctx context.Context, userID uint64, borrowID uint64, req *request.RenewBorrowRequest) (*response.RenewBorrowResponse, error) {
 	// 刷新逾期记录
 	err := s.overdueService.RefreshSingleUserOverdue(ctx, userID)
@@ -260,7 +665,16 @@ func (s *BorrowService) RenewBorrow(ctx context.Context, userID uint64, borrowID
 		return nil, err
 	}
 
-	if record.RenewCount >= MaxRenewCount {
+	borrower, err := s.userRepo.GetUserByUserID(ctx, record.UserID)
+	if err != nil {
+		return nil, err
+	}
+	policy, err := s.policyService.Resolve(ctx, borrower.Role, record.Book.CategoryID)
+	if err != nil {
+		return nil, err
+	}
+
+	if record.RenewCount >= policy.MaxRenewCount {
 		return nil, common.ErrRenewLimitReached
 	}
 
@@ -277,8 +691,9 @@ func (s *BorrowService) RenewBorrow(ctx context.Context, userID uint64, borrowID
 
 	var resp *response.RenewBorrowResponse
 
-	renewDays := DefaultBorrowDays
-	if req.RenewDays != nil {
+	// 续借天数不超过规则允许的天数
+	renewDays := policy.RenewDays
+	if req.RenewDays != nil && *req.RenewDays < renewDays {
 		renewDays = *req.RenewDays
 	}
 
@@ -287,8 +702,15 @@ func (s *BorrowService) RenewBorrow(ctx context.Context, userID uint64, borrowID
 	updates := map[string]interface{}{
 		"renew_count": renewCount,
 		"due_date":    newDueDate,
+		"reminded_at": nil, // 到期日变了，重新提醒
 	}
-	if err := s.borrowRepo.UpdateFields(ctx, s.borrowRepo.DB(), borrowID, updates); err != nil {
+	err = s.borrowRepo.DB().Transaction(func(tx *gorm.DB) error {
+		if err := s.borrowRepo.UpdateFields(ctx, tx, borrowID, updates); err != nil {
+			return err
+		}
+		return s.auditService.Record(ctx, tx, model.AuditActionRenew, model.AuditEntityBorrow, borrowID, record, updates)
+	})
+	if err != nil {
 		return nil, err
 	}
 
@@ -299,9 +721,17 @@ func (s *BorrowService) RenewBorrow(ctx context.Context, userID uint64, borrowID
 		NewDueDate:      newDueDate,
 		BookTitle:       record.Book.Title,
 		RenewCount:      renewCount,
-		MaxRenewCount:   MaxRenewCount,
+		MaxRenewCount:   policy.MaxRenewCount,
 	}
 
+	s.eventService.Publish(ctx, EventLoanRenewed, record.UserID, map[string]interface{}{
+		"borrow_id":    resp.ID,
+		"book_id":      resp.BookID,
+		"book_title":   resp.BookTitle,
+		"new_due_date": resp.NewDueDate,
+		"renew_count":  resp.RenewCount,
+	})
+
 	return resp, nil
 }
 
@@ -345,6 +775,11 @@ func (s *BorrowService) GetBorrowRecordList(ctx context.Context, req *request.Ge
 		return nil, err
 	}
 
+	policies, err := s.policyService.LoadAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+
 	items := make([]response.GetBorrowRecordItemResponse, 0, len(records))
 	for _, record := range records {
 		item := response.GetBorrowRecordItemResponse{
@@ -366,6 +801,8 @@ func (s *BorrowService) GetBorrowRecordList(ctx context.Context, req *request.Ge
 			Status:     record.Status,
 			RenewCount: record.RenewCount,
 			Fine:       record.Fine,
+			CheckoutStaffID: record.CheckoutStaffID,
+			CheckinStaffID:  record.CheckinStaffID,
 		}
 
 		if record.Status == "overdue" {
@@ -377,7 +814,8 @@ func (s *BorrowService) GetBorrowRecordList(ctx context.Context, req *request.Ge
 			item.DaysUntilDue = DaysFromToday(record.DueDate)
 		}
 
-		if record.RenewCount < MaxRenewCount && record.Status == "borrowed" {
+		policy := matchLoanPolicy(policies, record.User.Role, record.Book.CategoryID)
+		if record.RenewCount < policy.MaxRenewCount && record.Status == "borrowed" {
 			item.CanRenew = true
 		}
 		items = append(items, item)
@@ -401,6 +839,11 @@ func (s *BorrowService) GetCurrentRecord(ctx context.Context, userID uint64) (*r
 		return nil, common.ErrBorrowNotFound
 	}
 
+	policies, err := s.policyService.LoadAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+
 	var items []response.GetBorrowRecordItemResponse
 	var totalFine float64 = 0
 
@@ -436,7 +879,8 @@ func (s *BorrowService) GetCurrentRecord(ctx context.Context, userID uint64) (*r
 			item.DaysUntilDue = DaysFromToday(record.DueDate)
 		}
 
-		if record.RenewCount < MaxRenewCount && record.Status == "borrowed" {
+		policy := matchLoanPolicy(policies, record.User.Role, record.Book.CategoryID)
+		if record.RenewCount < policy.MaxRenewCount && record.Status == "borrowed" {
 			item.CanRenew = true
 		}
 		items = append(items, item)
@@ -444,11 +888,18 @@ func (s *BorrowService) GetCurrentRecord(ctx context.Context, userID uint64) (*r
 
 	user := records[0].User
 
+	// 已归还记录的罚金以流水余额为准
+	outstanding, err := s.fineService.GetBalance(ctx, s.borrowRepo.DB(), userID)
+	if err != nil {
+		return nil, err
+	}
+
 	return &response.GetCurrentRecordResponse{
 		BorrowingCount: user.BorrowingCount,
 		BorrowLimit:user.BorrowLimit,
 		OverdueCount: user.OverdueCount,
 		TotalFine: totalFine,
+		OutstandingFine: outstanding,
 		Records: items,
 	}, nil
 }
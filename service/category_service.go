@@ -14,11 +14,13 @@ import (
 
 type CategoryService struct {
 	categoryRepo *repository. CategoryRepository
+	auditService *AuditService
 }
 
-func NewCategoryService(categoryRepo *repository.CategoryRepository) *CategoryService {
+func NewCategoryService(categoryRepo *repository.CategoryRepository, auditService *AuditService) *CategoryService {
 	return &CategoryService{
 		categoryRepo: categoryRepo,
+		auditService: auditService,
 	}
 }
 
@@ -63,6 +65,73 @@ func (s *CategoryService) GetCategoryList(ctx context.Context, req *request.GetC
 	return &response. GetCategoryListResponse{Categories: items}, nil
 }
 
+// GetCategoryTree 获取分类树，图书数量逐级向上汇总
+func (s *CategoryService) GetCategoryTree(ctx context.Context) (*response.GetCategoryTreeResponse, error) {
+	categories, err := s.categoryRepo.GetCategoryList(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	counts, err := s.categoryRepo.GetBookCountsByCategory(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	nodes := make(map[uint]*response.CategoryTreeNode, len(categories))
+	for _, cat := range categories {
+		nodes[cat.ID] = &response.CategoryTreeNode{
+			ID:          cat.ID,
+			Name:        cat.Name,
+			Description: cat.Description,
+			ParentID:    cat.ParentID,
+			BookCount:   counts[cat.ID],
+			Children:    []*response.CategoryTreeNode{},
+		}
+	}
+
+	children := make(map[uint][]*response.CategoryTreeNode, len(categories))
+	for _, cat := range categories {
+		if cat.ParentID != nil {
+			if _, ok := nodes[*cat.ParentID]; ok {
+				children[*cat.ParentID] = append(children[*cat.ParentID], nodes[cat.ID])
+			}
+		}
+	}
+
+	visited := make(map[uint]bool, len(categories))
+	var build func(node *response.CategoryTreeNode) int64
+	build = func(node *response.CategoryTreeNode) int64 {
+		visited[node.ID] = true
+		node.TotalBookCount = node.BookCount
+		for _, child := range children[node.ID] {
+			if visited[child.ID] {
+				continue
+			}
+			node.Children = append(node.Children, child)
+			node.TotalBookCount += build(child)
+		}
+		return node.TotalBookCount
+	}
+
+	// 先从顶级分类（无父分类或父分类已不存在）构建，
+	// 再把历史数据中成环而无法到达的分类作为顶级节点补上
+	roots := make([]*response.CategoryTreeNode, 0)
+	for _, cat := range categories {
+		if cat.ParentID == nil || nodes[*cat.ParentID] == nil {
+			build(nodes[cat.ID])
+			roots = append(roots, nodes[cat.ID])
+		}
+	}
+	for _, cat := range categories {
+		if !visited[cat.ID] {
+			build(nodes[cat.ID])
+			roots = append(roots, nodes[cat.ID])
+		}
+	}
+
+	return &response.GetCategoryTreeResponse{Categories: roots}, nil
+}
+
 func (s *CategoryService) GetCategoryDetail(ctx context.Context, id uint) (*response.GetCategoryDetailResponse, error) {
 	category, err := s.categoryRepo.GetCategoryByID(ctx, id)
 	if err != nil {
@@ -144,7 +213,13 @@ func (s *CategoryService) CreateCategory(ctx context.Context, req *request.Creat
 		category.Description = *req.Description
 	}
 
-	if err := s. categoryRepo.CreateCategory(ctx, &category); err != nil {
+	err := s.categoryRepo.DB().Transaction(func(tx *gorm.DB) error {
+		if err := s.categoryRepo.CreateCategory(ctx, tx, &category); err != nil {
+			return err
+		}
+		return s.auditService.Record(ctx, tx, model.AuditActionCreate, model.AuditEntityCategory, uint64(category.ID), nil, category)
+	})
+	if err != nil {
 		return nil, err
 	}
 
@@ -205,6 +280,16 @@ func (s *CategoryService) UpdateCategory(ctx context.Context, id uint, req *requ
 				HTTPStatus: 400,
 			}
 		}
+		// 新父分类不能是自己的子孙分类，否则会形成环
+		descendants, err := s.categoryRepo.GetDescendantIDs(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		for _, descendantID := range descendants {
+			if descendantID == *req.ParentID {
+				return nil, common.ErrCategoryCycle
+			}
+		}
 		updates["parent_id"] = *req. ParentID
 	}
 
@@ -216,7 +301,13 @@ func (s *CategoryService) UpdateCategory(ctx context.Context, id uint, req *requ
 		}
 	}
 
-	if err := s.categoryRepo.UpdateCategory(ctx, id, updates); err != nil {
+	err = s.categoryRepo.DB().Transaction(func(tx *gorm.DB) error {
+		if err := s.categoryRepo.UpdateCategory(ctx, tx, id, updates); err != nil {
+			return err
+		}
+		return s.auditService.Record(ctx, tx, model.AuditActionUpdate, model.AuditEntityCategory, uint64(id), category, updates)
+	})
+	if err != nil {
 		return nil, err
 	}
 
@@ -232,7 +323,8 @@ func (s *CategoryService) UpdateCategory(ctx context.Context, id uint, req *requ
 
 func (s *CategoryService) DeleteCategory(ctx context. Context, id uint) error {
 	// 检查分类是否存在
-	if _, err := s.categoryRepo.GetCategoryByID(ctx, id); err != nil {
+	category, err := s.categoryRepo.GetCategoryByID(ctx, id)
+	if err != nil {
 		if errors. Is(err, gorm.ErrRecordNotFound) {
 			return common. ErrCategoryNotFound
 		}
@@ -270,5 +362,68 @@ func (s *CategoryService) DeleteCategory(ctx context. Context, id uint) error {
 		})
 	}
 
-	return s.categoryRepo. DeleteCategory(ctx, id)
-}
\ No newline at end of file
+	return s.categoryRepo.DB().Transaction(func(tx *gorm.DB) error {
+		if err := s.categoryRepo.DeleteCategory(ctx, tx, id); err != nil {
+			return err
+		}
+		return s.auditService.Record(ctx, tx, model.AuditActionDelete, model.AuditEntityCategory, uint64(id), category, nil)
+	})
+}
+// GetCategoryTrash 获取回收站中的分类
+func (s *CategoryService) GetCategoryTrash(ctx context.Context) (*response.GetCategoryTrashResponse, error) {
+	categories, err := s.categoryRepo.GetTrashedCategories(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	items := make([]response.TrashedCategoryItem, 0, len(categories))
+	for _, cat := range categories {
+		items = append(items, response.TrashedCategoryItem{
+			ID:          cat.ID,
+			Name:        cat.Name,
+			Description: cat.Description,
+			ParentID:    cat.ParentID,
+			DeletedAt:   cat.DeletedAt.Time,
+		})
+	}
+
+	return &response.GetCategoryTrashResponse{Categories: items}, nil
+}
+
+// RestoreCategory 将分类移出回收站，上级分类必须未被删除，且名称未被其他分类占用
+func (s *CategoryService) RestoreCategory(ctx context.Context, id uint) error {
+	category, err := s.categoryRepo.GetTrashedCategoryByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return common.ErrTrashedCategoryNotFound
+		}
+		return err
+	}
+
+	if category.ParentID != nil {
+		if _, err := s.categoryRepo.GetCategoryByID(ctx, *category.ParentID); err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return common.ErrParentCategoryDeleted
+			}
+			return err
+		}
+	}
+
+	if _, err := s.categoryRepo.GetCategoryByName(ctx, category.Name); err == nil {
+		return &common.BizError{
+			Code:       409,
+			Message:    "分类名称已存在",
+			HTTPStatus: 409,
+		}
+	} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return err
+	}
+
+	return s.categoryRepo.DB().Transaction(func(tx *gorm.DB) error {
+		if err := s.categoryRepo.RestoreCategory(ctx, tx, id); err != nil {
+			return err
+		}
+		return s.auditService.Record(ctx, tx, model.AuditActionRestore, model.AuditEntityCategory, uint64(id),
+			map[string]interface{}{"deleted_at": category.DeletedAt.Time}, map[string]interface{}{"deleted_at": nil})
+	})
+}
@@ -0,0 +1,142 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"library-system/dto/response"
+	"library-system/model"
+	"library-system/repository"
+	"library-system/utils"
+	"log"
+	"time"
+)
+
+// 事件流票据有效期：客户端取得票据后应立即建立连接
+const streamTicketTTL = 30 * time.Second
+
+// 实时事件类型
+const (
+	EventBookBorrowed         = "book_borrowed"
+	EventBookReturned         = "book_returned"
+	EventLoanRenewed          = "loan_renewed"
+	EventFineCharged          = "fine_charged"
+	EventReservationAvailable = "reservation_available"
+)
+
+// Event 推送给客户端的实时事件
+type Event struct {
+	Type    string                 `json:"type"`
+	UserID  uint64                 `json:"user_id"`
+	Data    map[string]interface{} `json:"data,omitempty"`
+	OccurAt time.Time              `json:"occur_at"`
+}
+
+// EventService 实时事件服务：发布到 Redis，由各实例的 SSE 连接订阅转发
+type EventService struct {
+	eventRdb    *repository.EventRdb
+	roleService *RoleService
+}
+
+func NewEventService(eventRdb *repository.EventRdb, roleService *RoleService) *EventService {
+	return &EventService{eventRdb: eventRdb, roleService: roleService}
+}
+
+// IssueStreamTicket 为当前 Access Token 签发建立事件流的一次性票据，避免 Token 出现在 URL 与访问日志中
+func (s *EventService) IssueStreamTicket(ctx context.Context, claims *utils.AccessTokenClaims) (*response.StreamTicketResponse, error) {
+	ticket, err := utils.GenerateOpaqueToken()
+	if err != nil {
+		return nil, err
+	}
+	payload, err := json.Marshal(claims)
+	if err != nil {
+		return nil, err
+	}
+	if err := repository.Rdb.StoreStreamTicket(ctx, utils.HashOpaqueToken(ticket), payload, streamTicketTTL); err != nil {
+		return nil, err
+	}
+
+	return &response.StreamTicketResponse{
+		Ticket:    ticket,
+		ExpiresIn: int(streamTicketTTL.Seconds()),
+	}, nil
+}
+
+// StillAuthorized 长连接定期复核：Token 未被吊销（登出、禁用、角色变更都会吊销会话），
+// 订阅全部流通事件的连接其角色仍须拥有流通查看权限
+func (s *EventService) StillAuthorized(ctx context.Context, claims *utils.AccessTokenClaims, watchAll bool) (bool, error) {
+	inBlacklist, err := repository.Rdb.IsInBlacklist(ctx, claims.TokenID)
+	if err != nil || inBlacklist {
+		return false, err
+	}
+	if !watchAll {
+		return true, nil
+	}
+
+	permissions, err := s.roleService.Permissions(ctx, claims.Role)
+	if err != nil {
+		return false, err
+	}
+	return permissions[model.PermCirculationRead], nil
+}
+
+// Publish 发布用户事件，同时进入管理员的流通事件频道。
+// 应在业务事务提交后调用；发布失败只记录日志，不影响业务结果
+func (s *EventService) Publish(ctx context.Context, eventType string, userID uint64, data map[string]interface{}) {
+	payload, err := json.Marshal(Event{
+		Type:    eventType,
+		UserID:  userID,
+		Data:    data,
+		OccurAt: time.Now(),
+	})
+	if err != nil {
+		log.Printf("序列化事件失败: %v", err)
+		return
+	}
+
+	for _, channel := range []string{repository.UserEventChannel(userID), repository.CirculationChannel} {
+		if err := s.eventRdb.Publish(ctx, channel, payload); err != nil {
+			log.Printf("发布事件 %s 到 %s 失败: %v", eventType, channel, err)
+		}
+	}
+}
+
+// Subscribe 普通用户订阅自己的事件，有流通查看权限的用户订阅全部流通事件（已包含其本人的事件）。
+// 返回的通道在 ctx 结束时关闭
+func (s *EventService) Subscribe(ctx context.Context, userID uint64, watchAll bool) (<-chan []byte, error) {
+	channels := []string{repository.UserEventChannel(userID)}
+	if watchAll {
+		channels = []string{repository.CirculationChannel}
+	}
+
+	pubsub := s.eventRdb.Subscribe(ctx, channels...)
+	// 等待订阅确认，确保连接建立后才返回
+	if _, err := pubsub.Receive(ctx); err != nil {
+		pubsub.Close()
+		return nil, err
+	}
+
+	out := make(chan []byte, 16)
+	go func() {
+		defer close(out)
+		defer pubsub.Close()
+
+		msgs := pubsub.Channel()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case msg, ok := <-msgs:
+				if !ok {
+					return
+				}
+				select {
+				case out <- []byte(msg.Payload):
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+	}()
+
+	return out, nil
+}
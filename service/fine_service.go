@@ -0,0 +1,249 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"library-system/common"
+	"library-system/dto/request"
+	"library-system/dto/response"
+	"library-system/model"
+	"library-system/repository"
+	"math"
+
+	"gorm.io/gorm"
+)
+
+type FineService struct {
+	fineRepo       *repository.FineRepository
+	userRepo       *repository.UserRepository
+	blockThreshold float64
+	auditService   *AuditService
+	eventService   *EventService
+}
+
+func NewFineService(fineRepo *repository.FineRepository, userRepo *repository.UserRepository, blockThreshold float64, auditService *AuditService, eventService *EventService) *FineService {
+	return &FineService{
+		fineRepo:       fineRepo,
+		userRepo:       userRepo,
+		blockThreshold: blockThreshold,
+		auditService:   auditService,
+		eventService:   eventService,
+	}
+}
+
+// GetBalance 查询用户当前欠款
+func (s *FineService) GetBalance(ctx context.Context, tx *gorm.DB, userID uint64) (float64, error) {
+	balance, err := s.fineRepo.GetBalance(ctx, tx, userID)
+	if err != nil {
+		return 0, err
+	}
+	return roundMoney(balance), nil
+}
+
+// CheckBorrowAllowed 欠款超过限额时禁止借书
+func (s *FineService) CheckBorrowAllowed(ctx context.Context, tx *gorm.DB, userID uint64) error {
+	balance, err := s.GetBalance(ctx, tx, userID)
+	if err != nil {
+		return err
+	}
+	if balance > s.blockThreshold {
+		return common.ErrFineBalanceExceeded
+	}
+	return nil
+}
+
+// PostCharge 归还结算时记入罚金
+func (s *FineService) PostCharge(ctx context.Context, tx *gorm.DB, userID, borrowID uint64, amount float64, reason string) error {
+	if amount <= 0 {
+		return nil
+	}
+	t := model.FineTransaction{
+		UserID:   userID,
+		BorrowID: &borrowID,
+		Type:     model.FineTypeCharge,
+		Amount:   roundMoney(amount),
+		Reason:   reason,
+	}
+	return s.fineRepo.CreateTransaction(ctx, tx, &t)
+}
+
+// GetFineAccount 获取用户的欠款和罚金流水
+func (s *FineService) GetFineAccount(ctx context.Context, userID uint64, req *request.GetFineTransactionsRequest) (*response.GetFineAccountResponse, error) {
+	if _, err := s.userRepo.GetUserByUserID(ctx, userID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, common.ErrNotFound
+		}
+		return nil, err
+	}
+
+	if req.Page == 0 {
+		req.Page = 1
+	}
+	if req.Limit == 0 {
+		req.Limit = 10
+	}
+
+	balance, err := s.GetBalance(ctx, s.fineRepo.DB(), userID)
+	if err != nil {
+		return nil, err
+	}
+
+	transactions, total, err := s.fineRepo.GetTransactionsByUserID(ctx, userID, req.Page, req.Limit)
+	if err != nil {
+		return nil, err
+	}
+
+	items := make([]response.FineTransactionItem, 0, len(transactions))
+	for _, t := range transactions {
+		items = append(items, toFineTransactionItem(t))
+	}
+
+	return &response.GetFineAccountResponse{
+		UserID:         userID,
+		Balance:        balance,
+		BlockThreshold: s.blockThreshold,
+		BorrowBlocked:  balance > s.blockThreshold,
+		Total:          total,
+		Page:           req.Page,
+		Limit:          req.Limit,
+		TotalPages:     int(math.Ceil(float64(total) / float64(req.Limit))),
+		Transactions:   items,
+	}, nil
+}
+
+// RecordPayment 登记缴纳，金额不能超过当前欠款
+func (s *FineService) RecordPayment(ctx context.Context, operatorID, userID uint64, req *request.RecordFinePaymentRequest) (*response.FineTransactionResponse, error) {
+	reason := "现金缴纳"
+	if req.Remark != nil {
+		reason = *req.Remark
+	}
+
+	return s.post(ctx, operatorID, userID, func(balance float64) (model.FineTransaction, error) {
+		// 不足一分的金额舍入后为 0，不生成空流水
+		amount := roundMoney(req.Amount)
+		if amount <= 0 {
+			return model.FineTransaction{}, common.ErrBadRequest
+		}
+		if balance <= 0 {
+			return model.FineTransaction{}, common.ErrNoOutstandingFine
+		}
+		if amount > balance {
+			return model.FineTransaction{}, common.ErrPaymentExceedsBalance
+		}
+		return model.FineTransaction{
+			Type:   model.FineTypePayment,
+			Amount: -amount,
+			Reason: reason,
+		}, nil
+	})
+}
+
+// WaiveFine 减免罚金，未指定金额时减免全部欠款
+func (s *FineService) WaiveFine(ctx context.Context, operatorID, userID uint64, req *request.WaiveFineRequest) (*response.FineTransactionResponse, error) {
+	return s.post(ctx, operatorID, userID, func(balance float64) (model.FineTransaction, error) {
+		if balance <= 0 {
+			return model.FineTransaction{}, common.ErrNoOutstandingFine
+		}
+		amount := balance
+		if req.Amount != nil {
+			amount = roundMoney(*req.Amount)
+			if amount <= 0 {
+				return model.FineTransaction{}, common.ErrBadRequest
+			}
+			if amount > balance {
+				return model.FineTransaction{}, common.ErrPaymentExceedsBalance
+			}
+		}
+		return model.FineTransaction{
+			Type:   model.FineTypeWaiver,
+			Amount: -amount,
+			Reason: req.Reason,
+		}, nil
+	})
+}
+
+// AdjustFine 人工调整欠款（如更正录入错误），调整后欠款不能为负
+func (s *FineService) AdjustFine(ctx context.Context, operatorID, userID uint64, req *request.AdjustFineRequest) (*response.FineTransactionResponse, error) {
+	return s.post(ctx, operatorID, userID, func(balance float64) (model.FineTransaction, error) {
+		amount := roundMoney(req.Amount)
+		if amount == 0 {
+			return model.FineTransaction{}, common.ErrBadRequest
+		}
+		if balance+amount < 0 {
+			return model.FineTransaction{}, common.ErrPaymentExceedsBalance
+		}
+		return model.FineTransaction{
+			Type:   model.FineTypeAdjustment,
+			Amount: amount,
+			Reason: req.Reason,
+		}, nil
+	})
+}
+
+// post 锁定用户后按当前欠款生成并写入一笔流水，提交后推送欠款变动的实时事件
+func (s *FineService) post(ctx context.Context, operatorID, userID uint64, build func(balance float64) (model.FineTransaction, error)) (*response.FineTransactionResponse, error) {
+	var resp *response.FineTransactionResponse
+
+	err := s.fineRepo.DB().Transaction(func(tx *gorm.DB) error {
+		// 锁定用户，避免并发登记时余额计算错误
+		if _, err := s.userRepo.GetUserByIDWithLock(ctx, tx, userID); err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return common.ErrNotFound
+			}
+			return err
+		}
+
+		balance, err := s.GetBalance(ctx, tx, userID)
+		if err != nil {
+			return err
+		}
+
+		t, err := build(balance)
+		if err != nil {
+			return err
+		}
+		t.UserID = userID
+		t.OperatorID = &operatorID
+
+		if err := s.fineRepo.CreateTransaction(ctx, tx, &t); err != nil {
+			return err
+		}
+		if err := s.auditService.Record(ctx, tx, model.AuditActionCreate, model.AuditEntityFineTransaction, t.ID, nil, t); err != nil {
+			return err
+		}
+
+		resp = &response.FineTransactionResponse{
+			Transaction: toFineTransactionItem(t),
+			Balance:     roundMoney(balance + t.Amount),
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.eventService.Publish(ctx, EventFineCharged, userID, map[string]interface{}{
+		"transaction_id": resp.Transaction.ID,
+		"type":           resp.Transaction.Type,
+		"amount":         resp.Transaction.Amount,
+		"reason":         resp.Transaction.Reason,
+		"balance":        resp.Balance,
+	})
+	return resp, nil
+}
+
+func roundMoney(v float64) float64 {
+	return math.Round(v*100) / 100
+}
+
+func toFineTransactionItem(t model.FineTransaction) response.FineTransactionItem {
+	return response.FineTransactionItem{
+		ID:         t.ID,
+		BorrowID:   t.BorrowID,
+		Type:       t.Type,
+		Amount:     t.Amount,
+		Reason:     t.Reason,
+		OperatorID: t.OperatorID,
+		CreatedAt:  t.CreatedAt,
+	}
+}
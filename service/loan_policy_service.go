@@ -0,0 +1,258 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"library-system/common"
+	"library-system/dto/request"
+	"library-system/dto/response"
+	"library-system/model"
+	"library-system/repository"
+	"math"
+
+	"gorm.io/gorm"
+)
+
+const (
+	// 未配置任何规则时的默认逾期罚金（元/天）
+	DefaultFineRate = 1.0
+)
+
+// DefaultLoanPolicy 未匹配到任何规则时使用的兜底规则
+var DefaultLoanPolicy = model.LoanPolicy{
+	LoanDays:      DefaultBorrowDays,
+	MaxRenewCount: MaxRenewCount,
+	RenewDays:     DefaultBorrowDays,
+	FineRate:      DefaultFineRate,
+}
+
+type LoanPolicyService struct {
+	policyRepo   *repository.LoanPolicyRepository
+	categoryRepo *repository.CategoryRepository
+	auditService *AuditService
+}
+
+func NewLoanPolicyService(policyRepo *repository.LoanPolicyRepository, categoryRepo *repository.CategoryRepository, auditService *AuditService) *LoanPolicyService {
+	return &LoanPolicyService{
+		policyRepo:   policyRepo,
+		categoryRepo: categoryRepo,
+		auditService: auditService,
+	}
+}
+
+// Resolve 查找对该角色和分类生效的规则
+func (s *LoanPolicyService) Resolve(ctx context.Context, role string, categoryID uint) (model.LoanPolicy, error) {
+	policies, err := s.policyRepo.GetCandidatePolicies(ctx, role, categoryID)
+	if err != nil {
+		return model.LoanPolicy{}, err
+	}
+	return matchLoanPolicy(policies, role, categoryID), nil
+}
+
+// LoadAll 一次性加载全部规则，供批量处理时配合 matchLoanPolicy 使用
+func (s *LoanPolicyService) LoadAll(ctx context.Context) ([]model.LoanPolicy, error) {
+	return s.policyRepo.GetPolicyList(ctx)
+}
+
+// matchLoanPolicy 选出最具体的规则：角色+分类 > 仅分类 > 仅角色 > 全局通配 > 默认
+func matchLoanPolicy(policies []model.LoanPolicy, role string, categoryID uint) model.LoanPolicy {
+	best := DefaultLoanPolicy
+	bestScore := -1
+	for _, p := range policies {
+		score := 0
+		if p.Role != nil {
+			if *p.Role != role {
+				continue
+			}
+			score += 1
+		}
+		if p.CategoryID != nil {
+			if *p.CategoryID != categoryID {
+				continue
+			}
+			score += 2
+		}
+		if score > bestScore {
+			best = p
+			bestScore = score
+		}
+	}
+	return best
+}
+
+// calcOverdueFine 按规则计算逾期罚金，超过上限时封顶
+func calcOverdueFine(policy model.LoanPolicy, overdueDays int) float64 {
+	fine := math.Round(float64(overdueDays)*policy.FineRate*100) / 100
+	if policy.FineCap > 0 && fine > policy.FineCap {
+		fine = policy.FineCap
+	}
+	return fine
+}
+
+func (s *LoanPolicyService) GetLoanPolicyList(ctx context.Context) (*response.GetLoanPolicyListResponse, error) {
+	policies, err := s.policyRepo.GetPolicyList(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	items := make([]response.LoanPolicyItem, len(policies))
+	for i, p := range policies {
+		items[i] = toLoanPolicyItem(p)
+	}
+
+	def := toLoanPolicyItem(DefaultLoanPolicy)
+	def.IsDefault = true
+
+	return &response.GetLoanPolicyListResponse{
+		Policies: items,
+		Default:  def,
+	}, nil
+}
+
+func (s *LoanPolicyService) ResolveLoanPolicy(ctx context.Context, req *request.ResolveLoanPolicyRequest) (*response.LoanPolicyItem, error) {
+	policy, err := s.Resolve(ctx, req.Role, req.CategoryID)
+	if err != nil {
+		return nil, err
+	}
+
+	item := toLoanPolicyItem(policy)
+	item.IsDefault = policy.ID == 0
+	return &item, nil
+}
+
+func (s *LoanPolicyService) CreateLoanPolicy(ctx context.Context, req *request.CreateLoanPolicyRequest) (*response.LoanPolicyItem, error) {
+	if req.CategoryID != nil {
+		if _, err := s.categoryRepo.GetCategoryByID(ctx, *req.CategoryID); err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return nil, common.ErrCategoryNotFound
+			}
+			return nil, err
+		}
+	}
+
+	if _, err := s.policyRepo.GetPolicyByKey(ctx, req.Role, req.CategoryID); err == nil {
+		return nil, common.ErrLoanPolicyExist
+	} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+
+	policy := model.LoanPolicy{
+		Role:          req.Role,
+		CategoryID:    req.CategoryID,
+		LoanDays:      req.LoanDays,
+		MaxRenewCount: *req.MaxRenewCount,
+		RenewDays:     req.RenewDays,
+		FineRate:      *req.FineRate,
+	}
+	if req.MaxLoans != nil {
+		policy.MaxLoans = *req.MaxLoans
+	}
+	if req.FineCap != nil {
+		policy.FineCap = *req.FineCap
+	}
+	if req.Description != nil {
+		policy.Description = *req.Description
+	}
+
+	err := s.policyRepo.DB().Transaction(func(tx *gorm.DB) error {
+		if err := s.policyRepo.CreatePolicy(ctx, tx, &policy); err != nil {
+			return err
+		}
+		return s.auditService.Record(ctx, tx, model.AuditActionCreate, model.AuditEntityLoanPolicy, policy.ID, nil, policy)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	item := toLoanPolicyItem(policy)
+	return &item, nil
+}
+
+func (s *LoanPolicyService) UpdateLoanPolicy(ctx context.Context, id uint64, req *request.UpdateLoanPolicyRequest) (*response.LoanPolicyItem, error) {
+	policy, err := s.policyRepo.GetPolicyByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, common.ErrLoanPolicyNotFound
+		}
+		return nil, err
+	}
+
+	updates := make(map[string]interface{})
+	if req.LoanDays != nil {
+		updates["loan_days"] = *req.LoanDays
+	}
+	if req.MaxRenewCount != nil {
+		updates["max_renew_count"] = *req.MaxRenewCount
+	}
+	if req.RenewDays != nil {
+		updates["renew_days"] = *req.RenewDays
+	}
+	if req.MaxLoans != nil {
+		updates["max_loans"] = *req.MaxLoans
+	}
+	if req.FineRate != nil {
+		updates["fine_rate"] = *req.FineRate
+	}
+	if req.FineCap != nil {
+		updates["fine_cap"] = *req.FineCap
+	}
+	if req.Description != nil {
+		updates["description"] = *req.Description
+	}
+
+	if len(updates) == 0 {
+		return nil, common.ErrBadRequest
+	}
+
+	err = s.policyRepo.DB().Transaction(func(tx *gorm.DB) error {
+		if err := s.policyRepo.UpdatePolicyFields(ctx, tx, id, updates); err != nil {
+			return err
+		}
+		return s.auditService.Record(ctx, tx, model.AuditActionUpdate, model.AuditEntityLoanPolicy, id, policy, updates)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	updated, err := s.policyRepo.GetPolicyByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	item := toLoanPolicyItem(updated)
+	return &item, nil
+}
+
+func (s *LoanPolicyService) DeleteLoanPolicy(ctx context.Context, id uint64) error {
+	policy, err := s.policyRepo.GetPolicyByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return common.ErrLoanPolicyNotFound
+		}
+		return err
+	}
+
+	return s.policyRepo.DB().Transaction(func(tx *gorm.DB) error {
+		if err := s.policyRepo.DeletePolicy(ctx, tx, id); err != nil {
+			return err
+		}
+		return s.auditService.Record(ctx, tx, model.AuditActionDelete, model.AuditEntityLoanPolicy, id, policy, nil)
+	})
+}
+
+func toLoanPolicyItem(p model.LoanPolicy) response.LoanPolicyItem {
+	return response.LoanPolicyItem{
+		ID:            p.ID,
+		Role:          p.Role,
+		CategoryID:    p.CategoryID,
+		LoanDays:      p.LoanDays,
+		MaxRenewCount: p.MaxRenewCount,
+		RenewDays:     p.RenewDays,
+		MaxLoans:      p.MaxLoans,
+		FineRate:      p.FineRate,
+		FineCap:       p.FineCap,
+		Description:   p.Description,
+		CreatedAt:     p.CreatedAt,
+		UpdatedAt:     p.UpdatedAt,
+	}
+}
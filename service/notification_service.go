@@ -0,0 +1,351 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"library-system/common"
+	"library-system/dto/request"
+	"library-system/dto/response"
+	"library-system/model"
+	"library-system/notifier"
+	"library-system/repository"
+	"log"
+	"math"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const (
+	// 每次调度最多发送的通知数
+	NotifyDispatchBatchSize = 100
+
+	// 发送中的通知占用时长，超时未更新结果视为发送中断，可被重新发送
+	notifySendLease = 5 * time.Minute
+
+	// 重试退避的上限
+	notifyMaxBackoff = time.Hour
+)
+
+// notificationOutbox 投递发件箱所需的存储操作，由 NotificationRepository 实现
+type notificationOutbox interface {
+	GetDueOutbox(ctx context.Context, now time.Time, limit int) ([]model.NotificationOutbox, error)
+	ClaimOutbox(ctx context.Context, id uint64, now, leaseUntil time.Time) (bool, error)
+	UpdateOutboxFields(ctx context.Context, id uint64, fields map[string]interface{}) error
+}
+
+// NotificationService 通知服务：业务事件写入站内消息和发件箱，发件箱由定时任务统一投递
+type NotificationService struct {
+	notificationRepo *repository.NotificationRepository
+	outbox           notificationOutbox
+	notifiers        map[string]notifier.Notifier
+	maxAttempts      int
+}
+
+func NewNotificationService(
+	notificationRepo *repository.NotificationRepository,
+	maxAttempts int,
+	notifiers ...notifier.Notifier,
+) *NotificationService {
+	registered := make(map[string]notifier.Notifier, len(notifiers))
+	for _, n := range notifiers {
+		registered[n.Channel()] = n
+	}
+	return &NotificationService{
+		notificationRepo: notificationRepo,
+		outbox:           notificationRepo,
+		notifiers:        registered,
+		maxAttempts:      maxAttempts,
+	}
+}
+
+// NotifyReservationReady 预约的图书已可借
+func (s *NotificationService) NotifyReservationReady(ctx context.Context, tx *gorm.DB, reservation *model.Reservation, expiresAt time.Time) error {
+	subject := fmt.Sprintf("您预约的《%s》已可借阅", reservation.Book.Title)
+	body := fmt.Sprintf("%s，您好：\n\n您预约的图书《%s》已到馆，请在 %s 前办理借阅，逾期预约将自动失效。",
+		reservation.User.Username, reservation.Book.Title, expiresAt.Format("2006-01-02 15:04"))
+
+	return s.enqueue(ctx, tx, model.NotifyEventReservationReady, reservation.User, subject, body, map[string]interface{}{
+		"reservation_id": reservation.ID,
+		"book_id":        reservation.BookID,
+		"book_title":     reservation.Book.Title,
+		"expires_at":     expiresAt,
+	})
+}
+
+// NotifyReservationExpired 预约超时未借，已失效
+func (s *NotificationService) NotifyReservationExpired(ctx context.Context, tx *gorm.DB, reservation *model.Reservation) error {
+	subject := fmt.Sprintf("您预约的《%s》已失效", reservation.Book.Title)
+	body := fmt.Sprintf("%s，您好：\n\n您预约的图书《%s》未在保留期限内借阅，预约已失效。如仍需要，请重新预约。",
+		reservation.User.Username, reservation.Book.Title)
+
+	return s.enqueue(ctx, tx, model.NotifyEventReservationExpired, reservation.User, subject, body, map[string]interface{}{
+		"reservation_id": reservation.ID,
+		"book_id":        reservation.BookID,
+		"book_title":     reservation.Book.Title,
+	})
+}
+
+// NotifyDueSoon 借阅即将到期（record 需预加载 Book 和 User）
+func (s *NotificationService) NotifyDueSoon(ctx context.Context, tx *gorm.DB, record *model.BorrowRecord, daysLeft int) error {
+	subject := fmt.Sprintf("《%s》将于 %d 天后到期", record.Book.Title, daysLeft)
+	body := fmt.Sprintf("%s，您好：\n\n您借阅的图书《%s》将于 %s 到期，请按时归还或办理续借。",
+		record.User.Username, record.Book.Title, record.DueDate.Format("2006-01-02"))
+
+	return s.enqueue(ctx, tx, model.NotifyEventDueSoon, record.User, subject, body, map[string]interface{}{
+		"borrow_id":  record.ID,
+		"book_id":    record.BookID,
+		"book_title": record.Book.Title,
+		"due_date":   record.DueDate,
+		"days_left":  daysLeft,
+	})
+}
+
+// NotifyOverdue 借阅已逾期（record 需预加载 Book 和 User）
+func (s *NotificationService) NotifyOverdue(ctx context.Context, tx *gorm.DB, record *model.BorrowRecord, overdueDays int, fine float64) error {
+	subject := fmt.Sprintf("《%s》已逾期", record.Book.Title)
+	body := fmt.Sprintf("%s，您好：\n\n您借阅的图书《%s》已于 %s 到期，目前逾期 %d 天，产生罚金 %.2f 元。请尽快归还，逾期期间无法借阅新书。",
+		record.User.Username, record.Book.Title, record.DueDate.Format("2006-01-02"), overdueDays, fine)
+
+	return s.enqueue(ctx, tx, model.NotifyEventOverdue, record.User, subject, body, map[string]interface{}{
+		"borrow_id":    record.ID,
+		"book_id":      record.BookID,
+		"book_title":   record.Book.Title,
+		"due_date":     record.DueDate,
+		"overdue_days": overdueDays,
+		"fine":         fine,
+	})
+}
+
+// NotifyFineCharged 归还结算产生罚金
+func (s *NotificationService) NotifyFineCharged(ctx context.Context, tx *gorm.DB, user model.User, borrowID uint64, bookTitle string, amount float64, reason string) error {
+	subject := fmt.Sprintf("《%s》产生罚金 %.2f 元", bookTitle, amount)
+	body := fmt.Sprintf("%s，您好：\n\n您归还的图书《%s》产生罚金 %.2f 元（%s），请及时到馆缴纳。",
+		user.Username, bookTitle, amount, reason)
+
+	return s.enqueue(ctx, tx, model.NotifyEventFineCharged, user, subject, body, map[string]interface{}{
+		"borrow_id":  borrowID,
+		"book_title": bookTitle,
+		"amount":     amount,
+		"reason":     reason,
+	})
+}
+
+// enqueue 写入站内消息，并为每个已启用的渠道写入一条发件箱记录，tx 为空时不参与业务事务
+func (s *NotificationService) enqueue(ctx context.Context, tx *gorm.DB, event string, user model.User, subject, body string, data map[string]interface{}) error {
+	if tx == nil {
+		tx = s.notificationRepo.DB()
+	}
+
+	payload, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+
+	inbox := model.Notification{
+		UserID:  user.ID,
+		Event:   event,
+		Title:   subject,
+		Content: body,
+		Payload: string(payload),
+	}
+	if err := s.notificationRepo.CreateNotification(ctx, tx, &inbox); err != nil {
+		return err
+	}
+
+	now := time.Now()
+	entries := make([]model.NotificationOutbox, 0, len(s.notifiers))
+	for channel, n := range s.notifiers {
+		recipient := ""
+		if channel == notifier.ChannelEmail {
+			recipient = user.Email
+		}
+		if n.RequiresRecipient() && recipient == "" {
+			continue
+		}
+		entries = append(entries, model.NotificationOutbox{
+			Event:         event,
+			Channel:       channel,
+			UserID:        user.ID,
+			Recipient:     recipient,
+			Subject:       subject,
+			Body:          body,
+			Payload:       string(payload),
+			Status:        model.OutboxStatusPending,
+			NextAttemptAt: now,
+		})
+	}
+
+	return s.notificationRepo.CreateOutbox(ctx, tx, entries)
+}
+
+// Dispatch 发送到期的通知，失败的按指数退避重试，超过次数后标记为失败
+func (s *NotificationService) Dispatch(ctx context.Context) (sent int, failed int, err error) {
+	now := time.Now()
+	entries, err := s.outbox.GetDueOutbox(ctx, now, NotifyDispatchBatchSize)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	for _, entry := range entries {
+		claimed, err := s.outbox.ClaimOutbox(ctx, entry.ID, now, now.Add(notifySendLease))
+		if err != nil {
+			return sent, failed, err
+		}
+		if !claimed {
+			continue
+		}
+
+		sendErr := s.send(ctx, entry)
+		attempts := entry.Attempts + 1
+		updates := map[string]interface{}{"attempts": attempts}
+
+		if sendErr == nil {
+			updates["status"] = model.OutboxStatusSent
+			updates["sent_at"] = time.Now()
+			updates["last_error"] = ""
+			sent++
+		} else {
+			updates["last_error"] = truncate(sendErr.Error(), 500)
+			if attempts >= s.maxAttempts {
+				updates["status"] = model.OutboxStatusFailed
+			} else {
+				updates["next_attempt_at"] = time.Now().Add(notifyBackoff(attempts))
+			}
+			failed++
+			log.Printf("通知 %d（%s/%s）第 %d 次发送失败: %v", entry.ID, entry.Event, entry.Channel, attempts, sendErr)
+		}
+
+		if err := s.outbox.UpdateOutboxFields(ctx, entry.ID, updates); err != nil {
+			return sent, failed, err
+		}
+	}
+
+	return sent, failed, nil
+}
+
+func (s *NotificationService) send(ctx context.Context, entry model.NotificationOutbox) error {
+	n, ok := s.notifiers[entry.Channel]
+	if !ok {
+		return fmt.Errorf("通知渠道 %s 未启用", entry.Channel)
+	}
+
+	var data map[string]interface{}
+	if entry.Payload != "" {
+		if err := json.Unmarshal([]byte(entry.Payload), &data); err != nil {
+			return err
+		}
+	}
+
+	return n.Send(ctx, notifier.Message{
+		Event:   entry.Event,
+		UserID:  entry.UserID,
+		To:      entry.Recipient,
+		Subject: entry.Subject,
+		Body:    entry.Body,
+		Data:    data,
+	})
+}
+
+// notifyBackoff 第 n 次失败后的等待时间：1、2、4、8... 分钟，最长 1 小时
+func notifyBackoff(attempts int) time.Duration {
+	d := time.Minute << (attempts - 1)
+	if d <= 0 || d > notifyMaxBackoff {
+		return notifyMaxBackoff
+	}
+	return d
+}
+
+func truncate(s string, n int) string {
+	r := []rune(s)
+	if len(r) <= n {
+		return s
+	}
+	return string(r[:n])
+}
+
+// GetNotificationList 获取用户的站内消息
+func (s *NotificationService) GetNotificationList(ctx context.Context, userID uint64, req *request.GetNotificationListRequest) (*response.GetNotificationListResponse, error) {
+	if req.Page == 0 {
+		req.Page = 1
+	}
+	if req.Limit == 0 {
+		req.Limit = 20
+	}
+
+	notifications, total, err := s.notificationRepo.GetNotificationList(ctx, userID, req.UnreadOnly, req.Page, req.Limit)
+	if err != nil {
+		return nil, err
+	}
+
+	unread, err := s.notificationRepo.CountUnread(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	items := make([]response.NotificationItem, 0, len(notifications))
+	for _, n := range notifications {
+		items = append(items, toNotificationItem(n))
+	}
+
+	return &response.GetNotificationListResponse{
+		Total:         total,
+		UnreadCount:   unread,
+		Page:          req.Page,
+		Limit:         req.Limit,
+		TotalPages:    int(math.Ceil(float64(total) / float64(req.Limit))),
+		Notifications: items,
+	}, nil
+}
+
+func (s *NotificationService) GetUnreadCount(ctx context.Context, userID uint64) (*response.UnreadCountResponse, error) {
+	unread, err := s.notificationRepo.CountUnread(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	return &response.UnreadCountResponse{UnreadCount: unread}, nil
+}
+
+// MarkRead 标记单条消息已读，只能操作自己的消息
+func (s *NotificationService) MarkRead(ctx context.Context, userID, id uint64) error {
+	n, err := s.notificationRepo.GetNotificationByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return common.ErrNotificationNotFound
+		}
+		return err
+	}
+	if n.UserID != userID {
+		return common.ErrNotificationNotFound
+	}
+
+	return s.notificationRepo.MarkRead(ctx, id, time.Now())
+}
+
+func (s *NotificationService) MarkAllRead(ctx context.Context, userID uint64) (*response.MarkAllReadResponse, error) {
+	updated, err := s.notificationRepo.MarkAllRead(ctx, userID, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	return &response.MarkAllReadResponse{Updated: updated}, nil
+}
+
+func toNotificationItem(n model.Notification) response.NotificationItem {
+	item := response.NotificationItem{
+		ID:        n.ID,
+		Event:     n.Event,
+		Title:     n.Title,
+		Content:   n.Content,
+		IsRead:    n.ReadAt != nil,
+		ReadAt:    n.ReadAt,
+		CreatedAt: n.CreatedAt,
+	}
+	if n.Payload != "" {
+		if err := json.Unmarshal([]byte(n.Payload), &item.Data); err != nil {
+			log.Printf("解析消息%d数据失败: %v", n.ID, err)
+		}
+	}
+	return item
+}
@@ -0,0 +1,280 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"library-system/model"
+	"library-system/notifier"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeOutbox 内存中的发件箱，语义与 NotificationRepository 一致：占用只对到期的待发送记录生效
+type fakeOutbox struct {
+	mu   sync.Mutex
+	rows map[uint64]*model.NotificationOutbox
+
+	// beforeClaim 在占用前调用，用于模拟其他实例抢先占用
+	beforeClaim func(id uint64)
+}
+
+func newFakeOutbox(rows ...model.NotificationOutbox) *fakeOutbox {
+	f := &fakeOutbox{rows: make(map[uint64]*model.NotificationOutbox)}
+	for i := range rows {
+		row := rows[i]
+		f.rows[row.ID] = &row
+	}
+	return f
+}
+
+func newTestNotificationService(outbox *fakeOutbox, maxAttempts int, notifiers ...notifier.Notifier) *NotificationService {
+	s := NewNotificationService(nil, maxAttempts, notifiers...)
+	s.outbox = outbox
+	return s
+}
+
+func (f *fakeOutbox) get(id uint64) model.NotificationOutbox {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return *f.rows[id]
+}
+
+func (f *fakeOutbox) GetDueOutbox(ctx context.Context, now time.Time, limit int) ([]model.NotificationOutbox, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var due []model.NotificationOutbox
+	for _, row := range f.rows {
+		if row.Status == model.OutboxStatusPending && !row.NextAttemptAt.After(now) {
+			due = append(due, *row)
+		}
+	}
+	sort.Slice(due, func(i, j int) bool {
+		if !due[i].NextAttemptAt.Equal(due[j].NextAttemptAt) {
+			return due[i].NextAttemptAt.Before(due[j].NextAttemptAt)
+		}
+		return due[i].ID < due[j].ID
+	})
+	if len(due) > limit {
+		due = due[:limit]
+	}
+	return due, nil
+}
+
+func (f *fakeOutbox) ClaimOutbox(ctx context.Context, id uint64, now, leaseUntil time.Time) (bool, error) {
+	if f.beforeClaim != nil {
+		f.beforeClaim(id)
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	row, ok := f.rows[id]
+	if !ok || row.Status != model.OutboxStatusPending || row.NextAttemptAt.After(now) {
+		return false, nil
+	}
+	row.NextAttemptAt = leaseUntil
+	return true, nil
+}
+
+func (f *fakeOutbox) UpdateOutboxFields(ctx context.Context, id uint64, fields map[string]interface{}) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	row, ok := f.rows[id]
+	if !ok {
+		return fmt.Errorf("outbox %d not found", id)
+	}
+	for column, v := range fields {
+		switch column {
+		case "status":
+			row.Status = v.(string)
+		case "attempts":
+			row.Attempts = v.(int)
+		case "next_attempt_at":
+			row.NextAttemptAt = v.(time.Time)
+		case "last_error":
+			row.LastError = v.(string)
+		case "sent_at":
+			sentAt := v.(time.Time)
+			row.SentAt = &sentAt
+		default:
+			return fmt.Errorf("unexpected column %s", column)
+		}
+	}
+	return nil
+}
+
+// stubNotifier 按预设结果发送，并记录收到的消息
+type stubNotifier struct {
+	mu   sync.Mutex
+	err  error
+	sent []notifier.Message
+}
+
+func (n *stubNotifier) Channel() string { return notifier.ChannelWebhook }
+
+func (n *stubNotifier) RequiresRecipient() bool { return false }
+
+func (n *stubNotifier) Send(ctx context.Context, msg notifier.Message) error {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.sent = append(n.sent, msg)
+	return n.err
+}
+
+func pendingOutbox(id uint64, attempts int, nextAttemptAt time.Time) model.NotificationOutbox {
+	return model.NotificationOutbox{
+		ID:            id,
+		Event:         model.NotifyEventDueSoon,
+		Channel:       notifier.ChannelWebhook,
+		UserID:        7,
+		Subject:       "到期提醒",
+		Body:          "请按时归还",
+		Payload:       `{"borrow_id":3}`,
+		Status:        model.OutboxStatusPending,
+		Attempts:      attempts,
+		NextAttemptAt: nextAttemptAt,
+	}
+}
+
+func TestDispatchSendsDueOutbox(t *testing.T) {
+	past := time.Now().Add(-time.Minute)
+	table := newFakeOutbox(
+		pendingOutbox(1, 0, past),
+		pendingOutbox(2, 0, time.Now().Add(time.Hour)), // 尚未到期
+	)
+	stub := &stubNotifier{}
+	s := newTestNotificationService(table, 5, stub)
+
+	sent, failed, err := s.Dispatch(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sent != 1 || failed != 0 {
+		t.Fatalf("sent=%d failed=%d, want 1, 0", sent, failed)
+	}
+	if len(stub.sent) != 1 || stub.sent[0].Data["borrow_id"] != float64(3) {
+		t.Fatalf("unexpected messages: %+v", stub.sent)
+	}
+
+	row := table.get(1)
+	if row.Status != model.OutboxStatusSent || row.Attempts != 1 || row.SentAt == nil {
+		t.Fatalf("row 1 = %+v, want sent after 1 attempt", row)
+	}
+	if table.get(2).Status != model.OutboxStatusPending {
+		t.Fatal("row 2 should stay pending")
+	}
+}
+
+func TestDispatchSkipsOutboxClaimedElsewhere(t *testing.T) {
+	table := newFakeOutbox(pendingOutbox(1, 0, time.Now().Add(-time.Minute)))
+	// 查询到记录之后、占用之前，另一个实例已占用该记录
+	table.beforeClaim = func(id uint64) {
+		table.mu.Lock()
+		table.rows[id].NextAttemptAt = time.Now().Add(notifySendLease)
+		table.mu.Unlock()
+	}
+	stub := &stubNotifier{}
+	s := newTestNotificationService(table, 5, stub)
+
+	sent, failed, err := s.Dispatch(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sent != 0 || failed != 0 || len(stub.sent) != 0 {
+		t.Fatalf("sent=%d failed=%d messages=%d, want nothing sent", sent, failed, len(stub.sent))
+	}
+	if row := table.get(1); row.Attempts != 0 {
+		t.Fatalf("attempts = %d, want 0", row.Attempts)
+	}
+}
+
+func TestDispatchRetriesWithBackoff(t *testing.T) {
+	table := newFakeOutbox(pendingOutbox(1, 2, time.Now().Add(-time.Minute)))
+	stub := &stubNotifier{err: errors.New("connection refused")}
+	s := newTestNotificationService(table, 5, stub)
+
+	before := time.Now()
+	sent, failed, err := s.Dispatch(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sent != 0 || failed != 1 {
+		t.Fatalf("sent=%d failed=%d, want 0, 1", sent, failed)
+	}
+
+	row := table.get(1)
+	if row.Status != model.OutboxStatusPending || row.Attempts != 3 {
+		t.Fatalf("row = %+v, want pending after 3 attempts", row)
+	}
+	if row.LastError != "connection refused" {
+		t.Fatalf("last_error = %q", row.LastError)
+	}
+	// 第 3 次失败后等待 4 分钟
+	wait := row.NextAttemptAt.Sub(before)
+	if wait < 4*time.Minute || wait > 4*time.Minute+time.Second {
+		t.Fatalf("next attempt in %v, want 4m", wait)
+	}
+
+	// 退避期内不会再次发送
+	if sent, failed, err := s.Dispatch(context.Background()); err != nil || sent+failed != 0 {
+		t.Fatalf("second dispatch sent=%d failed=%d err=%v, want nothing", sent, failed, err)
+	}
+}
+
+func TestDispatchGivesUpAfterMaxAttempts(t *testing.T) {
+	table := newFakeOutbox(pendingOutbox(1, 4, time.Now().Add(-time.Minute)))
+	stub := &stubNotifier{err: errors.New("webhook 返回状态码 500")}
+	s := newTestNotificationService(table, 5, stub)
+
+	if _, failed, err := s.Dispatch(context.Background()); err != nil || failed != 1 {
+		t.Fatalf("failed=%d err=%v, want 1 failure", failed, err)
+	}
+	row := table.get(1)
+	if row.Status != model.OutboxStatusFailed || row.Attempts != 5 {
+		t.Fatalf("row = %+v, want failed after 5 attempts", row)
+	}
+}
+
+func TestDispatchFailsUnknownChannel(t *testing.T) {
+	entry := pendingOutbox(1, 0, time.Now().Add(-time.Minute))
+	entry.Channel = "sms"
+	table := newFakeOutbox(entry)
+	s := newTestNotificationService(table, 1, &stubNotifier{})
+
+	if _, failed, err := s.Dispatch(context.Background()); err != nil || failed != 1 {
+		t.Fatalf("failed=%d err=%v, want 1 failure", failed, err)
+	}
+	if row := table.get(1); row.Status != model.OutboxStatusFailed {
+		t.Fatalf("status = %s, want failed", row.Status)
+	}
+}
+
+func TestNotifyBackoff(t *testing.T) {
+	tests := []struct {
+		attempts int
+		want     time.Duration
+	}{
+		{1, time.Minute},
+		{2, 2 * time.Minute},
+		{3, 4 * time.Minute},
+		{6, 32 * time.Minute},
+		{7, time.Hour}, // 64 分钟超过上限
+		{30, time.Hour},
+		{100, time.Hour}, // 移位溢出
+	}
+	for _, tt := range tests {
+		if got := notifyBackoff(tt.attempts); got != tt.want {
+			t.Errorf("notifyBackoff(%d) = %v, want %v", tt.attempts, got, tt.want)
+		}
+	}
+}
+
+func TestTruncate(t *testing.T) {
+	if got := truncate("发送失败", 2); got != "发送" {
+		t.Errorf("truncate = %q, want rune-safe prefix", got)
+	}
+	if got := truncate("ok", 10); got != "ok" {
+		t.Errorf("truncate = %q, want unchanged", got)
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"library-system/model"
 	"library-system/repository"
+	"log"
 	"time"
 
 	"gorm.io/gorm"
@@ -11,26 +12,48 @@ import (
 
 // OverdueService 逾期检查服务
 type OverdueService struct {
-	borrowRepo *repository.BorrowRepository
-	userRepo   *repository.UserRepository
+	borrowRepo          *repository.BorrowRepository
+	userRepo            *repository.UserRepository
+	policyService       *LoanPolicyService
+	notificationService *NotificationService
+	eventService        *EventService
+}
+
+// overdueCharge 转为逾期时产生的罚金，事务提交后推送实时事件
+type overdueCharge struct {
+	record      model.BorrowRecord
+	overdueDays int
+	fine        float64
 }
 
 // NewOverdueService 创建逾期服务实例
 func NewOverdueService(
 	borrowRepo *repository.BorrowRepository,
 	userRepo *repository.UserRepository,
+	policyService *LoanPolicyService,
+	notificationService *NotificationService,
+	eventService *EventService,
 ) *OverdueService {
 	return &OverdueService{
-		borrowRepo: borrowRepo,
-		userRepo:   userRepo,
+		borrowRepo:          borrowRepo,
+		userRepo:            userRepo,
+		policyService:       policyService,
+		notificationService: notificationService,
+		eventService:        eventService,
 	}
 }
 
 func (s *OverdueService) RefreshAllUsersOverdue(ctx context.Context) (int, error) {
 	now := time.Now()
 	updatedCount := 0
+	var charges []overdueCharge
 
-	err := s.borrowRepo.DB().Transaction(func(tx *gorm.DB) error {
+	policies, err := s.policyService.LoadAll(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	err = s.borrowRepo.DB().Transaction(func(tx *gorm.DB) error {
 		dueRecords, err := s.borrowRepo.GetAllDueRecord(ctx, tx, now)
 		if err != nil {
 			return err
@@ -45,8 +68,9 @@ func (s *OverdueService) RefreshAllUsersOverdue(ctx context.Context) (int, error
 		for _, record := range dueRecords {
 			// 计算逾期天数
 			overdueDays := int(now.Sub(record.DueDate).Hours() / 24)
-			// 计算罚金（1元/天）
-			fine := float64(overdueDays) * 1.0
+			// 按借阅规则计算罚金
+			policy := matchLoanPolicy(policies, record.User.Role, record.Book.CategoryID)
+			fine := calcOverdueFine(policy, overdueDays)
 
 			updates := map[string]interface{}{
 				"status": "overdue",
@@ -55,6 +79,12 @@ func (s *OverdueService) RefreshAllUsersOverdue(ctx context.Context) (int, error
 			if err := s.borrowRepo.UpdateFields(ctx, tx, record.ID, updates); err != nil {
 				return err
 			}
+			if err := s.notificationService.NotifyOverdue(ctx, tx, &record, overdueDays, fine); err != nil {
+				return err
+			}
+			if fine > 0 {
+				charges = append(charges, overdueCharge{record: record, overdueDays: overdueDays, fine: fine})
+			}
 			userOverdueMap[record.UserID]++
 			updatedCount++
 		}
@@ -67,15 +97,25 @@ func (s *OverdueService) RefreshAllUsersOverdue(ctx context.Context) (int, error
 
 		return nil
 	})
+	if err != nil {
+		return updatedCount, err
+	}
 
-	return updatedCount, err
+	s.publishFineCharged(ctx, charges)
+	return updatedCount, nil
 }
 
 func (s *OverdueService) RefreshSingleUserOverdue(ctx context.Context, userID uint64) error {
 	now := time.Now()
+	var charges []overdueCharge
+
+	policies, err := s.policyService.LoadAll(ctx)
+	if err != nil {
+		return err
+	}
 
 	// 使用事务
-	err := s.borrowRepo.DB().Transaction(func(tx *gorm.DB) error {
+	err = s.borrowRepo.DB().Transaction(func(tx *gorm.DB) error {
 		// 1. 查找该用户所有逾期但状态未更新的记录
 		var overdueRecords []model.BorrowRecord
 		overdueRecords, err := s.borrowRepo.GetDueRecordByUserID(ctx, tx, userID, now)
@@ -94,8 +134,9 @@ func (s *OverdueService) RefreshSingleUserOverdue(ctx context.Context, userID ui
 		for _, record := range overdueRecords {
 			// 计算逾期天数
 			overdueDays := int(now.Sub(record.DueDate).Hours() / 24)
-			// 计算罚金（1元/天）
-			fine := float64(overdueDays) * 1.0
+			// 按借阅规则计算罚金
+			policy := matchLoanPolicy(policies, record.User.Role, record.Book.CategoryID)
+			fine := calcOverdueFine(policy, overdueDays)
 
 			updates := map[string]interface{}{
 				"status": "overdue",
@@ -104,6 +145,12 @@ func (s *OverdueService) RefreshSingleUserOverdue(ctx context.Context, userID ui
 			if err := s.borrowRepo.UpdateFields(ctx, tx, record.ID, updates); err != nil {
 				return err
 			}
+			if err := s.notificationService.NotifyOverdue(ctx, tx, &record, overdueDays, fine); err != nil {
+				return err
+			}
+			if fine > 0 {
+				charges = append(charges, overdueCharge{record: record, overdueDays: overdueDays, fine: fine})
+			}
 		}
 
 		// 3. 更新用户逾期计数
@@ -113,6 +160,63 @@ func (s *OverdueService) RefreshSingleUserOverdue(ctx context.Context, userID ui
 
 		return nil
 	})
+	if err != nil {
+		return err
+	}
+
+	s.publishFineCharged(ctx, charges)
+	return nil
+}
+
+// publishFineCharged 推送逾期罚金的实时事件（在逾期状态提交后调用）
+func (s *OverdueService) publishFineCharged(ctx context.Context, charges []overdueCharge) {
+	for _, c := range charges {
+		s.eventService.Publish(ctx, EventFineCharged, c.record.UserID, map[string]interface{}{
+			"borrow_id":    c.record.ID,
+			"book_title":   c.record.Book.Title,
+			"overdue_days": c.overdueDays,
+			"overdue_fine": c.fine,
+			"amount":       c.fine,
+		})
+	}
+}
+
+// SendDueReminders 为 days 天内到期且未提醒过的借阅发送提醒，返回提醒条数
+func (s *OverdueService) SendDueReminders(ctx context.Context, days int) (int, error) {
+	now := time.Now()
+	records, err := s.borrowRepo.GetDueSoonUnremindedRecords(ctx, now, now.AddDate(0, 0, days))
+	if err != nil {
+		return 0, err
+	}
+
+	count := 0
+	for _, record := range records {
+		daysLeft := DaysFromToday(record.DueDate)
+		reminded := false
+		// 先按条件标记再写通知，同一事务提交：其他实例已标记或期间已归还时不再提醒
+		err := s.borrowRepo.DB().Transaction(func(tx *gorm.DB) error {
+			n, err := s.borrowRepo.MarkReminded(ctx, tx, record.ID, now)
+			if err != nil {
+				return err
+			}
+			if n != 1 {
+				return nil
+			}
+			if err := s.notificationService.NotifyDueSoon(ctx, tx, &record, daysLeft); err != nil {
+				return err
+			}
+			reminded = true
+			return nil
+		})
+		// 单条失败不影响其他记录，未标记的记录下次执行时重试
+		if err != nil {
+			log.Printf("借阅记录 %d 到期提醒失败: %v", record.ID, err)
+			continue
+		}
+		if reminded {
+			count++
+		}
+	}
 
-	return err
+	return count, nil
 }
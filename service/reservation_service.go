@@ -15,20 +15,26 @@ import (
 )
 
 type ReservationService struct {
-	reservationRepo *repository.ReservationRepository
-	bookRepo        *repository.BookRepository
-	userRepo        *repository.UserRepository
+	reservationRepo     *repository.ReservationRepository
+	bookRepo            *repository.BookRepository
+	userRepo            *repository.UserRepository
+	notificationService *NotificationService
+	eventService        *EventService
 }
 
 func NewReservationService(
 	reservationRepo *repository.ReservationRepository,
 	bookRepo *repository.BookRepository,
 	userRepo *repository.UserRepository,
+	notificationService *NotificationService,
+	eventService *EventService,
 ) *ReservationService {
 	return &ReservationService{
-		reservationRepo: reservationRepo,
-		bookRepo:        bookRepo,
-		userRepo:        userRepo,
+		reservationRepo:     reservationRepo,
+		bookRepo:            bookRepo,
+		userRepo:            userRepo,
+		notificationService: notificationService,
+		eventService:        eventService,
 	}
 }
 
@@ -163,16 +169,16 @@ func (s *ReservationService) GetMyReservations(ctx context.Context, userID uint6
 	}, nil
 }
 
-// NotifyNextReservation 通知下一个预约者（图书归还时调用）
-func (s *ReservationService) NotifyNextReservation(ctx context.Context, tx *gorm.DB, bookID uint64) error {
+// NotifyNextReservation 通知下一个预约者（图书归还时调用），返回被通知的预约，没有预约时返回 nil
+func (s *ReservationService) NotifyNextReservation(ctx context.Context, tx *gorm.DB, bookID uint64) (*model.Reservation, error) {
 	// 1. 获取下一个等待的预约
 	reservation, err := s.reservationRepo.GetNextWaitingReservation(ctx, bookID)
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			// 没有预约，正常情况
-			return nil
+			return nil, nil
 		}
-		return err
+		return nil, err
 	}
 
 	// 2. 更新预约状态为可借阅
@@ -186,17 +192,25 @@ func (s *ReservationService) NotifyNextReservation(ctx context.Context, tx *gorm
 	}
 
 	if err := s.reservationRepo.UpdateReservationStatus(ctx, tx, reservation.ID, updates); err != nil {
-		return err
+		return nil, err
 	}
+	reservation.ExpiresAt = &expiresAt
 
-	// 3. 发送通知（这里简化处理，实际项目应该调用通知服务）
-	log.Printf("📧 通知用户 %d:  您预约的图书《%s》已可借阅，请在 %s 前借阅",
-		reservation.UserID, reservation.Book.Title, expiresAt.Format("2006-01-02 15:04"))
-
-	// TODO: 集成邮件/短信通知服务
-	// s.notificationService.SendReservationNotification(reservation)
+	// 3. 写入通知发件箱，随预约状态一起提交
+	if err := s.notificationService.NotifyReservationReady(ctx, tx, reservation, expiresAt); err != nil {
+		return nil, err
+	}
+	return reservation, nil
+}
 
-	return nil
+// PublishReservationAvailable 推送预约可借的实时事件（在预约状态提交后调用）
+func (s *ReservationService) PublishReservationAvailable(ctx context.Context, reservation *model.Reservation) {
+	s.eventService.Publish(ctx, EventReservationAvailable, reservation.UserID, map[string]interface{}{
+		"reservation_id": reservation.ID,
+		"book_id":        reservation.BookID,
+		"book_title":     reservation.Book.Title,
+		"expires_at":     reservation.ExpiresAt,
+	})
 }
 
 // ProcessExpiredReservations 处理过期预约（定时任务）
@@ -220,9 +234,16 @@ func (s *ReservationService) ProcessExpiredReservations(ctx context.Context) (in
 
 		count++
 
+		if err := s.notificationService.NotifyReservationExpired(ctx, nil, &reservation); err != nil {
+			log.Printf("写入预约%d失效通知失败: %v", reservation.ID, err)
+		}
+
 		// 通知下一个预约者
-		if err := s.NotifyNextReservation(ctx, nil, reservation.BookID); err != nil {
+		next, err := s.NotifyNextReservation(ctx, nil, reservation.BookID)
+		if err != nil {
 			log.Printf("通知下一个预约者失败: %v", err)
+		} else if next != nil {
+			s.PublishReservationAvailable(ctx, next)
 		}
 	}
 
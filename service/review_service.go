@@ -0,0 +1,288 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"library-system/common"
+	"library-system/dto/request"
+	"library-system/dto/response"
+	"library-system/model"
+	"library-system/repository"
+	"math"
+
+	"gorm.io/gorm"
+)
+
+type ReviewService struct {
+	reviewRepo *repository.ReviewRepository
+	bookRepo     *repository.BookRepository
+	auditService *AuditService
+}
+
+func NewReviewService(reviewRepo *repository.ReviewRepository, bookRepo *repository.BookRepository, auditService *AuditService) *ReviewService {
+	return &ReviewService{
+		reviewRepo:   reviewRepo,
+		bookRepo:     bookRepo,
+		auditService: auditService,
+	}
+}
+
+// GetReviewList 获取图书的可见评价
+func (s *ReviewService) GetReviewList(ctx context.Context, bookID uint64, req *request.GetReviewListRequest) (*response.GetReviewListResponse, error) {
+	book, err := s.getBook(ctx, bookID)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Page == 0 {
+		req.Page = 1
+	}
+	if req.Limit == 0 {
+		req.Limit = 10
+	}
+
+	status := model.ReviewStatusVisible
+	reviews, total, err := s.reviewRepo.GetReviewList(ctx, &bookID, &status, req.Page, req.Limit)
+	if err != nil {
+		return nil, err
+	}
+
+	items := make([]response.ReviewItem, 0, len(reviews))
+	for _, r := range reviews {
+		items = append(items, toReviewItem(r))
+	}
+
+	return &response.GetReviewListResponse{
+		BookID:      bookID,
+		Rating:      book.Rating,
+		ReviewCount: book.ReviewCount,
+		Total:       total,
+		Page:        req.Page,
+		Limit:       req.Limit,
+		TotalPages:  int(math.Ceil(float64(total) / float64(req.Limit))),
+		Reviews:     items,
+	}, nil
+}
+
+// GetModerationList 管理员查看全部评价（含已隐藏）
+func (s *ReviewService) GetModerationList(ctx context.Context, req *request.GetReviewModerationListRequest) (*response.GetReviewListResponse, error) {
+	if req.Page == 0 {
+		req.Page = 1
+	}
+	if req.Limit == 0 {
+		req.Limit = 20
+	}
+
+	reviews, total, err := s.reviewRepo.GetReviewList(ctx, req.BookID, req.Status, req.Page, req.Limit)
+	if err != nil {
+		return nil, err
+	}
+
+	items := make([]response.ReviewItem, 0, len(reviews))
+	for _, r := range reviews {
+		item := toReviewItem(r)
+		item.BookTitle = r.Book.Title
+		items = append(items, item)
+	}
+
+	return &response.GetReviewListResponse{
+		Total:      total,
+		Page:       req.Page,
+		Limit:      req.Limit,
+		TotalPages: int(math.Ceil(float64(total) / float64(req.Limit))),
+		Reviews:    items,
+	}, nil
+}
+
+// CreateReview 发表评价，只有归还过该书的读者可以评价，每人每本书限一条
+func (s *ReviewService) CreateReview(ctx context.Context, userID, bookID uint64, req *request.CreateReviewRequest) (*response.ReviewItem, error) {
+	if _, err := s.getBook(ctx, bookID); err != nil {
+		return nil, err
+	}
+
+	returned, err := s.reviewRepo.HasReturnedBook(ctx, userID, bookID)
+	if err != nil {
+		return nil, err
+	}
+	if !returned {
+		return nil, common.ErrReviewNotAllowed
+	}
+
+	if _, err := s.reviewRepo.GetUserReviewForBook(ctx, userID, bookID); err == nil {
+		return nil, common.ErrReviewExist
+	} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+
+	review := model.Review{
+		BookID: bookID,
+		UserID: userID,
+		Rating: req.Rating,
+		Status: model.ReviewStatusVisible,
+	}
+	if req.Content != nil {
+		review.Content = *req.Content
+	}
+
+	err = s.reviewRepo.DB().Transaction(func(tx *gorm.DB) error {
+		if err := s.reviewRepo.CreateReview(ctx, tx, &review); err != nil {
+			return err
+		}
+		return s.reviewRepo.RecalcBookRating(ctx, tx, bookID)
+	})
+	// 并发提交时可能都通过了上面的检查，由唯一索引兜底
+	if errors.Is(err, gorm.ErrDuplicatedKey) {
+		return nil, common.ErrReviewExist
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return s.getReviewItem(ctx, review.ID)
+}
+
+// UpdateReview 修改自己的评价
+func (s *ReviewService) UpdateReview(ctx context.Context, userID, bookID, reviewID uint64, req *request.UpdateReviewRequest) (*response.ReviewItem, error) {
+	if req.Rating == nil && req.Content == nil {
+		return nil, common.ErrBadRequest
+	}
+
+	review, err := s.getBookReview(ctx, bookID, reviewID)
+	if err != nil {
+		return nil, err
+	}
+	if review.UserID != userID {
+		return nil, common.ErrPermissionDenied
+	}
+
+	updates := make(map[string]interface{})
+	if req.Rating != nil {
+		updates["rating"] = *req.Rating
+	}
+	if req.Content != nil {
+		updates["content"] = *req.Content
+	}
+
+	err = s.reviewRepo.DB().Transaction(func(tx *gorm.DB) error {
+		if err := s.reviewRepo.UpdateReviewFields(ctx, tx, reviewID, updates); err != nil {
+			return err
+		}
+		return s.reviewRepo.RecalcBookRating(ctx, tx, bookID)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return s.getReviewItem(ctx, reviewID)
+}
+
+// DeleteReview 删除评价，读者只能删除自己的，有审核权限的用户可删除任意评价
+func (s *ReviewService) DeleteReview(ctx context.Context, userID uint64, canModerate bool, bookID, reviewID uint64) error {
+	review, err := s.getBookReview(ctx, bookID, reviewID)
+	if err != nil {
+		return err
+	}
+	if !canModerate && review.UserID != userID {
+		return common.ErrPermissionDenied
+	}
+
+	return s.reviewRepo.DB().Transaction(func(tx *gorm.DB) error {
+		if err := s.reviewRepo.DeleteReview(ctx, tx, reviewID); err != nil {
+			return err
+		}
+		if err := s.reviewRepo.RecalcBookRating(ctx, tx, bookID); err != nil {
+			return err
+		}
+		// 读者删除自己的评价不属于管理操作，不记审计
+		if review.UserID == userID {
+			return nil
+		}
+		return s.auditService.Record(ctx, tx, model.AuditActionDelete, model.AuditEntityReview, reviewID, review, nil)
+	})
+}
+
+// ModerateReview 管理员隐藏或恢复评价，隐藏的评价不计入评分
+func (s *ReviewService) ModerateReview(ctx context.Context, bookID, reviewID uint64, req *request.ModerateReviewRequest) (*response.ReviewItem, error) {
+	review, err := s.getBookReview(ctx, bookID, reviewID)
+	if err != nil {
+		return nil, err
+	}
+
+	updates := map[string]interface{}{
+		"status":        model.ReviewStatusVisible,
+		"hidden_reason": "",
+	}
+	if *req.Hidden {
+		updates["status"] = model.ReviewStatusHidden
+		if req.Reason != nil {
+			updates["hidden_reason"] = *req.Reason
+		}
+	}
+
+	err = s.reviewRepo.DB().Transaction(func(tx *gorm.DB) error {
+		if err := s.reviewRepo.UpdateReviewFields(ctx, tx, reviewID, updates); err != nil {
+			return err
+		}
+		if err := s.reviewRepo.RecalcBookRating(ctx, tx, bookID); err != nil {
+			return err
+		}
+		return s.auditService.Record(ctx, tx, model.AuditActionUpdate, model.AuditEntityReview, reviewID, review, updates)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return s.getReviewItem(ctx, reviewID)
+}
+
+func (s *ReviewService) getBook(ctx context.Context, bookID uint64) (model.Book, error) {
+	book, err := s.bookRepo.GetBookByID(ctx, bookID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return model.Book{}, common.ErrBookNotFound
+		}
+		return model.Book{}, err
+	}
+	return book, nil
+}
+
+// getBookReview 获取评价并确认其属于该图书
+func (s *ReviewService) getBookReview(ctx context.Context, bookID, reviewID uint64) (model.Review, error) {
+	review, err := s.reviewRepo.GetReviewByID(ctx, reviewID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return model.Review{}, common.ErrReviewNotFound
+		}
+		return model.Review{}, err
+	}
+	if review.BookID != bookID {
+		return model.Review{}, common.ErrReviewNotFound
+	}
+	return review, nil
+}
+
+func (s *ReviewService) getReviewItem(ctx context.Context, reviewID uint64) (*response.ReviewItem, error) {
+	review, err := s.reviewRepo.GetReviewByID(ctx, reviewID)
+	if err != nil {
+		return nil, err
+	}
+	item := toReviewItem(review)
+	return &item, nil
+}
+
+func toReviewItem(r model.Review) response.ReviewItem {
+	return response.ReviewItem{
+		ID:     r.ID,
+		BookID: r.BookID,
+		User: response.ReviewUser{
+			ID:       r.User.ID,
+			Username: r.User.Username,
+		},
+		Rating:       r.Rating,
+		Content:      r.Content,
+		Status:       r.Status,
+		HiddenReason: r.HiddenReason,
+		CreatedAt:    r.CreatedAt,
+		UpdatedAt:    r.UpdatedAt,
+	}
+}
@@ -0,0 +1,303 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"library-system/common"
+	"library-system/dto/request"
+	"library-system/dto/response"
+	"library-system/model"
+	"library-system/repository"
+	"sort"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// 角色权限缓存的有效期，多实例部署时其他实例最迟在此时间后感知授权变更
+const permissionCacheTTL = time.Minute
+
+type RoleService struct {
+	roleRepo     *repository.RoleRepository
+	auditService *AuditService
+
+	mu       sync.RWMutex
+	cache    map[string]map[string]bool
+	loadedAt time.Time
+}
+
+func NewRoleService(roleRepo *repository.RoleRepository, auditService *AuditService) *RoleService {
+	return &RoleService{
+		roleRepo:     roleRepo,
+		auditService: auditService,
+	}
+}
+
+// Permissions 返回角色拥有的权限集合，admin 始终拥有全部权限，未知角色没有任何权限
+func (s *RoleService) Permissions(ctx context.Context, role string) (map[string]bool, error) {
+	if role == model.RoleAdmin {
+		all := make(map[string]bool, len(model.AllPermissions))
+		for _, p := range model.AllPermissions {
+			all[p] = true
+		}
+		return all, nil
+	}
+
+	s.mu.RLock()
+	if s.cache != nil && time.Since(s.loadedAt) < permissionCacheTTL {
+		perms := s.cache[role]
+		s.mu.RUnlock()
+		return perms, nil
+	}
+	s.mu.RUnlock()
+
+	roles, err := s.roleRepo.GetRoleList(ctx)
+	if err != nil {
+		return nil, err
+	}
+	cache := make(map[string]map[string]bool, len(roles))
+	for _, r := range roles {
+		perms := make(map[string]bool, len(r.Permissions))
+		for _, p := range r.Permissions {
+			perms[p.Permission] = true
+		}
+		cache[r.Name] = perms
+	}
+
+	s.mu.Lock()
+	s.cache = cache
+	s.loadedAt = time.Now()
+	s.mu.Unlock()
+
+	return cache[role], nil
+}
+
+// invalidate 授权变更后清空缓存，下次查询时重新加载
+func (s *RoleService) invalidate() {
+	s.mu.Lock()
+	s.cache = nil
+	s.mu.Unlock()
+}
+
+// RoleExists 判断角色是否存在，供分配角色时校验
+func (s *RoleService) RoleExists(ctx context.Context, name string) (bool, error) {
+	_, err := s.roleRepo.GetRoleByName(ctx, name)
+	if err == nil {
+		return true, nil
+	}
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return false, nil
+	}
+	return false, err
+}
+
+// GetPermissionList 获取系统支持的全部权限
+func (s *RoleService) GetPermissionList() *response.GetPermissionListResponse {
+	return &response.GetPermissionListResponse{
+		Permissions: append([]string(nil), model.AllPermissions...),
+	}
+}
+
+// GetRoleList 获取全部角色及其权限
+func (s *RoleService) GetRoleList(ctx context.Context) (*response.GetRoleListResponse, error) {
+	roles, err := s.roleRepo.GetRoleList(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	items := make([]response.RoleItem, 0, len(roles))
+	for _, r := range roles {
+		items = append(items, toRoleItem(r))
+	}
+	return &response.GetRoleListResponse{Roles: items}, nil
+}
+
+func (s *RoleService) CreateRole(ctx context.Context, req *request.CreateRoleRequest) (*response.RoleItem, error) {
+	permissions, err := normalizePermissions(req.Permissions)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := s.roleRepo.GetRoleByName(ctx, req.Name); err == nil {
+		return nil, common.ErrRoleExist
+	} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+
+	role := model.Role{
+		Name:        req.Name,
+		Description: req.Description,
+	}
+	err = s.roleRepo.DB().Transaction(func(tx *gorm.DB) error {
+		if err := s.roleRepo.CreateRole(ctx, tx, &role); err != nil {
+			return err
+		}
+		if err := s.roleRepo.ReplacePermissions(ctx, tx, role.ID, permissions); err != nil {
+			return err
+		}
+		return s.auditService.Record(ctx, tx, model.AuditActionCreate, model.AuditEntityRole, role.ID, nil, roleSnapshot(role, permissions))
+	})
+	if err != nil {
+		return nil, err
+	}
+	s.invalidate()
+
+	return s.getRoleItem(ctx, role.ID)
+}
+
+func (s *RoleService) UpdateRole(ctx context.Context, id uint64, req *request.UpdateRoleRequest) (*response.RoleItem, error) {
+	if req.Description == nil && req.Permissions == nil {
+		return nil, common.ErrBadRequest
+	}
+
+	role, err := s.getRole(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if role.Name == model.RoleAdmin {
+		return nil, common.ErrAdminRoleImmutable
+	}
+
+	before := roleSnapshot(role, rolePermissionNames(role))
+	updates := make(map[string]interface{})
+	if req.Description != nil {
+		updates["description"] = *req.Description
+	}
+
+	var permissions []string
+	if req.Permissions != nil {
+		if permissions, err = normalizePermissions(req.Permissions); err != nil {
+			return nil, err
+		}
+	}
+
+	err = s.roleRepo.DB().Transaction(func(tx *gorm.DB) error {
+		if len(updates) > 0 {
+			if err := s.roleRepo.UpdateRoleFields(ctx, tx, id, updates); err != nil {
+				return err
+			}
+		}
+
+		after := make(map[string]interface{}, len(updates)+1)
+		for k, v := range updates {
+			after[k] = v
+		}
+		if req.Permissions != nil {
+			if err := s.roleRepo.ReplacePermissions(ctx, tx, id, permissions); err != nil {
+				return err
+			}
+			after["permissions"] = permissions
+		}
+		return s.auditService.Record(ctx, tx, model.AuditActionUpdate, model.AuditEntityRole, id, before, after)
+	})
+	if err != nil {
+		return nil, err
+	}
+	s.invalidate()
+
+	return s.getRoleItem(ctx, id)
+}
+
+// DeleteRole 删除自定义角色，内置角色与仍被用户使用的角色不可删除
+func (s *RoleService) DeleteRole(ctx context.Context, id uint64) error {
+	role, err := s.getRole(ctx, id)
+	if err != nil {
+		return err
+	}
+	if role.Builtin {
+		return common.ErrBuiltinRoleUndeletable
+	}
+
+	count, err := s.roleRepo.CountUsersWithRole(ctx, role.Name)
+	if err != nil {
+		return err
+	}
+	if count > 0 {
+		return common.ErrRoleInUse
+	}
+
+	err = s.roleRepo.DB().Transaction(func(tx *gorm.DB) error {
+		if err := s.roleRepo.DeleteRole(ctx, tx, id); err != nil {
+			return err
+		}
+		return s.auditService.Record(ctx, tx, model.AuditActionDelete, model.AuditEntityRole, id, roleSnapshot(role, rolePermissionNames(role)), nil)
+	})
+	if err != nil {
+		return err
+	}
+	s.invalidate()
+	return nil
+}
+
+func (s *RoleService) getRole(ctx context.Context, id uint64) (model.Role, error) {
+	role, err := s.roleRepo.GetRoleByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return model.Role{}, common.ErrRoleNotFound
+		}
+		return model.Role{}, err
+	}
+	return role, nil
+}
+
+func (s *RoleService) getRoleItem(ctx context.Context, id uint64) (*response.RoleItem, error) {
+	role, err := s.getRole(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	item := toRoleItem(role)
+	return &item, nil
+}
+
+// normalizePermissions 校验权限并去重排序
+func normalizePermissions(permissions []string) ([]string, error) {
+	seen := make(map[string]bool, len(permissions))
+	result := make([]string, 0, len(permissions))
+	for _, p := range permissions {
+		if !model.IsValidPermission(p) {
+			bizErr := *common.ErrInvalidPermission
+			return nil, bizErr.WithDetails(map[string]interface{}{"permission": p})
+		}
+		if seen[p] {
+			continue
+		}
+		seen[p] = true
+		result = append(result, p)
+	}
+	sort.Strings(result)
+	return result, nil
+}
+
+func rolePermissionNames(role model.Role) []string {
+	if role.Name == model.RoleAdmin {
+		return append([]string(nil), model.AllPermissions...)
+	}
+	names := make([]string, 0, len(role.Permissions))
+	for _, p := range role.Permissions {
+		names = append(names, p.Permission)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// roleSnapshot 审计日志中的角色快照，权限以名称列表记录
+func roleSnapshot(role model.Role, permissions []string) map[string]interface{} {
+	return map[string]interface{}{
+		"name":        role.Name,
+		"description": role.Description,
+		"permissions": permissions,
+	}
+}
+
+func toRoleItem(r model.Role) response.RoleItem {
+	return response.RoleItem{
+		ID:          r.ID,
+		Name:        r.Name,
+		Description: r.Description,
+		Builtin:     r.Builtin,
+		Permissions: rolePermissionNames(r),
+		CreatedAt:   r.CreatedAt.UTC().Format(time.RFC3339),
+		UpdatedAt:   r.UpdatedAt.UTC().Format(time.RFC3339),
+	}
+}
@@ -0,0 +1,248 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"library-system/common"
+	"library-system/dto/response"
+	"library-system/model"
+	"library-system/repository"
+	"library-system/utils"
+	"log"
+	"sort"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"gorm.io/gorm"
+)
+
+// 会话记录的 User-Agent 最大长度
+const maxSessionUserAgentLen = 255
+
+// sessionStore 会话与 Token 家族所需的存储操作，由 repository.TokenRdb 实现
+type sessionStore interface {
+	SaveSession(ctx context.Context, userID uint64, session *repository.Session) error
+	GetSession(ctx context.Context, userID uint64, sessionID string) (*repository.Session, error)
+	ListSessions(ctx context.Context, userID uint64) ([]repository.Session, error)
+	DeleteSession(ctx context.Context, userID uint64, sessionID string) error
+	AddSessionToken(ctx context.Context, userID uint64, sessionID, tokenID string) error
+	GetSessionTokens(ctx context.Context, userID uint64, sessionID string) ([]string, error)
+	MarkTokenRotated(ctx context.Context, userID uint64, tokenID, sessionID string) error
+	GetRotatedTokenSession(ctx context.Context, userID uint64, tokenID string) (string, error)
+	DeleteRefreshToken(ctx context.Context, userID uint64, tokenID string) error
+	DeleteAllUserRefreshTokens(ctx context.Context, userID uint64) error
+	AddToBlacklist(ctx context.Context, tokenID string, ttl time.Duration) error
+}
+
+// SessionService 管理用户的登录会话（每台设备一个），会话信息与 Refresh Token 一同保存在 Redis
+type SessionService struct {
+	userRepo     *repository.UserRepository
+	auditService *AuditService
+	store        sessionStore
+}
+
+func NewSessionService(userRepo *repository.UserRepository, auditService *AuditService) *SessionService {
+	return &SessionService{
+		userRepo:     userRepo,
+		auditService: auditService,
+		store:        repository.Rdb,
+	}
+}
+
+// Start 登录成功后创建会话，记录设备与 IP
+func (s *SessionService) Start(ctx context.Context, userID uint64, sessionID, tokenID string) error {
+	now := time.Now().Unix()
+	session := repository.Session{
+		ID:         sessionID,
+		TokenID:    tokenID,
+		CreatedAt:  now,
+		LastUsedAt: now,
+	}
+	fillSessionClient(ctx, &session)
+	if err := s.store.SaveSession(ctx, userID, &session); err != nil {
+		return err
+	}
+	return s.store.AddSessionToken(ctx, userID, sessionID, tokenID)
+}
+
+// Rotate 刷新 Token 后将旧 Token 标记为已轮换，并更新会话的当前 Token 与最近使用时间；
+// 会话已过期时按当前请求重新建立
+func (s *SessionService) Rotate(ctx context.Context, userID uint64, sessionID, oldTokenID, tokenID string) error {
+	if err := s.store.MarkTokenRotated(ctx, userID, oldTokenID, sessionID); err != nil {
+		return err
+	}
+
+	session, err := s.store.GetSession(ctx, userID, sessionID)
+	if errors.Is(err, redis.Nil) {
+		return s.Start(ctx, userID, sessionID, tokenID)
+	}
+	if err != nil {
+		return err
+	}
+	if err := s.store.AddSessionToken(ctx, userID, sessionID, tokenID); err != nil {
+		return err
+	}
+
+	session.TokenID = tokenID
+	session.LastUsedAt = time.Now().Unix()
+	fillSessionClient(ctx, session)
+	return s.store.SaveSession(ctx, userID, session)
+}
+
+// End 结束当前会话（登出），tokenID 为当前请求的 Token，兼容没有会话的旧 Token
+func (s *SessionService) End(ctx context.Context, userID uint64, sessionID, tokenID string) error {
+	if err := s.revokeToken(ctx, userID, tokenID); err != nil {
+		return err
+	}
+	if sessionID == "" {
+		return nil
+	}
+	return s.revokeFamily(ctx, userID, sessionID)
+}
+
+// DetectReuse 在 Refresh Token 已不存在时调用：若它是已轮换的旧 Token，说明可能被盗用，
+// 吊销其所属会话的全部 Token 并记录审计日志，返回 true
+func (s *SessionService) DetectReuse(ctx context.Context, userID uint64, tokenID string) (bool, error) {
+	sessionID, err := s.store.GetRotatedTokenSession(ctx, userID, tokenID)
+	if err != nil {
+		if errors.Is(err, redis.Nil) {
+			return false, nil
+		}
+		return false, err
+	}
+
+	if err := s.revokeFamily(ctx, userID, sessionID); err != nil {
+		return true, err
+	}
+
+	log.Printf("用户 %d 的会话 %s 重复使用已轮换的 Refresh Token %s，疑似被盗用，已吊销该会话", userID, sessionID, tokenID)
+	s.auditService.Log(ctx, model.AuditActionRefreshTokenReuse, model.AuditEntityUser, userID, nil, map[string]interface{}{
+		"session_id": sessionID,
+		"token_id":   tokenID,
+	})
+	return true, nil
+}
+
+// GetSessionList 获取用户的会话列表，最近使用的在前；currentID 为发起请求的会话
+func (s *SessionService) GetSessionList(ctx context.Context, userID uint64, currentID string) (*response.GetSessionListResponse, error) {
+	sessions, err := s.store.ListSessions(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(sessions, func(i, j int) bool {
+		return sessions[i].LastUsedAt > sessions[j].LastUsedAt
+	})
+
+	items := make([]response.SessionItem, 0, len(sessions))
+	for _, session := range sessions {
+		items = append(items, response.SessionItem{
+			ID:         session.ID,
+			UserAgent:  session.UserAgent,
+			IP:         session.IP,
+			CreatedAt:  time.Unix(session.CreatedAt, 0).UTC().Format(time.RFC3339),
+			LastUsedAt: time.Unix(session.LastUsedAt, 0).UTC().Format(time.RFC3339),
+			Current:    currentID != "" && session.ID == currentID,
+		})
+	}
+	return &response.GetSessionListResponse{Sessions: items}, nil
+}
+
+// Revoke 吊销指定会话，其 Access Token 立即失效
+func (s *SessionService) Revoke(ctx context.Context, userID uint64, sessionID string) error {
+	session, err := s.store.GetSession(ctx, userID, sessionID)
+	if err != nil {
+		if errors.Is(err, redis.Nil) {
+			return common.ErrSessionNotFound
+		}
+		return err
+	}
+
+	if err := s.revokeToken(ctx, userID, session.TokenID); err != nil {
+		return err
+	}
+	return s.revokeFamily(ctx, userID, sessionID)
+}
+
+// RevokeAll 吊销用户的全部会话（在所有设备上登出）
+func (s *SessionService) RevokeAll(ctx context.Context, userID uint64) error {
+	sessions, err := s.store.ListSessions(ctx, userID)
+	if err != nil {
+		return err
+	}
+	for _, session := range sessions {
+		if err := s.revokeToken(ctx, userID, session.TokenID); err != nil {
+			return err
+		}
+		if err := s.revokeFamily(ctx, userID, session.ID); err != nil {
+			return err
+		}
+	}
+
+	// 清理升级前签发、没有会话记录的 Refresh Token
+	return s.store.DeleteAllUserRefreshTokens(ctx, userID)
+}
+
+// GetUserSessionList 管理员查看指定用户的会话
+func (s *SessionService) GetUserSessionList(ctx context.Context, userID uint64) (*response.GetSessionListResponse, error) {
+	if err := s.checkUser(ctx, userID); err != nil {
+		return nil, err
+	}
+	return s.GetSessionList(ctx, userID, "")
+}
+
+// ForceLogout 管理员强制用户在所有设备上登出
+func (s *SessionService) ForceLogout(ctx context.Context, userID uint64) error {
+	if err := s.checkUser(ctx, userID); err != nil {
+		return err
+	}
+	if err := s.RevokeAll(ctx, userID); err != nil {
+		return err
+	}
+	s.auditService.Log(ctx, model.AuditActionRevokeSessions, model.AuditEntityUser, userID, nil, nil)
+	return nil
+}
+
+func (s *SessionService) checkUser(ctx context.Context, userID uint64) error {
+	if _, err := s.userRepo.GetUserByUserID(ctx, userID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return common.ErrNotFound
+		}
+		return err
+	}
+	return nil
+}
+
+// revokeToken 删除 Refresh Token，并将共用同一 TokenID 的 Access Token 加入黑名单
+func (s *SessionService) revokeToken(ctx context.Context, userID uint64, tokenID string) error {
+	if err := s.store.DeleteRefreshToken(ctx, userID, tokenID); err != nil {
+		return err
+	}
+	return s.store.AddToBlacklist(ctx, tokenID, utils.AccessTokenTTL)
+}
+
+// revokeFamily 吊销会话签发过的全部 Token（未过期的 Access Token 一并加入黑名单），并删除会话
+func (s *SessionService) revokeFamily(ctx context.Context, userID uint64, sessionID string) error {
+	tokenIDs, err := s.store.GetSessionTokens(ctx, userID, sessionID)
+	if err != nil {
+		return err
+	}
+	for _, tokenID := range tokenIDs {
+		if err := s.revokeToken(ctx, userID, tokenID); err != nil {
+			return err
+		}
+	}
+	return s.store.DeleteSession(ctx, userID, sessionID)
+}
+
+// fillSessionClient 从请求元数据中记录客户端信息
+func fillSessionClient(ctx context.Context, session *repository.Session) {
+	meta := common.RequestMetaFrom(ctx)
+	if meta == nil {
+		return
+	}
+	session.IP = meta.IP
+	session.UserAgent = meta.UserAgent
+	if len(session.UserAgent) > maxSessionUserAgentLen {
+		session.UserAgent = session.UserAgent[:maxSessionUserAgentLen]
+	}
+}
@@ -0,0 +1,243 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"library-system/model"
+	"library-system/repository"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"gorm.io/driver/mysql"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// fakeSessionStore 内存中的会话存储，不存在的键与 TokenRdb 一样返回 redis.Nil
+type fakeSessionStore struct {
+	sessions      map[string]repository.Session
+	sessionTokens map[string][]string
+	rotated       map[string]string
+	refreshTokens map[string]bool
+	blacklist     map[string]bool
+}
+
+func newFakeSessionStore() *fakeSessionStore {
+	return &fakeSessionStore{
+		sessions:      make(map[string]repository.Session),
+		sessionTokens: make(map[string][]string),
+		rotated:       make(map[string]string),
+		refreshTokens: make(map[string]bool),
+		blacklist:     make(map[string]bool),
+	}
+}
+
+func userKey(userID uint64, id string) string {
+	return fmt.Sprintf("%d:%s", userID, id)
+}
+
+func (f *fakeSessionStore) SaveSession(ctx context.Context, userID uint64, session *repository.Session) error {
+	f.sessions[userKey(userID, session.ID)] = *session
+	return nil
+}
+
+func (f *fakeSessionStore) GetSession(ctx context.Context, userID uint64, sessionID string) (*repository.Session, error) {
+	session, ok := f.sessions[userKey(userID, sessionID)]
+	if !ok {
+		return nil, redis.Nil
+	}
+	return &session, nil
+}
+
+func (f *fakeSessionStore) ListSessions(ctx context.Context, userID uint64) ([]repository.Session, error) {
+	var sessions []repository.Session
+	for key, session := range f.sessions {
+		if strings.HasPrefix(key, userKey(userID, "")) {
+			sessions = append(sessions, session)
+		}
+	}
+	return sessions, nil
+}
+
+func (f *fakeSessionStore) DeleteSession(ctx context.Context, userID uint64, sessionID string) error {
+	delete(f.sessions, userKey(userID, sessionID))
+	delete(f.sessionTokens, userKey(userID, sessionID))
+	return nil
+}
+
+func (f *fakeSessionStore) AddSessionToken(ctx context.Context, userID uint64, sessionID, tokenID string) error {
+	key := userKey(userID, sessionID)
+	f.sessionTokens[key] = append(f.sessionTokens[key], tokenID)
+	return nil
+}
+
+func (f *fakeSessionStore) GetSessionTokens(ctx context.Context, userID uint64, sessionID string) ([]string, error) {
+	return f.sessionTokens[userKey(userID, sessionID)], nil
+}
+
+func (f *fakeSessionStore) MarkTokenRotated(ctx context.Context, userID uint64, tokenID, sessionID string) error {
+	f.rotated[userKey(userID, tokenID)] = sessionID
+	return nil
+}
+
+func (f *fakeSessionStore) GetRotatedTokenSession(ctx context.Context, userID uint64, tokenID string) (string, error) {
+	sessionID, ok := f.rotated[userKey(userID, tokenID)]
+	if !ok {
+		return "", redis.Nil
+	}
+	return sessionID, nil
+}
+
+func (f *fakeSessionStore) DeleteRefreshToken(ctx context.Context, userID uint64, tokenID string) error {
+	delete(f.refreshTokens, userKey(userID, tokenID))
+	return nil
+}
+
+func (f *fakeSessionStore) DeleteAllUserRefreshTokens(ctx context.Context, userID uint64) error {
+	for key := range f.refreshTokens {
+		if strings.HasPrefix(key, userKey(userID, "")) {
+			delete(f.refreshTokens, key)
+		}
+	}
+	return nil
+}
+
+func (f *fakeSessionStore) AddToBlacklist(ctx context.Context, tokenID string, ttl time.Duration) error {
+	f.blacklist[tokenID] = true
+	return nil
+}
+
+// newTestAuditService 返回不连接数据库的审计服务，写入的审计事件记录在 events 中
+func newTestAuditService(t *testing.T) (*AuditService, *[]model.AuditEvent) {
+	t.Helper()
+	db, err := gorm.Open(mysql.New(mysql.Config{
+		DSN:                       "test@tcp(127.0.0.1:1)/test",
+		SkipInitializeWithVersion: true,
+	}), &gorm.Config{DryRun: true, DisableAutomaticPing: true, Logger: logger.Discard})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var events []model.AuditEvent
+	err = db.Callback().Create().After("gorm:create").Register("test:capture_audit", func(tx *gorm.DB) {
+		if event, ok := tx.Statement.Dest.(*model.AuditEvent); ok {
+			events = append(events, *event)
+		}
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return NewAuditService(repository.NewAuditRepository(db)), &events
+}
+
+func newTestSessionService(t *testing.T) (*SessionService, *fakeSessionStore, *[]model.AuditEvent) {
+	t.Helper()
+	auditService, events := newTestAuditService(t)
+	store := newFakeSessionStore()
+	s := NewSessionService(nil, auditService)
+	s.store = store
+	return s, store, events
+}
+
+func TestDetectReuseRevokesSessionFamily(t *testing.T) {
+	s, store, events := newTestSessionService(t)
+	ctx := context.Background()
+
+	// 会话 s1 登录后刷新两次：t1 -> t2 -> t3；会话 s2 不受影响
+	if err := s.Start(ctx, 7, "s1", "t1"); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Rotate(ctx, 7, "s1", "t1", "t2"); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Rotate(ctx, 7, "s1", "t2", "t3"); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Start(ctx, 7, "s2", "u1"); err != nil {
+		t.Fatal(err)
+	}
+	store.refreshTokens[userKey(7, "t3")] = true
+	store.refreshTokens[userKey(7, "u1")] = true
+
+	reused, err := s.DetectReuse(ctx, 7, "t1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reused {
+		t.Fatal("reused = false, want true for a rotated token")
+	}
+
+	for _, tokenID := range []string{"t1", "t2", "t3"} {
+		if !store.blacklist[tokenID] {
+			t.Errorf("token %s not blacklisted", tokenID)
+		}
+	}
+	if store.refreshTokens[userKey(7, "t3")] {
+		t.Error("current refresh token of the reused session not deleted")
+	}
+	if _, err := store.GetSession(ctx, 7, "s1"); err != redis.Nil {
+		t.Errorf("session s1 still exists: %v", err)
+	}
+
+	if store.blacklist["u1"] || !store.refreshTokens[userKey(7, "u1")] {
+		t.Error("other session should stay valid")
+	}
+	if _, err := store.GetSession(ctx, 7, "s2"); err != nil {
+		t.Errorf("session s2 = %v, want kept", err)
+	}
+
+	if len(*events) != 1 || (*events)[0].Action != model.AuditActionRefreshTokenReuse || (*events)[0].EntityID != 7 {
+		t.Fatalf("audit events = %+v, want one refresh_token_reuse for user 7", *events)
+	}
+}
+
+func TestDetectReuseIgnoresUnknownToken(t *testing.T) {
+	s, store, events := newTestSessionService(t)
+	ctx := context.Background()
+
+	if err := s.Start(ctx, 7, "s1", "t1"); err != nil {
+		t.Fatal(err)
+	}
+	// 未轮换过的 Token（如已过期或已登出）不视为盗用
+	for _, tokenID := range []string{"t1", "unknown"} {
+		reused, err := s.DetectReuse(ctx, 7, tokenID)
+		if err != nil || reused {
+			t.Fatalf("DetectReuse(%s) = %v, %v, want false", tokenID, reused, err)
+		}
+	}
+	// 其他用户的已轮换 Token 不影响当前用户
+	if err := s.Rotate(ctx, 8, "s9", "x1", "x2"); err != nil {
+		t.Fatal(err)
+	}
+	if reused, err := s.DetectReuse(ctx, 7, "x1"); err != nil || reused {
+		t.Fatalf("DetectReuse(other user) = %v, %v, want false", reused, err)
+	}
+
+	if len(store.blacklist) != 0 || len(*events) != 0 {
+		t.Fatalf("blacklist=%v events=%d, want nothing revoked", store.blacklist, len(*events))
+	}
+	if _, err := store.GetSession(ctx, 7, "s1"); err != nil {
+		t.Fatalf("session s1 = %v, want kept", err)
+	}
+}
+
+func TestRotateRestartsExpiredSession(t *testing.T) {
+	s, store, _ := newTestSessionService(t)
+	ctx := context.Background()
+
+	// 会话记录已过期，刷新时按当前请求重新建立，旧 Token 仍记为已轮换
+	if err := s.Rotate(ctx, 7, "s1", "t1", "t2"); err != nil {
+		t.Fatal(err)
+	}
+	session, err := store.GetSession(ctx, 7, "s1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if session.TokenID != "t2" {
+		t.Fatalf("session token = %s, want t2", session.TokenID)
+	}
+	if reused, err := s.DetectReuse(ctx, 7, "t1"); err != nil || !reused {
+		t.Fatalf("DetectReuse(t1) = %v, %v, want true", reused, err)
+	}
+}
@@ -6,19 +6,22 @@ import (
 	"library-system/dto/request"
 	"library-system/dto/response"
 	"library-system/repository"
+	"math"
 	"sort"
 	"time"
 )
 
 type StatsService struct {
-	statsRepo *repository.StatsRepository
-	userRepo  *repository.UserRepository
+	statsRepo    *repository.StatsRepository
+	userRepo     *repository.UserRepository
+	categoryRepo *repository.CategoryRepository
 }
 
-func NewStatsService(statsRepo *repository.StatsRepository, userRepo *repository.UserRepository) *StatsService {
+func NewStatsService(statsRepo *repository.StatsRepository, userRepo *repository.UserRepository, categoryRepo *repository.CategoryRepository) *StatsService {
 	return &StatsService{
-		statsRepo: statsRepo,
-		userRepo:  userRepo,
+		statsRepo:    statsRepo,
+		userRepo:     userRepo,
+		categoryRepo: categoryRepo,
 	}
 }
 
@@ -118,7 +121,8 @@ func (s *StatsService) GetBorrowStats(ctx context.Context, req *request. GetBorr
 // GetUserStats 获取用户借阅统计
 func (s *StatsService) GetUserStats(ctx context.Context, userID uint64) (*response.GetUserStatsResponse, error) {
 	// 获取用户信息
-	user, err := s.userRepo.GetUserByUserID(ctx, userID)
+	// 已删除的用户仍可查看历史统计
+	user, err := s.userRepo.GetUserByUserIDUnscoped(ctx, userID)
 	if err != nil {
 		return nil, common.ErrNotFound
 	}
@@ -190,7 +194,19 @@ func (s *StatsService) GetPopularBooks(ctx context.Context, req *request. GetPop
 		startDate = &t
 	}
 
-	books, err := s.statsRepo.GetPopularBooks(ctx, limit, startDate, req.CategoryID)
+	var categoryIDs []uint
+	if req.CategoryID != nil {
+		categoryIDs = []uint{*req.CategoryID}
+		if req.IncludeDescendants {
+			ids, err := s.categoryRepo.GetDescendantIDs(ctx, *req.CategoryID)
+			if err != nil {
+				return nil, err
+			}
+			categoryIDs = ids
+		}
+	}
+
+	books, err := s.statsRepo.GetPopularBooks(ctx, limit, startDate, categoryIDs)
 	if err != nil {
 		return nil, err
 	}
@@ -239,4 +255,73 @@ func (s *StatsService) GetCategoryStats(ctx context. Context) (*response.GetCate
 	return &response. GetCategoryStatsResponse{
 		Categories: items,
 	}, nil
-}
\ No newline at end of file
+}
+
+// GetDamagedItems 获取损坏/遗失图书报表
+func (s *StatsService) GetDamagedItems(ctx context.Context, req *request.GetDamagedItemsRequest) (*response.GetDamagedItemsResponse, error) {
+	if req.Page <= 0 {
+		req.Page = 1
+	}
+	if req.Limit <= 0 || req.Limit > 100 {
+		req.Limit = 10
+	}
+
+	var startDate, endDate *time.Time
+	if req.StartDate != "" {
+		t, err := time.Parse("2006-01-02", req.StartDate)
+		if err != nil {
+			return nil, common.ErrBadRequest
+		}
+		startDate = &t
+	}
+	if req.EndDate != "" {
+		t, err := time.Parse("2006-01-02", req.EndDate)
+		if err != nil {
+			return nil, common.ErrBadRequest
+		}
+		// 结束日期包含当天
+		t = t.Add(24*time.Hour - time.Second)
+		endDate = &t
+	}
+
+	records, total, err := s.statsRepo.GetDamagedItems(ctx, req.Condition, startDate, endDate, req.Page, req.Limit)
+	if err != nil {
+		return nil, err
+	}
+
+	summary, err := s.statsRepo.GetDamagedItemsSummary(ctx, req.Condition, startDate, endDate)
+	if err != nil {
+		return nil, err
+	}
+
+	items := make([]response.DamagedItem, len(records))
+	for i, record := range records {
+		items[i] = response.DamagedItem{
+			BorrowID:        record.BorrowID,
+			BookID:          record.BookID,
+			Title:           record.Title,
+			ISBN:            record.ISBN,
+			CopyID:          record.CopyID,
+			Barcode:         record.Barcode,
+			UserID:          record.UserID,
+			Username:        record.Username,
+			Condition:       record.Condition,
+			Remark:          record.Remark,
+			CompensationFee: record.CompensationFee,
+			ReturnDate:      record.ReturnDate,
+		}
+	}
+
+	return &response.GetDamagedItemsResponse{
+		Total:      total,
+		Page:       req.Page,
+		Limit:      req.Limit,
+		TotalPages: int(math.Ceil(float64(total) / float64(req.Limit))),
+		Summary: response.DamagedItemsSummary{
+			DamagedCount:      summary.DamagedCount,
+			LostCount:         summary.LostCount,
+			TotalCompensation: summary.TotalCompensation,
+		},
+		Items: items,
+	}, nil
+}
@@ -6,24 +6,75 @@ import (
 	"library-system/dto/request"
 	"library-system/dto/response"
 	"library-system/model"
+	"library-system/notifier"
 	"library-system/repository"
 	"library-system/utils"
 	"time"
 	"errors"
+	"fmt"
 	"gorm.io/gorm"
 	"math"
 	"log"
+
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	// 同一用户两次申请重置密码的最小间隔
+	passwordResetCooldown = time.Minute
+
+	// 重新发送验证邮件的最小间隔
+	emailVerifyCooldown = time.Minute
+
+	// 两次修改邮箱的最小间隔，避免借此向任意地址发送大量邮件
+	emailChangeCooldown = time.Minute
+
+	// 账号邮件的发送超时
+	accountMailTimeout = 30 * time.Second
 )
 
+// EmailVerificationPolicy 自助注册的邮箱验证策略
+type EmailVerificationPolicy struct {
+	Enabled    bool          // 注册后为待验证状态，验证邮箱后激活
+	AllowLogin bool          // 待验证用户能否登录（只能浏览）
+	TokenTTL   time.Duration // 验证链接有效期
+	VerifyURL  string        // 前端验证页面地址
+}
+
 type UserService struct {
 	userRepo *repository.UserRepository
 	overdueService *OverdueService
+	auditService *AuditService
+	sessionService *SessionService
+	roleRepo *repository.RoleRepository
+	mailSender notifier.MailSender
+	resetURL string
+	resetTokenTTL time.Duration
+	verification EmailVerificationPolicy
 }
 
-func NewUserService(repo *repository.UserRepository, overdueService *OverdueService) *UserService {
+func NewUserService(
+	repo *repository.UserRepository,
+	overdueService *OverdueService,
+	auditService *AuditService,
+	sessionService *SessionService,
+	roleRepo *repository.RoleRepository,
+	mailSender notifier.MailSender,
+	resetURL string,
+	resetTokenTTL time.Duration,
+	verification EmailVerificationPolicy,
+) *UserService {
 	return &UserService{
 		userRepo:        repo,
 		overdueService: overdueService,
+		auditService: auditService,
+		sessionService: sessionService,
+		roleRepo: roleRepo,
+		mailSender: mailSender,
+		resetURL: resetURL,
+		resetTokenTTL: resetTokenTTL,
+		verification: verification,
 	}
 }
 
@@ -34,16 +85,15 @@ func (s *UserService) Register(ctx context.Context, req *request.UserRegisterReq
 		Email:    req.Email,
 		Phone:    req.Phone,
 	}
-
-	// 判断是否存在相同用户名
-	if _, err := s.userRepo.GetUserByUsername(ctx, user.Username); err == nil {
-		return nil, common.ErrUsernameExist
-	} else if !errors.Is(err, gorm.ErrRecordNotFound) {
-		return nil, err
+	if s.verification.Enabled {
+		user.Status = model.UserStatusPendingVerification
 	}
 
-	// 判断是否存在相同邮箱
-	if _, err := s.userRepo.GetUserByUsername(ctx, user.Username); err == nil {
+	// 判断用户名、邮箱是否已被占用（回收站中的用户同样占用）
+	if existing, err := s.userRepo.GetUserByUsernameOrEmailUnscoped(ctx, user.Username, user.Email); err == nil {
+		if existing.Username == user.Username {
+			return nil, common.ErrUsernameExist
+		}
 		return nil, common.ErrEmailExist
 	} else if !errors.Is(err, gorm.ErrRecordNotFound) {
 		return nil, err
@@ -57,17 +107,25 @@ func (s *UserService) Register(ctx context.Context, req *request.UserRegisterReq
 	user.Password = hashedPwd
 
 	// 调用数据库函数
-	err = s.userRepo.CreateUser(ctx, &user)
+	err = s.userRepo.CreateUser(ctx, s.userRepo.DB(), &user)
 	if err != nil {
 		return nil, err
 	}
 
+	// 验证邮件发送失败不影响注册，用户可申请重新发送
+	if user.Status == model.UserStatusPendingVerification {
+		if err := s.sendVerificationMail(ctx, user); err != nil {
+			log.Printf("签发邮箱验证令牌失败 user=%d: %v", user.ID, err)
+		}
+	}
+
 	// 构建返回值
 	data := &response.UserRegisterResponse{
 		ID:        user.ID,
 		Username:  user.Username,
 		Email:     user.Email,
 		Role:      user.Role,
+		Status:    user.Status,
 		CreatedAt: user.CreatedAt,
 	}
 
@@ -84,7 +142,7 @@ func (s *UserService) Login(ctx context.Context, req *request.UserLoginRequest)
 		return nil, err
 	}
 
-	if user.Status == "disabled" {
+	if user.Status == model.UserStatusDisabled {
 		return nil, common.ErrUserDisabled
 	}
 
@@ -94,10 +152,19 @@ func (s *UserService) Login(ctx context.Context, req *request.UserLoginRequest)
 		return nil, common.ErrInvalidAuth
 	}
 
+	pending := user.Status == model.UserStatusPendingVerification
+	if pending && !s.verification.AllowLogin {
+		return nil, common.ErrEmailNotVerified
+	}
+
+	// 每次登录对应一个新会话（设备）
+	sessionID := uuid.NewString()
 	accessToken, refreshToken, tokenID, err := utils.GenerateTokenPair(
 		user.ID,
 		user.Username,
 		user.Role,
+		sessionID,
+		pending,
 	)
 	if err != nil {
 		return nil, common.ErrInternalServer
@@ -108,6 +175,10 @@ func (s *UserService) Login(ctx context.Context, req *request.UserLoginRequest)
 		return nil, err
 	}
 
+	if err := s.sessionService.Start(ctx, user.ID, sessionID, tokenID); err != nil {
+		return nil, err
+	}
+
 	userResponse := response.UserResponse{
 		ID:       user.ID,
 		Username: user.Username,
@@ -134,9 +205,23 @@ func (s *UserService) RefreshToken(ctx context.Context, req *request.UserRefresh
 		return nil, common.ErrInvalidToken
 	}
 
-	// 从 Redis 验证 Token 是否存在
-	storedToken, err := repository.Rdb.GetRefreshToken(ctx, claims.UserID, claims.TokenID)
-	if err != nil || storedToken != req.RefreshToken {
+	// 从 Redis 取出并删除 Refresh Token，每个 Token 只能刷新一次
+	storedToken, err := repository.Rdb.ConsumeRefreshToken(ctx, claims.UserID, claims.TokenID)
+	if errors.Is(err, redis.Nil) {
+		// 已轮换的 Token 被再次使用，吊销整个会话
+		reused, err := s.sessionService.DetectReuse(ctx, claims.UserID, claims.TokenID)
+		if err != nil {
+			return nil, common.ErrInternalServer
+		}
+		if reused {
+			return nil, common.ErrRefreshTokenReused
+		}
+		return nil, common.ErrInvalidToken
+	}
+	if err != nil {
+		return nil, common.ErrInternalServer
+	}
+	if storedToken != req.RefreshToken {
 		return nil, common.ErrInvalidToken
 	}
 
@@ -158,23 +243,38 @@ func (s *UserService) RefreshToken(ctx context.Context, req *request.UserRefresh
 		return nil, err
 	}
 
-	// 生成新的 Token Pair
+	if user.Status == model.UserStatusDisabled {
+		return nil, common.ErrUserDisabled
+	}
+	pending := user.Status == model.UserStatusPendingVerification
+	if pending && !s.verification.AllowLogin {
+		return nil, common.ErrEmailNotVerified
+	}
+
+	// 沿用原会话；升级前签发的 Token 没有会话，刷新时补建
+	sessionID := claims.SessionID
+	if sessionID == "" {
+		sessionID = uuid.NewString()
+	}
+
+	// 生成新的 Token Pair，验证邮箱后刷新即可获得完整权限
 	newAccessToken, newRefreshToken, newTokenID, err := utils.GenerateTokenPair(
 		user.ID,
 		user.Username,
 		user.Role,
+		sessionID,
+		pending,
 	)
 	if err != nil {
 		return nil, common.ErrInternalServer
 	}
 
-	// 删除旧的 Refresh Token
-	if err := repository.Rdb.DeleteRefreshToken(ctx, claims.UserID, claims.TokenID); err != nil {
+	// 存储新的 Refresh Token
+	if err := repository.Rdb.StoreRefreshToken(ctx, user.ID, newTokenID, newRefreshToken); err != nil {
 		return nil, common.ErrInternalServer
 	}
 
-	// 存储新的 Refresh Token
-	if err := repository.Rdb.StoreRefreshToken(ctx, user.ID, newTokenID, newRefreshToken); err != nil {
+	if err := s.sessionService.Rotate(ctx, user.ID, sessionID, claims.TokenID, newTokenID); err != nil {
 		return nil, common.ErrInternalServer
 	}
 
@@ -196,16 +296,8 @@ func (s *UserService) RefreshToken(ctx context.Context, req *request.UserRefresh
 	return data, nil
 }
 
-func (s *UserService) Logout(ctx context.Context, userID uint64, tokenID string) error {
-	if err := repository.Rdb.DeleteRefreshToken(ctx, userID, tokenID); err != nil {
-		return err
-	}
-
-	if err := repository.Rdb.AddToBlacklist(ctx, tokenID, 24*time.Hour); err != nil {
-		return err
-	}
-
-	return nil
+func (s *UserService) Logout(ctx context.Context, userID uint64, sessionID, tokenID string) error {
+	return s.sessionService.End(ctx, userID, sessionID, tokenID)
 }
 
 func (s *UserService) GetUserMsg(ctx context.Context, userID uint64) (*response.GetUserMsgResponse, error) {
@@ -225,6 +317,7 @@ func (s *UserService) GetUserMsg(ctx context.Context, userID uint64) (*response.
 		Username:       user.Username,
 		Email:          user.Email,
 		Phone:          user.Phone,
+		CardNumber:     user.CardNumber,
 		Role:           user.Role,
 		Status:         user.Status,
 		BorrowLimit:    user.BorrowLimit,
@@ -243,34 +336,140 @@ func (s *UserService) UpdateUser(ctx context.Context, userID uint64, req *reques
 		return nil, common.ErrBadRequest
 	}
 
+	user, err := s.userRepo.GetUserByUserID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
 	updates := make(map[string]interface{})
+	pendingEmail := ""
 
-	if req.Email != nil {
-		updates["email"] = *req.Email
+	if req.Email != nil && *req.Email != user.Email {
+		if err := s.checkEmail(ctx, *req.Email, userID); err != nil {
+			return nil, err
+		}
+		if s.verification.Enabled {
+			// 新邮箱需验证归属后才生效，验证前仍使用原邮箱
+			if err := s.requestEmailChange(ctx, user, *req.Email); err != nil {
+				return nil, err
+			}
+			pendingEmail = *req.Email
+		} else {
+			updates["email"] = *req.Email
+		}
 	}
-	if req.Username != nil {
+	if req.Username != nil && *req.Username != user.Username {
+		if err := s.checkUsername(ctx, *req.Username, userID); err != nil {
+			return nil, err
+		}
 		updates["username"] = *req.Username
 	}
 	if req.Phone != nil {
 		updates["phone"] = *req.Phone
 	}
 
-	if err := s.userRepo.UpdateUserFields(ctx, s.userRepo.DB(), userID, updates); err != nil {
-		return nil, err
+	if len(updates) > 0 {
+		if err := s.userRepo.UpdateUserFields(ctx, s.userRepo.DB(), userID, updates); err != nil {
+			return nil, err
+		}
 	}
 
 	data := &response.UpdateUserResponse{
-		ID:        userID,
-		Username:  *req.Username,
-		Email:     *req.Email,
-		Phone:     *req.Phone,
-		UpdatedAt: time.Now().UTC().Format(time.RFC3339),
+		ID:           userID,
+		Username:     user.Username,
+		Email:        user.Email,
+		Phone:        user.Phone,
+		PendingEmail: pendingEmail,
+		UpdatedAt:    time.Now().UTC().Format(time.RFC3339),
+	}
+	if v, ok := updates["email"].(string); ok {
+		data.Email = v
+	}
+	if req.Username != nil {
+		data.Username = *req.Username
+	}
+	if req.Phone != nil {
+		data.Phone = *req.Phone
 	}
 
 	return data, nil
 }
 
-func (s *UserService) ChangePwd(ctx context.Context, userID uint64, tokenID string, req *request.ChangePwdRequest) error {
+// requestEmailChange 记录待验证的新邮箱，并向新邮箱发送验证链接
+func (s *UserService) requestEmailChange(ctx context.Context, user model.User, email string) error {
+	ok, err := repository.Rdb.AcquireOneTimeTokenCooldown(ctx, repository.TokenPurposeEmailChange, user.ID, emailChangeCooldown)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return common.ErrEmailChangeTooFrequent
+	}
+
+	token, err := utils.GenerateOpaqueToken()
+	if err != nil {
+		return err
+	}
+	if err := repository.Rdb.StorePendingEmail(ctx, user.ID, email, s.verification.TokenTTL); err != nil {
+		return err
+	}
+	if err := repository.Rdb.StoreOneTimeToken(ctx, repository.TokenPurposeEmailChange, user.ID, utils.HashOpaqueToken(token), s.verification.TokenTTL); err != nil {
+		return err
+	}
+
+	subject := "验证图书馆账号的新邮箱"
+	body := fmt.Sprintf("%s，您好：\n\n您正在将图书馆账号的邮箱修改为本邮箱，请在 %d 小时内打开以下链接完成验证：\n%s?token=%s\n\n验证前账号仍使用原邮箱。如果不是您本人操作，请忽略本邮件。",
+		user.Username, int(s.verification.TokenTTL.Hours()), s.verification.VerifyURL, token)
+
+	recipient := user
+	recipient.Email = email
+	s.sendMailAsync(recipient, subject, body)
+	return nil
+}
+
+// confirmEmailChange 新邮箱验证通过后替换账号邮箱，并通知原邮箱
+func (s *UserService) confirmEmailChange(ctx context.Context, userID uint64) error {
+	email, err := repository.Rdb.ConsumePendingEmail(ctx, userID)
+	if err != nil {
+		if errors.Is(err, redis.Nil) {
+			return common.ErrInvalidVerifyToken
+		}
+		return err
+	}
+
+	user, err := s.userRepo.GetUserByUserID(ctx, userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return common.ErrInvalidVerifyToken
+		}
+		return err
+	}
+	if user.Email == email {
+		return nil
+	}
+	// 验证期间新邮箱可能已被他人注册
+	if err := s.checkEmail(ctx, email, userID); err != nil {
+		return err
+	}
+
+	updates := map[string]interface{}{"email": email}
+	err = s.userRepo.DB().Transaction(func(tx *gorm.DB) error {
+		if err := s.userRepo.UpdateUserFields(ctx, tx, userID, updates); err != nil {
+			return err
+		}
+		return s.auditService.Record(ctx, tx, model.AuditActionUpdate, model.AuditEntityUser, userID, user, updates)
+	})
+	if err != nil {
+		return err
+	}
+
+	subject := "图书馆账号邮箱已修改"
+	body := fmt.Sprintf("%s，您好：\n\n您的图书馆账号邮箱已修改为 %s，之后的通知将发送到新邮箱。如果不是您本人操作，请立即联系图书馆。",
+		user.Username, email)
+	s.sendMailAsync(user, subject, body)
+	return nil
+}
+
+func (s *UserService) ChangePwd(ctx context.Context, userID uint64, sessionID, tokenID string, req *request.ChangePwdRequest) error {
 	user, err := s.userRepo.GetUserByUserID(ctx, userID)
 	if err != nil {
 		return err
@@ -293,17 +492,211 @@ func (s *UserService) ChangePwd(ctx context.Context, userID uint64, tokenID stri
 		return err
 	}
 
-	if err := repository.Rdb.DeleteRefreshToken(ctx, userID, tokenID); err != nil {
+	return s.sessionService.End(ctx, userID, sessionID, tokenID)
+}
+
+// ForgotPassword 向邮箱发送重置密码链接。邮箱不存在、账号被禁用或处于冷却期时同样返回成功，避免泄露账号是否存在
+func (s *UserService) ForgotPassword(ctx context.Context, req *request.ForgotPasswordRequest) error {
+	user, err := s.userRepo.GetUserByEmail(ctx, req.Email)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil
+		}
+		return err
+	}
+	if user.Status == model.UserStatusDisabled {
+		return nil
+	}
+
+	ok, err := repository.Rdb.AcquireOneTimeTokenCooldown(ctx, repository.TokenPurposePasswordReset, user.ID, passwordResetCooldown)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return nil
+	}
+
+	token, err := utils.GenerateOpaqueToken()
+	if err != nil {
+		return err
+	}
+	if err := repository.Rdb.StoreOneTimeToken(ctx, repository.TokenPurposePasswordReset, user.ID, utils.HashOpaqueToken(token), s.resetTokenTTL); err != nil {
+		return err
+	}
+
+	subject := "重置图书馆账号密码"
+	body := fmt.Sprintf("%s，您好：\n\n我们收到了重置密码的申请，请在 %d 分钟内打开以下链接设置新密码：\n%s?token=%s\n\n链接仅能使用一次。如果不是您本人操作，请忽略本邮件。",
+		user.Username, int(s.resetTokenTTL.Minutes()), s.resetURL, token)
+
+	s.sendMailAsync(user, subject, body)
+	return nil
+}
+
+// ResetPassword 使用一次性令牌设置新密码，并使该用户所有 Refresh Token 失效
+func (s *UserService) ResetPassword(ctx context.Context, req *request.ResetPasswordRequest) error {
+	userID, err := repository.Rdb.ConsumeOneTimeToken(ctx, repository.TokenPurposePasswordReset, utils.HashOpaqueToken(req.Token))
+	if err != nil {
+		if errors.Is(err, redis.Nil) {
+			return common.ErrInvalidResetToken
+		}
+		return err
+	}
+
+	if _, err := s.userRepo.GetUserByUserID(ctx, userID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return common.ErrInvalidResetToken
+		}
+		return err
+	}
+
+	hashed, err := utils.HashPassword(req.NewPassword)
+	if err != nil {
+		return err
+	}
+	if err := s.userRepo.UpdateUserFields(ctx, s.userRepo.DB(), userID, map[string]interface{}{"password": hashed}); err != nil {
+		return err
+	}
+
+	// 密码已重置，所有设备需重新登录
+	return s.sessionService.RevokeAll(ctx, userID)
+}
+
+// ResendVerification 重新发送验证邮件，与找回密码一样不泄露邮箱是否注册
+func (s *UserService) ResendVerification(ctx context.Context, req *request.ResendVerificationRequest) error {
+	user, err := s.userRepo.GetUserByEmail(ctx, req.Email)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil
+		}
+		return err
+	}
+	if user.Status != model.UserStatusPendingVerification {
+		return nil
+	}
+
+	ok, err := repository.Rdb.AcquireOneTimeTokenCooldown(ctx, repository.TokenPurposeEmailVerify, user.ID, emailVerifyCooldown)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return nil
+	}
+
+	return s.sendVerificationMail(ctx, user)
+}
+
+// VerifyEmail 使用验证令牌激活账号，已被禁用的账号保持禁用；修改邮箱的验证链接共用此接口
+func (s *UserService) VerifyEmail(ctx context.Context, req *request.VerifyEmailRequest) error {
+	tokenHash := utils.HashOpaqueToken(req.Token)
+	userID, err := repository.Rdb.ConsumeOneTimeToken(ctx, repository.TokenPurposeEmailVerify, tokenHash)
+	if errors.Is(err, redis.Nil) {
+		userID, err = repository.Rdb.ConsumeOneTimeToken(ctx, repository.TokenPurposeEmailChange, tokenHash)
+		if err == nil {
+			return s.confirmEmailChange(ctx, userID)
+		}
+	}
+	if err != nil {
+		if errors.Is(err, redis.Nil) {
+			return common.ErrInvalidVerifyToken
+		}
 		return err
 	}
 
-	if err := repository.Rdb.AddToBlacklist(ctx, tokenID, 24*time.Hour); err != nil {
+	user, err := s.userRepo.GetUserByUserID(ctx, userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return common.ErrInvalidVerifyToken
+		}
+		return err
+	}
+	if user.Status != model.UserStatusPendingVerification {
+		return nil
+	}
+
+	updates := map[string]interface{}{"status": model.UserStatusActive}
+	return s.userRepo.DB().Transaction(func(tx *gorm.DB) error {
+		if err := s.userRepo.UpdateUserFields(ctx, tx, userID, updates); err != nil {
+			return err
+		}
+		return s.auditService.Record(ctx, tx, model.AuditActionUpdate, model.AuditEntityUser, userID, user, updates)
+	})
+}
+
+// PurgeUnverifiedUsers 彻底删除注册超过验证链接有效期仍未验证的账号，释放其占用的用户名和邮箱；
+// 重新发送过验证邮件、链接仍有效的账号暂不删除，单个账号失败只记录日志
+func (s *UserService) PurgeUnverifiedUsers(ctx context.Context) (int, error) {
+	before := time.Now().Add(-s.verification.TokenTTL)
+	users, err := s.userRepo.GetUsersByStatusCreatedBefore(ctx, model.UserStatusPendingVerification, before)
+	if err != nil {
+		return 0, err
+	}
+
+	count := 0
+	for _, user := range users {
+		pending, err := repository.Rdb.HasOneTimeToken(ctx, repository.TokenPurposeEmailVerify, user.ID)
+		if err != nil {
+			log.Printf("检查用户 %d 的验证令牌失败: %v", user.ID, err)
+			continue
+		}
+		if pending {
+			continue
+		}
+
+		purged := false
+		err = s.userRepo.DB().Transaction(func(tx *gorm.DB) error {
+			n, err := s.userRepo.PurgeUserByStatus(ctx, tx, user.ID, model.UserStatusPendingVerification)
+			if err != nil || n == 0 {
+				return err // n 为 0 说明期间已完成验证
+			}
+			purged = true
+			return s.auditService.Record(ctx, tx, model.AuditActionDelete, model.AuditEntityUser, user.ID, user, nil)
+		})
+		if err != nil {
+			log.Printf("清理未验证用户 %d 失败: %v", user.ID, err)
+			continue
+		}
+		if !purged {
+			continue
+		}
+		count++
+
+		if err := s.sessionService.RevokeAll(ctx, user.ID); err != nil {
+			log.Printf("吊销未验证用户 %d 的会话失败: %v", user.ID, err)
+		}
+	}
+
+	return count, nil
+}
+
+// sendVerificationMail 签发验证令牌并发送验证邮件
+func (s *UserService) sendVerificationMail(ctx context.Context, user model.User) error {
+	token, err := utils.GenerateOpaqueToken()
+	if err != nil {
 		return err
 	}
+	if err := repository.Rdb.StoreOneTimeToken(ctx, repository.TokenPurposeEmailVerify, user.ID, utils.HashOpaqueToken(token), s.verification.TokenTTL); err != nil {
+		return err
+	}
+
+	subject := "验证图书馆账号邮箱"
+	body := fmt.Sprintf("%s，您好：\n\n感谢注册，请在 %d 小时内打开以下链接验证邮箱：\n%s?token=%s\n\n验证后即可借阅、预约图书。如果不是您本人注册，请忽略本邮件。",
+		user.Username, int(s.verification.TokenTTL.Hours()), s.verification.VerifyURL, token)
 
+	s.sendMailAsync(user, subject, body)
 	return nil
 }
 
+// sendMailAsync 异步发送账号邮件，响应时间不因账号是否存在而不同，发送失败只记录日志
+func (s *UserService) sendMailAsync(user model.User, subject, body string) {
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), accountMailTimeout)
+		defer cancel()
+		if err := s.mailSender.SendMail(ctx, user.Email, subject, body); err != nil {
+			log.Printf("发送邮件「%s」失败 user=%d: %v", subject, user.ID, err)
+		}
+	}()
+}
+
 func (s *UserService) GetUserList(ctx context.Context, req *request.GetUserListRequest) (*response.GetUserListResponse, error) {
 	// 参数校验
 	if req.Page <= 0 {
@@ -312,10 +705,12 @@ func (s *UserService) GetUserList(ctx context.Context, req *request.GetUserListR
 	if req.Limit <= 0 || req.Limit > 100 {
 		req.Limit = 10
 	}
-	if req.Role != "" && req.Role != "admin" && req.Role != "user" {
-		return nil, common.ErrNotFound
+	if req.Role != "" {
+		if err := s.checkRole(ctx, req.Role); err != nil {
+			return nil, err
+		}
 	}
-	if req.Status != "" && req.Status != "active" && req.Status != "disabled" {
+	if req.Status != "" && req.Status != model.UserStatusActive && req.Status != model.UserStatusDisabled && req.Status != model.UserStatusPendingVerification {
 		return nil, common.ErrNotFound
 	}
 
@@ -331,6 +726,7 @@ func (s *UserService) GetUserList(ctx context.Context, req *request.GetUserListR
 			Username:       u.Username,
 			Email:          u.Email,
 			Phone:          u.Phone,
+			CardNumber:     u.CardNumber,
 			Role:           u.Role,
 			Status:         u.Status,
 			BorrowLimit:    u.BorrowLimit,
@@ -358,19 +754,30 @@ func (s *UserService) CreateUser(ctx context.Context, req *request.CreateUserReq
 		Password: req.Password,
 		Email: req.Email,
 		Phone: req.Phone,
+		CardNumber: req.CardNumber,
 		Role: req.Role,
 		BorrowLimit: req.BorrowLimit,
 	}
 
-	// 判断是否存在相同用户名
-	if _, err := s.userRepo.GetUserByUsername(ctx, user.Username); err == nil {
-		return nil, common.ErrUsernameExist
-	} else if !errors.Is(err, gorm.ErrRecordNotFound) {
-		return nil, err
+	if user.Role != "" {
+		if err := s.checkRole(ctx, user.Role); err != nil {
+			return nil, err
+		}
+	}
+	if user.CardNumber != nil {
+		if err := s.checkCardNumber(ctx, *user.CardNumber, 0); err != nil {
+			return nil, err
+		}
 	}
 
-	// 判断是否存在相同邮箱
-	if _, err := s.userRepo.GetUserByUsername(ctx, user.Username); err == nil {
+	// 判断用户名、邮箱是否已被占用，属于回收站中的用户时提示恢复
+	if existing, err := s.userRepo.GetUserByUsernameOrEmailUnscoped(ctx, user.Username, user.Email); err == nil {
+		if existing.DeletedAt.Valid {
+			return nil, common.ErrUserInTrash
+		}
+		if existing.Username == user.Username {
+			return nil, common.ErrUsernameExist
+		}
 		return nil, common.ErrEmailExist
 	} else if !errors.Is(err, gorm.ErrRecordNotFound) {
 		return nil, err
@@ -383,8 +790,13 @@ func (s *UserService) CreateUser(ctx context.Context, req *request.CreateUserReq
 	}
 	user.Password = hashedPwd	
 
-	// 调用数据库函数
-	err = s.userRepo.CreateUser(ctx, &user)
+	// 创建用户与审计记录在同一事务中提交
+	err = s.userRepo.DB().Transaction(func(tx *gorm.DB) error {
+		if err := s.userRepo.CreateUser(ctx, tx, &user); err != nil {
+			return err
+		}
+		return s.auditService.Record(ctx, tx, model.AuditActionCreate, model.AuditEntityUser, user.ID, nil, user)
+	})
 	if err != nil {
 		return nil, err
 	}
@@ -394,6 +806,7 @@ func (s *UserService) CreateUser(ctx context.Context, req *request.CreateUserReq
 		ID:          user.ID,
 		Username:    user.Username,
 		Email:       user.Email,
+		CardNumber:  user.CardNumber,
 		Role:        user.Role,
 		Status:      user.Status,
 		BorrowLimit: user.BorrowLimit,
@@ -403,10 +816,12 @@ func (s *UserService) CreateUser(ctx context.Context, req *request.CreateUserReq
 	return data, nil
 }
 
-func (s *UserService) UpdateUserByAdmin(ctx context.Context, id uint64, req *request.UpdateUserByAdminRequest) (*response.UpdateUserByAdminResponse, error) {
+// UpdateUserByAdmin 管理员修改用户，actorRole 为操作人的角色
+func (s *UserService) UpdateUserByAdmin(ctx context.Context, actorRole string, id uint64, req *request.UpdateUserByAdminRequest) (*response.UpdateUserByAdminResponse, error) {
 	updates := make(map[string]interface{})
 
 	if req.Email == nil &&
+		req.CardNumber == nil &&
 		req.Role == nil &&
 		req.Status == nil &&
 		req.BorrowLimit == nil &&
@@ -416,48 +831,121 @@ func (s *UserService) UpdateUserByAdmin(ctx context.Context, id uint64, req *req
 	}
 
 	if req.Email != nil {
+		if err := s.checkEmail(ctx, *req.Email, id); err != nil {
+			return nil, err
+		}
 		updates["email"] = *req.Email
 	}
 	if req.Username != nil {
+		if err := s.checkUsername(ctx, *req.Username, id); err != nil {
+			return nil, err
+		}
 		updates["username"] = *req.Username
 	}
 	if req.Phone != nil {
 		updates["phone"] = *req.Phone
 	}
+	if req.CardNumber != nil {
+		if err := s.checkCardNumber(ctx, *req.CardNumber, id); err != nil {
+			return nil, err
+		}
+		updates["card_number"] = *req.CardNumber
+	}
 	if req.BorrowLimit != nil {
 		updates["borrow_limit"] = *req.BorrowLimit
 	}
 	if req.Role != nil {
+		if err := s.checkRole(ctx, *req.Role); err != nil {
+			return nil, err
+		}
 		updates["role"] = *req.Role
 	}
 	if req.Status != nil {
 		updates["status"] = *req.Status
 	}
 
-	if err := s.userRepo.UpdateUserFields(ctx, s.userRepo.DB(), id, updates); err != nil {
+	user, err := s.userRepo.GetUserByUserID(ctx, id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, common.ErrNotFound
+		}
 		return nil, err
 	}
 
+	// 管理员账号只能由管理员修改（否则可改邮箱后重置密码接管账号），授予管理员角色同样只能由管理员操作
+	if (user.Role == model.RoleAdmin || (req.Role != nil && *req.Role == model.RoleAdmin)) && actorRole != model.RoleAdmin {
+		return nil, common.ErrPermissionDenied
+	}
+
+	err = s.userRepo.DB().Transaction(func(tx *gorm.DB) error {
+		if err := s.userRepo.UpdateUserFields(ctx, tx, id, updates); err != nil {
+			return err
+		}
+		return s.auditService.Record(ctx, tx, model.AuditActionUpdate, model.AuditEntityUser, id, user, updates)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// 禁用用户或变更角色时立即吊销其所有会话，Token 中的旧角色随之失效
+	disabled := req.Status != nil && *req.Status == model.UserStatusDisabled
+	roleChanged := req.Role != nil && *req.Role != user.Role
+	if disabled || roleChanged {
+		if err := s.sessionService.RevokeAll(ctx, id); err != nil {
+			log.Printf("吊销用户 %d 的会话失败: %v", id, err)
+		}
+	}
+
+	// 请求只包含修改的字段，其余取自修改前的用户
+	updated := user
+	if req.Username != nil {
+		updated.Username = *req.Username
+	}
+	if req.Email != nil {
+		updated.Email = *req.Email
+	}
+	if req.Phone != nil {
+		updated.Phone = *req.Phone
+	}
+	if req.CardNumber != nil {
+		updated.CardNumber = req.CardNumber
+	}
+	if req.Role != nil {
+		updated.Role = *req.Role
+	}
+	if req.Status != nil {
+		updated.Status = *req.Status
+	}
+	if req.BorrowLimit != nil {
+		updated.BorrowLimit = *req.BorrowLimit
+	}
+
 	res := &response.UpdateUserByAdminResponse{
 		ID:          id,
-		Username:    *req.Username,
-		Email:       *req.Email,
-		Phone:       *req.Phone,
-		Role:        *req.Role,
-		Status:      *req.Status,
-		BorrowLimit: *req.BorrowLimit,
+		Username:    updated.Username,
+		Email:       updated.Email,
+		Phone:       updated.Phone,
+		CardNumber:  updated.CardNumber,
+		Role:        updated.Role,
+		Status:      updated.Status,
+		BorrowLimit: updated.BorrowLimit,
 		UpdatedAt:   time.Now().UTC().Format(time.RFC3339),
 	}
 
 	return res, nil
 }
 
-func (s *UserService) DeleteUser(ctx context.Context, id uint64) error {
+// DeleteUser 删除用户，actorRole 为操作人的角色，管理员账号只能由管理员删除
+func (s *UserService) DeleteUser(ctx context.Context, actorRole string, id uint64) error {
 	user, err := s.userRepo.GetUserByUserID(ctx, id)
 	if err != nil {
 		return err
 	}
 
+	if user.Role == model.RoleAdmin && actorRole != model.RoleAdmin {
+		return common.ErrPermissionDenied
+	}
+
 	if user.BorrowingCount > 0 {
 		bizErr := common.NewBizError(400, "无法删除该用户", 400)
 		details := make(map[string]interface{})
@@ -467,10 +955,140 @@ func (s *UserService) DeleteUser(ctx context.Context, id uint64) error {
 		return bizErr
 	}
 
-	err = s.userRepo.DeleteUserByID(ctx, id)
+	hasReservation, err := s.userRepo.HasActiveReservations(ctx, id)
+	if err != nil {
+		return err
+	}
+	if hasReservation {
+		return common.ErrUserHasReservations
+	}
+
+	// 软删除，借阅、预约等历史记录仍可关联到该用户
+	err = s.userRepo.DB().Transaction(func(tx *gorm.DB) error {
+		if err := s.userRepo.DeleteUserByID(ctx, tx, id); err != nil {
+			return err
+		}
+		return s.auditService.Record(ctx, tx, model.AuditActionDelete, model.AuditEntityUser, id, user, nil)
+	})
 	if err != nil {
 		return err
 	}
 
+	// 已删除的用户立即登出所有设备
+	if err := s.sessionService.RevokeAll(ctx, id); err != nil {
+		log.Printf("吊销用户 %d 的会话失败: %v", id, err)
+	}
+
 	return nil
-}
\ No newline at end of file
+}
+
+// GetUserTrash 分页查询回收站中的用户
+func (s *UserService) GetUserTrash(ctx context.Context, req *request.GetUserTrashRequest) (*response.GetUserTrashResponse, error) {
+	if req.Page <= 0 {
+		req.Page = 1
+	}
+	if req.Limit <= 0 || req.Limit > 100 {
+		req.Limit = 10
+	}
+
+	users, count, err := s.userRepo.GetTrashedUsers(ctx, req.Page, req.Limit)
+	if err != nil {
+		return nil, err
+	}
+
+	list := make([]response.TrashedUserItem, 0, len(users))
+	for _, u := range users {
+		list = append(list, response.TrashedUserItem{
+			ID:        u.ID,
+			Username:  u.Username,
+			Email:     u.Email,
+			Role:      u.Role,
+			Status:    u.Status,
+			CreatedAt: u.CreatedAt.UTC().Format(time.RFC3339),
+			DeletedAt: u.DeletedAt.Time.UTC().Format(time.RFC3339),
+		})
+	}
+
+	return &response.GetUserTrashResponse{
+		Total:      int(count),
+		Page:       req.Page,
+		Limit:      req.Limit,
+		TotalPages: int(math.Ceil(float64(count) / float64(req.Limit))),
+		Users:      list,
+	}, nil
+}
+
+// RestoreUser 将用户移出回收站
+func (s *UserService) RestoreUser(ctx context.Context, id uint64) error {
+	user, err := s.userRepo.GetTrashedUserByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return common.ErrTrashedUserNotFound
+		}
+		return err
+	}
+
+	return s.userRepo.DB().Transaction(func(tx *gorm.DB) error {
+		if err := s.userRepo.RestoreUser(ctx, tx, id); err != nil {
+			return err
+		}
+		return s.auditService.Record(ctx, tx, model.AuditActionRestore, model.AuditEntityUser, id,
+			map[string]interface{}{"deleted_at": user.DeletedAt.Time}, map[string]interface{}{"deleted_at": nil})
+	})
+}
+
+// checkRole 校验角色是否存在
+func (s *UserService) checkRole(ctx context.Context, role string) error {
+	if _, err := s.roleRepo.GetRoleByName(ctx, role); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return common.ErrRoleNotFound
+		}
+		return err
+	}
+	return nil
+}
+
+// checkUsername 检查用户名是否已被其他用户（包括回收站中的用户）占用
+func (s *UserService) checkUsername(ctx context.Context, username string, selfID uint64) error {
+	existing, err := s.userRepo.GetUserByUsernameUnscoped(ctx, username)
+	if err == nil {
+		if existing.ID != selfID {
+			return common.ErrUsernameExist
+		}
+		return nil
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return err
+	}
+	return nil
+}
+
+// checkEmail 检查邮箱是否已被其他用户（包括回收站中的用户）占用
+func (s *UserService) checkEmail(ctx context.Context, email string, selfID uint64) error {
+	existing, err := s.userRepo.GetUserByEmailUnscoped(ctx, email)
+	if err == nil {
+		if existing.ID != selfID {
+			return common.ErrEmailExist
+		}
+		return nil
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return err
+	}
+	return nil
+}
+
+// checkCardNumber 校验借书证号未被其他用户（含回收站中的用户）占用，selfID 为当前修改的用户
+func (s *UserService) checkCardNumber(ctx context.Context, cardNumber string, selfID uint64) error {
+	existing, err := s.userRepo.GetUserByCardNumberUnscoped(ctx, cardNumber)
+	if err == nil {
+		if existing.ID != selfID {
+			return common.ErrCardNumberExist
+		}
+		return nil
+	}
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil
+	}
+	return err
+}
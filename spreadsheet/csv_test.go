@@ -0,0 +1,92 @@
+package spreadsheet
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestReadCSV(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want [][]string
+	}{
+		{
+			name: "plain",
+			in:   "isbn,title\n9780306406157,三体\n",
+			want: [][]string{{"isbn", "title"}, {"9780306406157", "三体"}},
+		},
+		{
+			name: "bom stripped",
+			in:   "\xef\xbb\xbfisbn,title\n1,a\n",
+			want: [][]string{{"isbn", "title"}, {"1", "a"}},
+		},
+		{
+			name: "quoted fields and leading spaces",
+			in:   "title, author\n\"Hello, World\", \"Doe, J\"\n",
+			want: [][]string{{"title", "author"}, {"Hello, World", "Doe, J"}},
+		},
+		{
+			name: "blank lines keep line numbers",
+			in:   "isbn\n\n\n1\n",
+			want: [][]string{{"isbn"}, nil, nil, {"1"}},
+		},
+		{
+			name: "ragged rows",
+			in:   "a,b,c\n1\n",
+			want: [][]string{{"a", "b", "c"}, {"1"}},
+		},
+		{
+			name: "crlf",
+			in:   "a,b\r\n1,2\r\n",
+			want: [][]string{{"a", "b"}, {"1", "2"}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ReadCSV(strings.NewReader(tt.in))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("ReadCSV = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestReadCSVInvalid(t *testing.T) {
+	if _, err := ReadCSV(strings.NewReader("a,b\n\"unterminated,1\n")); !errors.Is(err, ErrInvalidFile) {
+		t.Fatalf("err = %v, want ErrInvalidFile", err)
+	}
+}
+
+func TestWriteCSVRoundTrip(t *testing.T) {
+	rows := [][]string{{"isbn", "title"}, {"9780306406157", "Hello, \"World\""}}
+	data, err := WriteCSV(rows)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(string(data), "\xef\xbb\xbf") {
+		t.Fatal("missing BOM")
+	}
+	got, err := ReadCSV(strings.NewReader(string(data)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, rows) {
+		t.Fatalf("round trip = %q, want %q", got, rows)
+	}
+}
+
+func TestReadByExtension(t *testing.T) {
+	r := strings.NewReader("a,b\n")
+	if rows, err := Read("Books.CSV", r, r.Size()); err != nil || len(rows) != 1 {
+		t.Fatalf("Read csv = %v, %v", rows, err)
+	}
+	if _, err := Read("books.xls", r, r.Size()); !errors.Is(err, ErrUnsupportedFormat) {
+		t.Fatalf("err = %v, want ErrUnsupportedFormat", err)
+	}
+}
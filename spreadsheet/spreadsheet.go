@@ -0,0 +1,24 @@
+package spreadsheet
+
+import (
+	"errors"
+	"io"
+	"path/filepath"
+	"strings"
+)
+
+var (
+	ErrUnsupportedFormat = errors.New("unsupported spreadsheet format")
+	ErrInvalidFile       = errors.New("invalid spreadsheet file")
+)
+
+// Read 按文件扩展名解析 CSV 或 XLSX，返回全部行（含表头），每行按列序排列
+func Read(filename string, r io.ReaderAt, size int64) ([][]string, error) {
+	switch strings.ToLower(filepath.Ext(filename)) {
+	case ".csv":
+		return ReadCSV(io.NewSectionReader(r, 0, size))
+	case ".xlsx":
+		return ReadXLSX(r, size)
+	}
+	return nil, ErrUnsupportedFormat
+}
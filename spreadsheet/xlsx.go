@@ -0,0 +1,221 @@
+package spreadsheet
+
+import (
+	"archive/zip"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"path"
+	"strconv"
+	"strings"
+)
+
+const (
+	// 单个 XML 部件的解压上限，防止压缩炸弹
+	maxXLSXPartSize = 64 << 20
+
+	// Excel 工作表的行列上限，行列号超出即为无效文件
+	maxXLSXRows    = 1 << 20 // 1048576
+	maxXLSXColumns = 1 << 14 // 16384，即 XFD 列
+
+	// 按行列号补齐后的单元格总数上限：行列号来自上传文件，几 KB 的文件即可声明极大的行列号
+	maxXLSXCells = 4 << 20
+)
+
+type xlsxWorkbook struct {
+	Sheets []struct {
+		Name string `xml:"name,attr"`
+		RID  string `xml:"http://schemas.openxmlformats.org/officeDocument/2006/relationships id,attr"`
+	} `xml:"sheets>sheet"`
+}
+
+type xlsxRelationships struct {
+	Relationships []struct {
+		ID     string `xml:"Id,attr"`
+		Target string `xml:"Target,attr"`
+	} `xml:"Relationship"`
+}
+
+type xlsxText struct {
+	T string `xml:"t"`
+	R []struct {
+		T string `xml:"t"`
+	} `xml:"r"`
+}
+
+// String 纯文本直接取 <t>，富文本拼接各个 <r><t>
+func (t xlsxText) String() string {
+	if len(t.R) == 0 {
+		return t.T
+	}
+	var b strings.Builder
+	for _, r := range t.R {
+		b.WriteString(r.T)
+	}
+	return b.String()
+}
+
+type xlsxSharedStrings struct {
+	Items []xlsxText `xml:"si"`
+}
+
+type xlsxSheet struct {
+	Rows []struct {
+		Index int `xml:"r,attr"`
+		Cells []struct {
+			Ref    string   `xml:"r,attr"`
+			Type   string   `xml:"t,attr"`
+			Value  string   `xml:"v"`
+			Inline xlsxText `xml:"is"`
+		} `xml:"c"`
+	} `xml:"sheetData>row"`
+}
+
+// ReadXLSX 读取工作簿中第一个工作表的单元格文本。
+// 只解析值，不处理样式，日期单元格返回 Excel 序列号
+func ReadXLSX(r io.ReaderAt, size int64) ([][]string, error) {
+	zr, err := zip.NewReader(r, size)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidFile, err)
+	}
+	files := make(map[string]*zip.File, len(zr.File))
+	for _, f := range zr.File {
+		files[f.Name] = f
+	}
+
+	var workbook xlsxWorkbook
+	if err := decodeXLSXPart(files, "xl/workbook.xml", &workbook); err != nil {
+		return nil, err
+	}
+	if len(workbook.Sheets) == 0 {
+		return nil, fmt.Errorf("%w: workbook has no sheets", ErrInvalidFile)
+	}
+
+	var rels xlsxRelationships
+	if err := decodeXLSXPart(files, "xl/_rels/workbook.xml.rels", &rels); err != nil {
+		return nil, err
+	}
+	sheetPath := ""
+	for _, rel := range rels.Relationships {
+		if rel.ID == workbook.Sheets[0].RID {
+			// Target 通常相对于 xl/，也可能是以 / 开头的包内绝对路径
+			if strings.HasPrefix(rel.Target, "/") {
+				sheetPath = strings.TrimPrefix(rel.Target, "/")
+			} else {
+				sheetPath = path.Join("xl", rel.Target)
+			}
+			break
+		}
+	}
+	if sheetPath == "" {
+		return nil, fmt.Errorf("%w: sheet relationship not found", ErrInvalidFile)
+	}
+
+	var shared xlsxSharedStrings
+	if _, ok := files["xl/sharedStrings.xml"]; ok {
+		if err := decodeXLSXPart(files, "xl/sharedStrings.xml", &shared); err != nil {
+			return nil, err
+		}
+	}
+
+	var sheet xlsxSheet
+	if err := decodeXLSXPart(files, sheetPath, &sheet); err != nil {
+		return nil, err
+	}
+
+	rows := make([][]string, 0, len(sheet.Rows))
+	cells := 0
+	for i, row := range sheet.Rows {
+		// 空行在 XML 中会被省略，按行号补齐以保证行号与表格一致
+		rowIndex := row.Index
+		if rowIndex == 0 {
+			rowIndex = i + 1
+		}
+		if rowIndex < 0 || rowIndex > maxXLSXRows {
+			return nil, fmt.Errorf("%w: row %d out of range", ErrInvalidFile, rowIndex)
+		}
+		if len(rows) < rowIndex-1 {
+			cells += rowIndex - 1 - len(rows)
+			if cells > maxXLSXCells {
+				return nil, fmt.Errorf("%w: too many cells", ErrInvalidFile)
+			}
+		}
+		for len(rows) < rowIndex-1 {
+			rows = append(rows, nil)
+		}
+
+		var values []string
+		for j, cell := range row.Cells {
+			col := j
+			if cell.Ref != "" {
+				col = columnIndex(cell.Ref)
+			}
+			if col < 0 || col >= maxXLSXColumns {
+				return nil, fmt.Errorf("%w: bad cell reference %q", ErrInvalidFile, cell.Ref)
+			}
+			if len(values) <= col {
+				cells += col + 1 - len(values)
+				if cells > maxXLSXCells {
+					return nil, fmt.Errorf("%w: too many cells", ErrInvalidFile)
+				}
+			}
+			for len(values) <= col {
+				values = append(values, "")
+			}
+
+			switch cell.Type {
+			case "s":
+				idx, err := strconv.Atoi(cell.Value)
+				if err != nil || idx < 0 || idx >= len(shared.Items) {
+					return nil, fmt.Errorf("%w: bad shared string index in %s", ErrInvalidFile, cell.Ref)
+				}
+				values[col] = shared.Items[idx].String()
+			case "inlineStr":
+				values[col] = cell.Inline.String()
+			case "b":
+				if cell.Value == "1" {
+					values[col] = "TRUE"
+				} else {
+					values[col] = "FALSE"
+				}
+			default:
+				values[col] = cell.Value
+			}
+		}
+		rows = append(rows, values)
+	}
+	return rows, nil
+}
+
+func decodeXLSXPart(files map[string]*zip.File, name string, v interface{}) error {
+	f, ok := files[name]
+	if !ok {
+		return fmt.Errorf("%w: missing %s", ErrInvalidFile, name)
+	}
+	rc, err := f.Open()
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidFile, err)
+	}
+	defer rc.Close()
+
+	if err := xml.NewDecoder(io.LimitReader(rc, maxXLSXPartSize)).Decode(v); err != nil {
+		return fmt.Errorf("%w: %s: %v", ErrInvalidFile, name, err)
+	}
+	return nil
+}
+
+// columnIndex 将单元格引用（如 AB12）的列字母转为从 0 开始的列号，
+// 没有列字母或超出 maxXLSXColumns 时返回 -1
+func columnIndex(ref string) int {
+	col := 0
+	for _, c := range ref {
+		if c < 'A' || c > 'Z' {
+			break
+		}
+		col = col*26 + int(c-'A'+1)
+		if col > maxXLSXColumns {
+			return -1
+		}
+	}
+	return col - 1
+}
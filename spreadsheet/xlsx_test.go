@@ -0,0 +1,178 @@
+package spreadsheet
+
+import (
+	"archive/zip"
+	"bytes"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+const (
+	testWorkbookXML = `<?xml version="1.0" encoding="UTF-8"?>
+<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships">
+<sheets><sheet name="Books" sheetId="1" r:id="rId1"/></sheets>
+</workbook>`
+	testRelsXML = `<?xml version="1.0" encoding="UTF-8"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">
+<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet1.xml"/>
+</Relationships>`
+	testSharedStringsXML = `<?xml version="1.0" encoding="UTF-8"?>
+<sst xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main">
+<si><t>isbn</t></si>
+<si><t>title</t></si>
+<si><r><t>三</t></r><r><t>体</t></r></si>
+</sst>`
+)
+
+// buildXLSX 生成只包含解析所需部件的最小工作簿
+func buildXLSX(t *testing.T, sheetData string, parts map[string]string) *bytes.Reader {
+	t.Helper()
+	files := map[string]string{
+		"xl/workbook.xml":            testWorkbookXML,
+		"xl/_rels/workbook.xml.rels": testRelsXML,
+		"xl/sharedStrings.xml":       testSharedStringsXML,
+		"xl/worksheets/sheet1.xml": `<?xml version="1.0" encoding="UTF-8"?>
+<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetData>` + sheetData + `</sheetData></worksheet>`,
+	}
+	for name, content := range parts {
+		if content == "" {
+			delete(files, name)
+		} else {
+			files[name] = content
+		}
+	}
+
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for name, content := range files {
+		w, err := zw.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		w.Write([]byte(content))
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return bytes.NewReader(buf.Bytes())
+}
+
+func TestReadXLSX(t *testing.T) {
+	r := buildXLSX(t, `
+<row r="1"><c r="A1" t="s"><v>0</v></c><c r="B1" t="s"><v>1</v></c></row>
+<row r="2"><c r="A2"><v>9780306406157</v></c><c r="B2" t="s"><v>2</v></c></row>
+<row r="4"><c r="B4" t="inlineStr"><is><t>inline</t></is></c><c r="D4" t="b"><v>1</v></c></row>`, nil)
+
+	got, err := ReadXLSX(r, r.Size())
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := [][]string{
+		{"isbn", "title"},
+		{"9780306406157", "三体"},
+		nil, // 省略的空行按行号补齐
+		{"", "inline", "", "TRUE"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("ReadXLSX = %q, want %q", got, want)
+	}
+}
+
+func TestReadXLSXWithoutRefs(t *testing.T) {
+	r := buildXLSX(t, `<row><c><v>a</v></c><c><v>b</v></c></row><row><c t="b"><v>0</v></c></row>`, map[string]string{
+		"xl/sharedStrings.xml": "", // 共享字符串表是可选的
+	})
+
+	got, err := ReadXLSX(r, r.Size())
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := [][]string{{"a", "b"}, {"FALSE"}}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("ReadXLSX = %q, want %q", got, want)
+	}
+}
+
+func TestReadXLSXAbsoluteTarget(t *testing.T) {
+	r := buildXLSX(t, `<row r="1"><c r="A1"><v>x</v></c></row>`, map[string]string{
+		"xl/_rels/workbook.xml.rels": `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">
+<Relationship Id="rId1" Target="/xl/worksheets/sheet1.xml"/></Relationships>`,
+	})
+	got, err := ReadXLSX(r, r.Size())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, [][]string{{"x"}}) {
+		t.Fatalf("ReadXLSX = %q", got)
+	}
+}
+
+func TestReadXLSXInvalid(t *testing.T) {
+	tests := []struct {
+		name      string
+		sheetData string
+		parts     map[string]string
+	}{
+		{"bad shared string index", `<row r="1"><c r="A1" t="s"><v>9</v></c></row>`, nil},
+		{"negative shared string index", `<row r="1"><c r="A1" t="s"><v>-1</v></c></row>`, nil},
+		{"missing workbook", ``, map[string]string{"xl/workbook.xml": ""}},
+		{"missing sheet", ``, map[string]string{"xl/worksheets/sheet1.xml": ""}},
+		{"no sheets", ``, map[string]string{"xl/workbook.xml": `<workbook><sheets></sheets></workbook>`}},
+		{"malformed xml", `<row r="1"><c r="A1"><v>x</v></row>`, nil},
+		// 行列号来自上传文件，超出 Excel 上限时应拒绝而不是按行列号补齐
+		{"row index too large", `<row r="2000000000"><c r="A2000000000"><v>x</v></c></row>`, nil},
+		{"negative row index", `<row r="-5"><c><v>x</v></c></row>`, nil},
+		{"column too large", `<row r="1"><c r="XFE1"><v>x</v></c></row>`, nil},
+		{"column overflow", `<row r="1"><c r="ZZZZZZZZZZZZZZZZZZZZ1"><v>x</v></c></row>`, nil},
+		{"ref without column", `<row r="1"><c r="1A"><v>x</v></c></row>`, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := buildXLSX(t, tt.sheetData, tt.parts)
+			if _, err := ReadXLSX(r, r.Size()); !errors.Is(err, ErrInvalidFile) {
+				t.Fatalf("err = %v, want ErrInvalidFile", err)
+			}
+		})
+	}
+}
+
+func TestReadXLSXNotZip(t *testing.T) {
+	r := bytes.NewReader([]byte("isbn,title\n"))
+	if _, err := ReadXLSX(r, r.Size()); !errors.Is(err, ErrInvalidFile) {
+		t.Fatalf("err = %v, want ErrInvalidFile", err)
+	}
+}
+
+// 单元格总数超出上限时在补齐前拒绝
+func TestReadXLSXCellBudget(t *testing.T) {
+	var sheet bytes.Buffer
+	for i := 1; i <= maxXLSXCells/maxXLSXColumns+1; i++ {
+		sheet.WriteString(`<row><c r="XFD1"><v>x</v></c></row>`)
+	}
+	r := buildXLSX(t, sheet.String(), nil)
+	if _, err := ReadXLSX(r, r.Size()); !errors.Is(err, ErrInvalidFile) {
+		t.Fatalf("err = %v, want ErrInvalidFile", err)
+	}
+}
+
+func TestColumnIndex(t *testing.T) {
+	tests := []struct {
+		ref  string
+		want int
+	}{
+		{"A1", 0},
+		{"Z9", 25},
+		{"AA10", 26},
+		{"AB12", 27},
+		{"XFD1", maxXLSXColumns - 1},
+		{"XFE1", -1},
+		{"1", -1},
+		{"ZZZZZZZZZZZZZZZZ1", -1},
+	}
+	for _, tt := range tests {
+		if got := columnIndex(tt.ref); got != tt.want {
+			t.Errorf("columnIndex(%q) = %d, want %d", tt.ref, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,106 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestValidKey(t *testing.T) {
+	tests := []struct {
+		key  string
+		want bool
+	}{
+		{"covers/12/abc-w150.jpg", true},
+		{"a.jpg", true},
+		{"covers/..hidden/a.jpg", true},
+		{"", false},
+		{"/etc/passwd", false},
+		{"../a.jpg", false},
+		{"covers/../../a.jpg", false},
+		{"covers/./a.jpg", false},
+		{"covers//a.jpg", false},
+		{"covers/", false},
+		{"covers\\..\\a.jpg", false},
+		{"..", false},
+	}
+	for _, tt := range tests {
+		if got := validKey(tt.key); got != tt.want {
+			t.Errorf("validKey(%q) = %v, want %v", tt.key, got, tt.want)
+		}
+	}
+}
+
+func TestLocalStorePutAndDelete(t *testing.T) {
+	root := t.TempDir()
+	s := NewLocalStore(root, "/static/")
+	ctx := context.Background()
+
+	if err := s.Put(ctx, "covers/12/a-w150.jpg", []byte("v1"), "image/jpeg"); err != nil {
+		t.Fatal(err)
+	}
+	// 已存在时覆盖
+	if err := s.Put(ctx, "covers/12/a-w150.jpg", []byte("v2"), "image/jpeg"); err != nil {
+		t.Fatal(err)
+	}
+
+	path := filepath.Join(root, "covers", "12", "a-w150.jpg")
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != "v2" {
+		t.Fatalf("content = %q, want v2", data)
+	}
+	// 不应残留临时文件
+	entries, _ := os.ReadDir(filepath.Dir(path))
+	if len(entries) != 1 {
+		t.Fatalf("directory has %d entries, want 1", len(entries))
+	}
+
+	if got := s.URL("covers/12/a-w150.jpg"); got != "/static/covers/12/a-w150.jpg" {
+		t.Errorf("URL = %s", got)
+	}
+
+	if err := s.Delete(ctx, "covers/12/a-w150.jpg"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Fatalf("file still exists: %v", err)
+	}
+	// 删除不存在的对象不报错
+	if err := s.Delete(ctx, "covers/12/a-w150.jpg"); err != nil {
+		t.Fatalf("Delete missing = %v", err)
+	}
+}
+
+func TestLocalStoreRejectsEscapingKeys(t *testing.T) {
+	base := t.TempDir()
+	root := filepath.Join(base, "static")
+	s := NewLocalStore(root, "/static")
+	ctx := context.Background()
+
+	outside := filepath.Join(base, "outside.txt")
+	if err := os.WriteFile(outside, []byte("keep"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, key := range []string{"../outside.txt", "covers/../../outside.txt", "/outside.txt", "..\\outside.txt", ""} {
+		if err := s.Put(ctx, key, []byte("overwritten"), ""); !errors.Is(err, ErrInvalidKey) {
+			t.Errorf("Put(%q) = %v, want ErrInvalidKey", key, err)
+		}
+		if err := s.Delete(ctx, key); !errors.Is(err, ErrInvalidKey) {
+			t.Errorf("Delete(%q) = %v, want ErrInvalidKey", key, err)
+		}
+	}
+
+	data, err := os.ReadFile(outside)
+	if err != nil || string(data) != "keep" {
+		t.Fatalf("file outside root changed: %q, %v", data, err)
+	}
+	if _, err := os.Stat(root); !os.IsNotExist(err) {
+		t.Fatalf("root should not have been created: %v", err)
+	}
+}
@@ -0,0 +1,8 @@
+package utils
+
+import "fmt"
+
+// GenerateBarcode 按 ISBN 与序号生成默认副本条码，如 9787111544937-001
+func GenerateBarcode(isbn string, seq int) string {
+	return fmt.Sprintf("%s-%03d", isbn, seq)
+}
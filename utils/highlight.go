@@ -0,0 +1,122 @@
+package utils
+
+import (
+	"html"
+	"strings"
+	"unicode"
+)
+
+const (
+	HighlightOpen  = "<em>"
+	HighlightClose = "</em>"
+)
+
+// SearchTerms 将检索词按空白和标点拆分为关键词
+func SearchTerms(q string) []string {
+	fields := strings.FieldsFunc(q, func(r rune) bool {
+		return unicode.IsSpace(r) || (unicode.IsPunct(r) && r != '-' && r != '\'')
+	})
+
+	seen := make(map[string]bool)
+	terms := make([]string, 0, len(fields))
+	for _, f := range fields {
+		key := strings.ToLower(f)
+		if !seen[key] {
+			seen[key] = true
+			terms = append(terms, f)
+		}
+	}
+	return terms
+}
+
+// Highlight 将文本中出现的关键词包裹在 <em> 中（忽略大小写），其余内容做 HTML 转义
+func Highlight(text string, terms []string) string {
+	runes := []rune(text)
+	marks := matchMarks(runes, terms)
+
+	var b strings.Builder
+	writeHighlighted(&b, runes, marks, 0, len(runes))
+	return b.String()
+}
+
+// Snippet 截取第一个关键词附近的片段并高亮，radius 为关键词前后保留的字符数；未命中时返回开头片段
+func Snippet(text string, terms []string, radius int) string {
+	runes := []rune(text)
+	marks := matchMarks(runes, terms)
+
+	first := -1
+	for i, m := range marks {
+		if m {
+			first = i
+			break
+		}
+	}
+
+	start, end := 0, len(runes)
+	if first >= 0 {
+		start = max(first-radius, 0)
+		end = min(first+radius*2, len(runes))
+	} else {
+		end = min(radius*2, len(runes))
+	}
+
+	var b strings.Builder
+	if start > 0 {
+		b.WriteString("…")
+	}
+	writeHighlighted(&b, runes, marks, start, end)
+	if end < len(runes) {
+		b.WriteString("…")
+	}
+	return b.String()
+}
+
+// matchMarks 标记文本中被任一关键词覆盖的字符
+func matchMarks(runes []rune, terms []string) []bool {
+	marks := make([]bool, len(runes))
+	lower := []rune(strings.ToLower(string(runes)))
+	// 个别字符转小写后长度会变化，此时放弃高亮以免错位
+	if len(lower) != len(runes) {
+		return marks
+	}
+
+	for _, term := range terms {
+		t := []rune(strings.ToLower(term))
+		if len(t) == 0 {
+			continue
+		}
+		for i := 0; i+len(t) <= len(lower); i++ {
+			if string(lower[i:i+len(t)]) == string(t) {
+				for j := i; j < i+len(t); j++ {
+					marks[j] = true
+				}
+			}
+		}
+	}
+	return marks
+}
+
+func writeHighlighted(b *strings.Builder, runes []rune, marks []bool, start, end int) {
+	inMark := false
+	segStart := start
+	flush := func(i int) {
+		b.WriteString(html.EscapeString(string(runes[segStart:i])))
+		segStart = i
+	}
+
+	for i := start; i < end; i++ {
+		if marks[i] != inMark {
+			flush(i)
+			if marks[i] {
+				b.WriteString(HighlightOpen)
+			} else {
+				b.WriteString(HighlightClose)
+			}
+			inMark = marks[i]
+		}
+	}
+	flush(end)
+	if inMark {
+		b.WriteString(HighlightClose)
+	}
+}
@@ -0,0 +1,73 @@
+package utils
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestNormalizeISBN(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want string
+		err  error
+	}{
+		{"isbn13", "9780306406157", "9780306406157", nil},
+		{"isbn13 with hyphens", "978-0-306-40615-7", "9780306406157", nil},
+		{"isbn13 with spaces", " 978 0 306 40615 7 ", "9780306406157", nil},
+		{"979 prefix", "9791090636071", "9791090636071", nil},
+		{"isbn10 to 13", "0306406152", "9780306406157", nil},
+		{"isbn10 with hyphens", "0-306-40615-2", "9780306406157", nil},
+		{"isbn10 check digit X", "080442957X", "9780804429573", nil},
+		{"isbn10 lowercase x", "080442957x", "9780804429573", nil},
+		{"isbn13 bad check digit", "9780306406158", "", ErrInvalidISBN},
+		{"isbn10 bad check digit", "0306406153", "", ErrInvalidISBN},
+		{"isbn10 X not last", "03064X6152", "", ErrInvalidISBN},
+		{"isbn13 with X", "978030640615X", "", ErrInvalidISBN},
+		{"letters", "97803064o6157", "", ErrInvalidISBN},
+		{"too short", "030640615", "", ErrInvalidISBN},
+		{"too long", "97803064061570", "", ErrInvalidISBN},
+		{"empty", "", "", ErrInvalidISBN},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := NormalizeISBN(tt.in)
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("NormalizeISBN(%q) error = %v, want %v", tt.in, err, tt.err)
+			}
+			if got != tt.want {
+				t.Fatalf("NormalizeISBN(%q) = %q, want %q", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestISBN13To10(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{"9780306406157", "0306406152"},
+		{"9780804429573", "080442957X"},
+		{"9791090636071", ""}, // 979 前缀没有 ISBN-10
+		{"978030640615", ""},
+	}
+	for _, tt := range tests {
+		if got := ISBN13To10(tt.in); got != tt.want {
+			t.Errorf("ISBN13To10(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+// ISBN-10 转换为 ISBN-13 后再转回应得到原值
+func TestISBN10RoundTrip(t *testing.T) {
+	for _, isbn10 := range []string{"0306406152", "080442957X", "0131103628", "0596520689"} {
+		isbn13, err := NormalizeISBN(isbn10)
+		if err != nil {
+			t.Fatalf("NormalizeISBN(%q): %v", isbn10, err)
+		}
+		if got := ISBN13To10(isbn13); got != isbn10 {
+			t.Errorf("ISBN13To10(%q) = %q, want %q", isbn13, got, isbn10)
+		}
+	}
+}
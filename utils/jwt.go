@@ -1,27 +1,22 @@
 package utils
 
 import (
-    "log"
-    "os"
     "time"
     "errors"
     "github.com/google/uuid"
     "github.com/golang-jwt/jwt/v5"
 )
 
-var accessTokenSecret []byte
+// Access Token 使用非对称密钥签名（见 jwt_keys.go），其他服务可通过 JWKS 公钥自行验证；
+// Refresh Token 只由本服务验证，仍使用 HMAC 密钥
 var refreshTokenSecret []byte
 
-func init() {
-    accessTokenSecret := os.Getenv("JWT_ACCESS_SECRET")
-    if accessTokenSecret == "" {
-        log.Fatal("JWT_ACCESS_SECRET 环境变量未设置")
-    }
+// AccessTokenTTL Access Token 有效期
+const AccessTokenTTL = 24 * time.Hour
 
-    refreshTokenSecre := os.Getenv("JWT_REFRESH_SECRET")
-    if refreshTokenSecre == "" {
-        log.Fatal("JWT_REFRESH_SECRET 环境变量未设置")
-    }
+// SetRefreshTokenSecret 设置签发和验证 Refresh Token 使用的密钥，启动时调用
+func SetRefreshTokenSecret(secret string) {
+    refreshTokenSecret = []byte(secret)
 }
 
 // AccessTokenClaims Access Token 的 Claims
@@ -30,6 +25,8 @@ type AccessTokenClaims struct {
 	Username string `json:"username"`
 	Role     string `json:"role"`
 	TokenID  string `json:"token_id"` // 用于黑名单
+	SessionID string `json:"sid"`     // 所属登录会话
+	Pending  bool   `json:"pending,omitempty"` // 邮箱尚未验证，只能浏览
 	jwt.RegisteredClaims
 }
 
@@ -37,12 +34,13 @@ type AccessTokenClaims struct {
 type RefreshTokenClaims struct {
 	UserID  uint64 `json:"user_id"`
 	TokenID string `json:"token_id"` // 唯一标识
+	SessionID string `json:"sid"`    // 所属登录会话（Token 家族），刷新后保持不变
 	jwt. RegisteredClaims
 }
 // ========== Token 生成 ==========
 
 // GenerateTokenPair 生成 Access Token 和 Refresh Token
-func GenerateTokenPair(userID uint64, username, role string) (accessToken, refreshToken, tokenID string, err error) {
+func GenerateTokenPair(userID uint64, username, role, sessionID string, pending bool) (accessToken, refreshToken, tokenID string, err error) {
 	// 生成唯一的 TokenID
 	tokenID = generateTokenID()
 	if err != nil {
@@ -50,13 +48,13 @@ func GenerateTokenPair(userID uint64, username, role string) (accessToken, refre
 	}
 
 	// 生成 Access Token
-	accessToken, err = GenerateAccessToken(userID, username, role, tokenID)
+	accessToken, err = GenerateAccessToken(userID, username, role, tokenID, sessionID, pending)
 	if err != nil {
 		return "", "", "", err
 	}
 
 	// 生成 Refresh Token
-	refreshToken, err = GenerateRefreshToken(userID, tokenID)
+	refreshToken, err = GenerateRefreshToken(userID, tokenID, sessionID)
 	if err != nil {
 		return "", "", "", err
 	}
@@ -65,30 +63,38 @@ func GenerateTokenPair(userID uint64, username, role string) (accessToken, refre
 }
 
 // GenerateAccessToken 生成 Access Token
-func GenerateAccessToken(userID uint64, username, role, tokenID string) (string, error) {
+func GenerateAccessToken(userID uint64, username, role, tokenID, sessionID string, pending bool) (string, error) {
 	now := time.Now()
 	claims := AccessTokenClaims{
 		UserID:   userID,
 		Username: username,
 		Role:     role,
 		TokenID:  tokenID,
+		SessionID: sessionID,
+		Pending:  pending,
 		RegisteredClaims: jwt. RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(now.Add(24 * time.Hour)), // 24小时
+			ExpiresAt: jwt.NewNumericDate(now.Add(AccessTokenTTL)),
 			IssuedAt:  jwt.NewNumericDate(now),
 			NotBefore: jwt.NewNumericDate(now),
 		},
 	}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	return token.SignedString(accessTokenSecret)
+	if accessKeys == nil || accessKeys.Current() == nil {
+		return "", errors.New("签名密钥未加载")
+	}
+	key := accessKeys.Current()
+	token := jwt.NewWithClaims(key.Method, claims)
+	token.Header["kid"] = key.ID
+	return token.SignedString(key.Private)
 }
 
 // GenerateRefreshToken 生成 Refresh Token
-func GenerateRefreshToken(userID uint64, tokenID string) (string, error) {
+func GenerateRefreshToken(userID uint64, tokenID, sessionID string) (string, error) {
 	now := time.Now()
 	claims := RefreshTokenClaims{
 		UserID:  userID,
 		TokenID: tokenID,
+		SessionID: sessionID,
 		RegisteredClaims: jwt.RegisteredClaims{
 			ExpiresAt: jwt.NewNumericDate(now.Add(7 * 24 * time. Hour)), // 7天
 			IssuedAt:  jwt.NewNumericDate(now),
@@ -105,11 +111,20 @@ func GenerateRefreshToken(userID uint64, tokenID string) (string, error) {
 // ValidateAccessToken 验证 Access Token
 func ValidateAccessToken(tokenString string) (*AccessTokenClaims, error) {
 	token, err := jwt.ParseWithClaims(tokenString, &AccessTokenClaims{}, func(token *jwt. Token) (interface{}, error) {
-		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+		if accessKeys == nil {
+			return nil, errors.New("签名密钥未加载")
+		}
+		kid, _ := token.Header["kid"].(string)
+		key, ok := accessKeys.lookup(kid)
+		if !ok {
+			return nil, errors.New("未知的签名密钥")
+		}
+		// 签名方法必须与密钥匹配，防止算法混淆
+		if token.Method.Alg() != key.Method.Alg() {
 			return nil, errors.New("无效的签名方法")
 		}
-		return accessTokenSecret, nil
-	})
+		return key.Private.Public(), nil
+	}, jwt.WithValidMethods([]string{jwt.SigningMethodRS256.Alg(), jwt.SigningMethodEdDSA.Alg()}))
 
 	if err != nil {
 		return nil, err
@@ -129,7 +144,7 @@ func ValidateRefreshToken(tokenString string) (*RefreshTokenClaims, error) {
 			return nil, errors.New("无效的签名方法")
 		}
 		return refreshTokenSecret, nil
-	})
+	}, jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}))
 
 	if err != nil {
 		return nil, err
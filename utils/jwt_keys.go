@@ -0,0 +1,383 @@
+package utils
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"log"
+	"math/big"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+)
+
+// Access Token 签名密钥保存在密钥目录中，每个文件 {kid}.pem 一个私钥：
+// RSA（PKCS#1 或 PKCS#8，至少 2048 位）使用 RS256，Ed25519（PKCS#8）使用 EdDSA。
+// kid 以生效时间开头（如 20261101T000000Z-ops），运维放入的密钥同样按此命名；
+// 密钥加载后立即发布到 JWKS 并可用于验证，到生效时间后才用于签名，生效时间最晚的已生效密钥为当前签名密钥。
+// 旧密钥被取代后仍保留一个 Access Token 有效期用于验证，之后退役
+const (
+	keyFileExt    = ".pem"
+	kidTimeLayout = "20060102T150405Z"
+	minRSAKeyBits = 2048
+
+	// 自动生成的密钥带有该 PEM 头，退役后只删除这类文件，运维放入的密钥文件不会被删除
+	generatedKeyHeader = "Generated-By"
+	generatedKeyValue  = "library-system"
+)
+
+const (
+	// KeyReloadInterval 各实例重新加载密钥目录的间隔
+	KeyReloadInterval = time.Minute
+	// JWKSCacheMaxAge JWKS 响应允许缓存的时长
+	JWKSCacheMaxAge = 5 * time.Minute
+	// 新生成的密钥先发布、后签名：等待时间须超过 JWKS 缓存时长与重新加载间隔之和，
+	// 保证其他实例和外部验证方在收到新密钥签发的 Token 前已拿到公钥
+	keyActivationDelay = 2 * (JWKSCacheMaxAge + KeyReloadInterval)
+)
+
+// SigningKey 签名密钥
+type SigningKey struct {
+	ID          string // kid，即文件名
+	Method      jwt.SigningMethod
+	Private     crypto.Signer
+	ActivatesAt time.Time // 开始用于签名的时间，取自 kid
+	Generated   bool      // 由本服务自动生成
+}
+
+// KeySet 从密钥目录加载的一组签名密钥，多实例共享同一目录即可互相验证 Token
+type KeySet struct {
+	dir       string
+	algorithm string // 自动生成密钥使用的算法
+
+	mu       sync.RWMutex
+	keys     map[string]*SigningKey // 已发布的密钥（可用于验证）
+	current  *SigningKey
+	pending  bool     // 存在尚未生效的密钥
+	retired  []string // 已退役的自动生成密钥文件
+	loadedAt time.Time
+}
+
+// JWK 公钥（RFC 7517）
+type JWK struct {
+	Kty string `json:"kty"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	Kid string `json:"kid"`
+	Crv string `json:"crv,omitempty"`
+	X   string `json:"x,omitempty"`
+	N   string `json:"n,omitempty"`
+	E   string `json:"e,omitempty"`
+}
+
+// JWKS 公钥集合
+type JWKS struct {
+	Keys []JWK `json:"keys"`
+}
+
+var accessKeys *KeySet
+
+// SetAccessKeySet 设置签发和验证 Access Token 使用的密钥
+func SetAccessKeySet(ks *KeySet) {
+	accessKeys = ks
+}
+
+func NewKeySet(dir, algorithm string) *KeySet {
+	return &KeySet{
+		dir:       dir,
+		algorithm: algorithm,
+		keys:      map[string]*SigningKey{},
+	}
+}
+
+// Load 加载密钥目录，目录中没有密钥时生成一个立即生效的密钥
+func (ks *KeySet) Load() error {
+	if ks.algorithm != jwt.SigningMethodEdDSA.Alg() && ks.algorithm != jwt.SigningMethodRS256.Alg() {
+		return fmt.Errorf("不支持的签名算法 %s，可选 EdDSA 或 RS256", ks.algorithm)
+	}
+	if err := ks.reload(); err != nil {
+		return err
+	}
+	if ks.Current() != nil {
+		return nil
+	}
+
+	log.Printf("密钥目录 %s 中没有签名密钥，已自动生成", ks.dir)
+	if _, err := ks.generate(time.Now()); err != nil {
+		return err
+	}
+	return ks.reload()
+}
+
+// Rotate 重新加载密钥目录并删除已退役的自动生成密钥；当前签名密钥已使用超过 interval 且没有待生效的密钥时，
+// 生成下一个密钥，发布 keyActivationDelay 后才开始签名。interval 为 0 时只重新加载；返回新密钥的 kid
+func (ks *KeySet) Rotate(interval time.Duration) (string, error) {
+	if err := ks.reload(); err != nil {
+		return "", err
+	}
+
+	ks.mu.RLock()
+	retired := ks.retired
+	current := ks.current
+	pending := ks.pending
+	ks.mu.RUnlock()
+	for _, path := range retired {
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			log.Printf("删除已退役的密钥 %s 失败: %v", path, err)
+		}
+	}
+
+	if interval <= 0 || pending {
+		return "", nil
+	}
+	if current != nil && time.Since(current.ActivatesAt) < interval {
+		return "", nil
+	}
+	kid, err := ks.generate(time.Now().Add(keyActivationDelay))
+	if err != nil {
+		return "", err
+	}
+	return kid, ks.reload()
+}
+
+// Current 当前的签名密钥
+func (ks *KeySet) Current() *SigningKey {
+	ks.mu.RLock()
+	defer ks.mu.RUnlock()
+	return ks.current
+}
+
+// JWKS 导出已发布的全部公钥（含尚未生效的密钥），本地副本过期时先重新加载密钥目录
+func (ks *KeySet) JWKS() JWKS {
+	if ks.stale() {
+		if err := ks.reload(); err != nil {
+			log.Printf("重新加载签名密钥失败: %v", err)
+		}
+	}
+
+	ks.mu.RLock()
+	defer ks.mu.RUnlock()
+
+	set := JWKS{Keys: make([]JWK, 0, len(ks.keys))}
+	for _, key := range ks.keys {
+		jwk := JWK{Use: "sig", Alg: key.Method.Alg(), Kid: key.ID}
+		switch pub := key.Private.Public().(type) {
+		case ed25519.PublicKey:
+			jwk.Kty = "OKP"
+			jwk.Crv = "Ed25519"
+			jwk.X = base64.RawURLEncoding.EncodeToString(pub)
+		case *rsa.PublicKey:
+			jwk.Kty = "RSA"
+			jwk.N = base64.RawURLEncoding.EncodeToString(pub.N.Bytes())
+			jwk.E = base64.RawURLEncoding.EncodeToString(big.NewInt(int64(pub.E)).Bytes())
+		}
+		set.Keys = append(set.Keys, jwk)
+	}
+	sort.Slice(set.Keys, func(i, j int) bool {
+		return set.Keys[i].Kid < set.Keys[j].Kid
+	})
+	return set
+}
+
+// lookup 按 kid 查找验证密钥；找不到且本地副本已过期时重新加载一次
+func (ks *KeySet) lookup(kid string) (*SigningKey, bool) {
+	ks.mu.RLock()
+	key, ok := ks.keys[kid]
+	ks.mu.RUnlock()
+	if ok || !ks.stale() {
+		return key, ok
+	}
+
+	if err := ks.reload(); err != nil {
+		log.Printf("重新加载签名密钥失败: %v", err)
+		return nil, false
+	}
+	ks.mu.RLock()
+	defer ks.mu.RUnlock()
+	key, ok = ks.keys[kid]
+	return key, ok
+}
+
+func (ks *KeySet) stale() bool {
+	ks.mu.RLock()
+	defer ks.mu.RUnlock()
+	return time.Since(ks.loadedAt) > KeyReloadInterval
+}
+
+// reload 重新读取密钥目录，任一文件无效时保留原有密钥
+func (ks *KeySet) reload() error {
+	entries, err := os.ReadDir(ks.dir)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+
+	var all []*SigningKey
+	paths := map[string]string{}
+	for _, entry := range entries {
+		if entry.IsDir() || filepath.Ext(entry.Name()) != keyFileExt {
+			continue
+		}
+		path := filepath.Join(ks.dir, entry.Name())
+		key, err := readKeyFile(path)
+		if err != nil {
+			return fmt.Errorf("加载密钥 %s 失败: %v", path, err)
+		}
+		all = append(all, key)
+		paths[key.ID] = path
+	}
+	// 按生效时间排序，相同时按 kid，保证各实例选出同一个签名密钥
+	sort.Slice(all, func(i, j int) bool {
+		if !all[i].ActivatesAt.Equal(all[j].ActivatesAt) {
+			return all[i].ActivatesAt.Before(all[j].ActivatesAt)
+		}
+		return all[i].ID < all[j].ID
+	})
+
+	now := time.Now()
+	active := -1
+	for i, key := range all {
+		if !key.ActivatesAt.After(now) {
+			active = i
+		}
+	}
+
+	keys := map[string]*SigningKey{}
+	var retired []string
+	for i, key := range all {
+		// 被已生效的新密钥取代超过一个 Access Token 有效期的密钥不再用于验证
+		if i < active && now.Sub(all[i+1].ActivatesAt) > AccessTokenTTL {
+			if key.Generated {
+				retired = append(retired, paths[key.ID])
+			}
+			continue
+		}
+		keys[key.ID] = key
+	}
+
+	ks.mu.Lock()
+	defer ks.mu.Unlock()
+	ks.keys = keys
+	ks.retired = retired
+	ks.pending = active < len(all)-1
+	ks.current = nil
+	if active >= 0 {
+		ks.current = all[active]
+	} else if len(all) > 0 {
+		// 只有尚未生效的密钥时（运维提前放入），使用最早的一个，避免无法签发
+		ks.current = all[0]
+	}
+	ks.loadedAt = now
+	return nil
+}
+
+// generate 生成在 activatesAt 生效的新密钥并写入密钥目录，返回 kid
+func (ks *KeySet) generate(activatesAt time.Time) (string, error) {
+	var private crypto.Signer
+	var err error
+	if ks.algorithm == jwt.SigningMethodRS256.Alg() {
+		private, err = rsa.GenerateKey(rand.Reader, minRSAKeyBits)
+	} else {
+		_, private, err = ed25519.GenerateKey(rand.Reader)
+	}
+	if err != nil {
+		return "", err
+	}
+
+	der, err := x509.MarshalPKCS8PrivateKey(private)
+	if err != nil {
+		return "", err
+	}
+	if err := os.MkdirAll(ks.dir, 0o700); err != nil {
+		return "", err
+	}
+
+	// 多个实例可能同时轮换，kid 附带随机后缀避免冲突；先写临时文件再改名，避免其他实例读到不完整的文件
+	kid := activatesAt.UTC().Format(kidTimeLayout) + "-" + uuid.NewString()[:8]
+	tmp, err := os.CreateTemp(ks.dir, ".tmp-*")
+	if err != nil {
+		return "", err
+	}
+	defer os.Remove(tmp.Name())
+	block := &pem.Block{
+		Type:    "PRIVATE KEY",
+		Headers: map[string]string{generatedKeyHeader: generatedKeyValue},
+		Bytes:   der,
+	}
+	if err := pem.Encode(tmp, block); err != nil {
+		tmp.Close()
+		return "", err
+	}
+	if err := tmp.Close(); err != nil {
+		return "", err
+	}
+	if err := os.Rename(tmp.Name(), filepath.Join(ks.dir, kid+keyFileExt)); err != nil {
+		return "", err
+	}
+	return kid, nil
+}
+
+// readKeyFile 读取 PEM 格式的私钥文件
+func readKeyFile(path string) (*SigningKey, error) {
+	kid := strings.TrimSuffix(filepath.Base(path), keyFileExt)
+	if len(kid) < len(kidTimeLayout) {
+		return nil, fmt.Errorf("kid 须以生效时间开头，格式如 %s-name", kidTimeLayout)
+	}
+	activatesAt, err := time.Parse(kidTimeLayout, kid[:len(kidTimeLayout)])
+	if err != nil {
+		return nil, fmt.Errorf("kid 须以生效时间开头，格式如 %s-name", kidTimeLayout)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("不是 PEM 格式")
+	}
+
+	var parsed interface{}
+	switch block.Type {
+	case "RSA PRIVATE KEY":
+		parsed, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	case "PRIVATE KEY":
+		parsed, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	default:
+		return nil, fmt.Errorf("不支持的 PEM 类型 %s", block.Type)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	key := &SigningKey{
+		ID:          kid,
+		ActivatesAt: activatesAt,
+		Generated:   block.Headers[generatedKeyHeader] == generatedKeyValue,
+	}
+	switch private := parsed.(type) {
+	case *rsa.PrivateKey:
+		if private.N.BitLen() < minRSAKeyBits {
+			return nil, fmt.Errorf("RSA 密钥至少 %d 位", minRSAKeyBits)
+		}
+		key.Method = jwt.SigningMethodRS256
+		key.Private = private
+	case ed25519.PrivateKey:
+		key.Method = jwt.SigningMethodEdDSA
+		key.Private = private
+	default:
+		return nil, errors.New("只支持 RSA 与 Ed25519 私钥")
+	}
+	return key, nil
+}
@@ -0,0 +1,246 @@
+package utils
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// writeOperatorKey 模拟运维放入的密钥文件（不带自动生成的 PEM 头）
+func writeOperatorKey(t *testing.T, dir, kid string, block *pem.Block) {
+	t.Helper()
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, kid+keyFileExt), pem.EncodeToMemory(block), 0o600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func ed25519Block(t *testing.T) *pem.Block {
+	t.Helper()
+	_, private, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	der, err := x509.MarshalPKCS8PrivateKey(private)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &pem.Block{Type: "PRIVATE KEY", Bytes: der}
+}
+
+func rsaBlock(t *testing.T, bits int) *pem.Block {
+	t.Helper()
+	private, err := rsa.GenerateKey(rand.Reader, bits)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(private)}
+}
+
+func kidAt(at time.Time, name string) string {
+	return at.UTC().Format(kidTimeLayout) + "-" + name
+}
+
+func jwksKids(set JWKS) []string {
+	kids := make([]string, 0, len(set.Keys))
+	for _, key := range set.Keys {
+		kids = append(kids, key.Kid)
+	}
+	return kids
+}
+
+func TestKeySetLoadGeneratesKey(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "keys")
+	ks := NewKeySet(dir, jwt.SigningMethodEdDSA.Alg())
+	if err := ks.Load(); err != nil {
+		t.Fatal(err)
+	}
+
+	current := ks.Current()
+	if current == nil || !current.Generated || current.Method != jwt.SigningMethodEdDSA {
+		t.Fatalf("current = %+v, want a generated EdDSA key", current)
+	}
+	set := ks.JWKS()
+	if len(set.Keys) != 1 || set.Keys[0].Kid != current.ID || set.Keys[0].Kty != "OKP" || set.Keys[0].X == "" {
+		t.Fatalf("JWKS = %+v", set)
+	}
+
+	// 再次加载不会重复生成
+	if err := NewKeySet(dir, jwt.SigningMethodEdDSA.Alg()).Load(); err != nil {
+		t.Fatal(err)
+	}
+	entries, _ := os.ReadDir(dir)
+	if len(entries) != 1 {
+		t.Fatalf("key directory has %d files, want 1", len(entries))
+	}
+}
+
+func TestKeySetLoadRejectsUnsupportedAlgorithm(t *testing.T) {
+	if err := NewKeySet(t.TempDir(), "HS256").Load(); err == nil {
+		t.Fatal("Load with HS256 should fail")
+	}
+}
+
+func TestKeySetRotatePublishesBeforeSigning(t *testing.T) {
+	dir := t.TempDir()
+	ks := NewKeySet(dir, jwt.SigningMethodEdDSA.Alg())
+	old, err := ks.generate(time.Now().Add(-48 * time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := ks.Load(); err != nil {
+		t.Fatal(err)
+	}
+
+	// 当前密钥使用未满轮换间隔时不轮换
+	if kid, err := ks.Rotate(72 * time.Hour); err != nil || kid != "" {
+		t.Fatalf("Rotate = %q, %v, want no rotation", kid, err)
+	}
+
+	next, err := ks.Rotate(24 * time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if next == "" || next <= old {
+		t.Fatalf("Rotate = %q, want a kid after %s", next, old)
+	}
+	activatesAt, _ := time.Parse(kidTimeLayout, next[:len(kidTimeLayout)])
+	if wait := time.Until(activatesAt); wait < keyActivationDelay-2*time.Second || wait > keyActivationDelay {
+		t.Fatalf("new key activates in %v, want %v", wait, keyActivationDelay)
+	}
+
+	// 新密钥立即发布，但在生效前仍由旧密钥签名
+	if current := ks.Current(); current.ID != old {
+		t.Fatalf("current = %s, want %s until the new key activates", current.ID, old)
+	}
+	if got := jwksKids(ks.JWKS()); len(got) != 2 || got[0] != old || got[1] != next {
+		t.Fatalf("JWKS kids = %v, want [%s %s]", got, old, next)
+	}
+	if _, ok := ks.lookup(next); !ok {
+		t.Fatal("pending key should be available for verification")
+	}
+
+	// 已有待生效的密钥时不再生成
+	if kid, err := ks.Rotate(time.Nanosecond); err != nil || kid != "" {
+		t.Fatalf("Rotate with pending key = %q, %v, want no rotation", kid, err)
+	}
+}
+
+func TestKeySetSelectsAndRetiresKeys(t *testing.T) {
+	dir := t.TempDir()
+	now := time.Now()
+	ks := NewKeySet(dir, jwt.SigningMethodEdDSA.Alg())
+
+	// 被取代超过一个 Access Token 有效期的密钥退役：自动生成的删除文件，运维放入的保留文件
+	retiredGenerated, err := ks.generate(now.Add(-96 * time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+	retiredOperator := kidAt(now.Add(-72*time.Hour), "ops")
+	writeOperatorKey(t, dir, retiredOperator, rsaBlock(t, minRSAKeyBits))
+	// 刚被取代的密钥仍用于验证
+	previous := kidAt(now.Add(-48*time.Hour), "ops")
+	writeOperatorKey(t, dir, previous, ed25519Block(t))
+	current := kidAt(now.Add(-time.Hour), "ops")
+	writeOperatorKey(t, dir, current, rsaBlock(t, minRSAKeyBits))
+	pending := kidAt(now.Add(time.Hour), "ops")
+	writeOperatorKey(t, dir, pending, ed25519Block(t))
+
+	if _, err := ks.Rotate(0); err != nil {
+		t.Fatal(err)
+	}
+
+	got := ks.Current()
+	if got.ID != current || got.Method != jwt.SigningMethodRS256 || got.Generated {
+		t.Fatalf("current = %+v, want operator RS256 key %s", got, current)
+	}
+
+	set := ks.JWKS()
+	want := []string{previous, current, pending}
+	if kids := jwksKids(set); strings.Join(kids, ",") != strings.Join(want, ",") {
+		t.Fatalf("JWKS kids = %v, want %v", kids, want)
+	}
+	if rsaKey := set.Keys[1]; rsaKey.Kty != "RSA" || rsaKey.Alg != "RS256" || rsaKey.E != "AQAB" || rsaKey.N == "" {
+		t.Fatalf("RSA JWK = %+v", rsaKey)
+	}
+	for _, kid := range []string{retiredGenerated, retiredOperator} {
+		if _, ok := ks.lookup(kid); ok {
+			t.Errorf("retired key %s still used for verification", kid)
+		}
+	}
+
+	if _, err := os.Stat(filepath.Join(dir, retiredGenerated+keyFileExt)); !os.IsNotExist(err) {
+		t.Errorf("retired generated key file not removed: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, retiredOperator+keyFileExt)); err != nil {
+		t.Errorf("operator key file should be kept: %v", err)
+	}
+}
+
+func TestKeySetOnlyPendingKeys(t *testing.T) {
+	dir := t.TempDir()
+	now := time.Now()
+	early := kidAt(now.Add(time.Hour), "b")
+	late := kidAt(now.Add(2*time.Hour), "a")
+	writeOperatorKey(t, dir, late, ed25519Block(t))
+	writeOperatorKey(t, dir, early, ed25519Block(t))
+
+	ks := NewKeySet(dir, jwt.SigningMethodEdDSA.Alg())
+	if err := ks.Load(); err != nil {
+		t.Fatal(err)
+	}
+	// 只有尚未生效的密钥时使用最早生效的一个，不自动生成
+	if got := ks.Current(); got.ID != early {
+		t.Fatalf("current = %s, want %s", got.ID, early)
+	}
+	if entries, _ := os.ReadDir(dir); len(entries) != 2 {
+		t.Fatalf("key directory has %d files, want 2", len(entries))
+	}
+}
+
+func TestKeySetRejectsInvalidKeyFiles(t *testing.T) {
+	tests := []struct {
+		name  string
+		kid   string
+		block func(t *testing.T) *pem.Block
+	}{
+		{"kid without time", "ops-key", ed25519Block},
+		{"short rsa key", kidAt(time.Now(), "ops"), func(t *testing.T) *pem.Block { return rsaBlock(t, 1024) }},
+		{"unsupported pem type", kidAt(time.Now(), "ops"), func(t *testing.T) *pem.Block {
+			return &pem.Block{Type: "EC PRIVATE KEY", Bytes: []byte("x")}
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			valid, err := NewKeySet(dir, jwt.SigningMethodEdDSA.Alg()).generate(time.Now().Add(-time.Hour))
+			if err != nil {
+				t.Fatal(err)
+			}
+			ks := NewKeySet(dir, jwt.SigningMethodEdDSA.Alg())
+			if err := ks.Load(); err != nil {
+				t.Fatal(err)
+			}
+
+			// 任一文件无效时加载失败，并保留原有密钥
+			writeOperatorKey(t, dir, tt.kid, tt.block(t))
+			if _, err := ks.Rotate(0); err == nil {
+				t.Fatal("Rotate should fail on an invalid key file")
+			}
+			if got := ks.Current(); got == nil || got.ID != valid {
+				t.Fatalf("current = %+v, want %s kept", got, valid)
+			}
+		})
+	}
+}
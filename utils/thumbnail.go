@@ -0,0 +1,57 @@
+package utils
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+)
+
+// FlattenRGBA 将图片转成以 (0,0) 为原点的 RGBA 便于按像素取值，透明区域铺白底（JPEG 不支持透明）
+func FlattenRGBA(src image.Image) *image.RGBA {
+	bounds := src.Bounds()
+	rgba := image.NewRGBA(image.Rect(0, 0, bounds.Dx(), bounds.Dy()))
+	draw.Draw(rgba, rgba.Bounds(), image.NewUniform(color.White), image.Point{}, draw.Src)
+	draw.Draw(rgba, rgba.Bounds(), src, bounds.Min, draw.Over)
+	return rgba
+}
+
+// Thumbnail 按宽度等比缩小 FlattenRGBA 的结果，原图不宽于 width 时原样返回不放大。
+// 采用区域平均（盒式滤波），缩小时比最近邻更平滑
+func Thumbnail(rgba *image.RGBA, width int) *image.RGBA {
+	sw, sh := rgba.Rect.Dx(), rgba.Rect.Dy()
+	if sw <= width || sw == 0 || sh == 0 {
+		return rgba
+	}
+
+	dw := width
+	dh := max(sh*width/sw, 1)
+	dst := image.NewRGBA(image.Rect(0, 0, dw, dh))
+
+	for y := 0; y < dh; y++ {
+		y0 := y * sh / dh
+		y1 := max((y+1)*sh/dh, y0+1)
+		for x := 0; x < dw; x++ {
+			x0 := x * sw / dw
+			x1 := max((x+1)*sw/dw, x0+1)
+
+			var r, g, b, a, n uint32
+			for sy := y0; sy < y1; sy++ {
+				off := rgba.PixOffset(x0, sy)
+				for sx := x0; sx < x1; sx++ {
+					r += uint32(rgba.Pix[off])
+					g += uint32(rgba.Pix[off+1])
+					b += uint32(rgba.Pix[off+2])
+					a += uint32(rgba.Pix[off+3])
+					off += 4
+					n++
+				}
+			}
+			off := dst.PixOffset(x, y)
+			dst.Pix[off] = uint8(r / n)
+			dst.Pix[off+1] = uint8(g / n)
+			dst.Pix[off+2] = uint8(b / n)
+			dst.Pix[off+3] = uint8(a / n)
+		}
+	}
+	return dst
+}